@@ -33,7 +33,7 @@ chmod +x ${CODEGEN_PKG}/generate-internal-groups.sh
 
 ${CODEGEN_PKG}/generate-groups.sh all \
     github.com/openfaas/ingress-operator/pkg/client github.com/openfaas/ingress-operator/pkg/apis \
-    openfaas:v1 \
+    openfaas:v1,v2 \
     --output-base "${TEMP_DIR}" \
     --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.go.txt
 
@@ -13,3 +13,6 @@ fi
   schemapatch:manifests=./artifacts/crds \
   paths=./pkg/apis/... \
   output:dir=./artifacts/crds
+
+# openfaas.com/v2 is not served until the conversion webhook is enabled with
+# hack/crd-conversion-patch.yaml
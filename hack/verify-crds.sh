@@ -0,0 +1,36 @@
+#!/usr/bin/env bash
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(git rev-parse --show-toplevel)
+export GOPATH="${GOPATH:-$(go env GOPATH)}"
+
+DIFFROOT="${SCRIPT_ROOT}/artifacts/crds"
+TMP_DIFFROOT="${SCRIPT_ROOT}/_tmp/crds"
+_tmp="${SCRIPT_ROOT}/_tmp"
+
+cleanup() {
+  rm -rf "${_tmp}"
+}
+trap "cleanup" EXIT SIGINT
+
+cleanup
+
+mkdir -p "${TMP_DIFFROOT}"
+cp -a "${DIFFROOT}"/* "${TMP_DIFFROOT}"
+
+cd "${SCRIPT_ROOT}"
+./hack/update-crds.sh
+echo "diffing ${DIFFROOT} against freshly generated CRDs"
+ret=0
+diff -Naupr "${TMP_DIFFROOT}" "${DIFFROOT}" || ret=$?
+cp -a "${TMP_DIFFROOT}"/* "${DIFFROOT}"
+if [[ $ret -eq 0 ]]
+then
+  echo "${DIFFROOT} up to date."
+else
+  echo "${DIFFROOT} is out of date. Please run hack/update-crds.sh"
+  exit 1
+fi
@@ -1,21 +1,40 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
 	clientset "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned"
 	informers "github.com/openfaas/ingress-operator/pkg/client/informers/externalversions"
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
 	controllerv1 "github.com/openfaas/ingress-operator/pkg/controller/v1"
+	"github.com/openfaas/ingress-operator/pkg/metrics"
 	"github.com/openfaas/ingress-operator/pkg/signals"
 	"github.com/openfaas/ingress-operator/pkg/version"
+	"github.com/openfaas/ingress-operator/pkg/webhook"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
 	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	klog "k8s.io/klog"
 
 	// required for generating code from CRD
@@ -26,8 +45,42 @@ import (
 )
 
 var (
-	masterURL  string
-	kubeconfig string
+	masterURL   string
+	kubeconfig  string
+	metricsAddr string
+
+	probeAddr         string
+	livenessStallTime time.Duration
+
+	leaderElect          bool
+	leaseDuration        time.Duration
+	renewDeadline        time.Duration
+	retryPeriod          time.Duration
+	leaderElectNamespace string
+	leaderElectLeaseName string
+
+	gatewayAPIParent string
+
+	gatewayName      string
+	gatewayNamespace string
+	gatewayPort      int
+	clusterDomain    string
+
+	functionNamespaces string
+	bypassHysteresis   time.Duration
+
+	certificateExpiryThreshold time.Duration
+
+	webhookAddr          string
+	webhookNamespace     string
+	webhookService       string
+	webhookSecret        string
+	webhookConfiguration string
+
+	defaultIngressClass      string
+	defaultFunctionNamespace string
+	defaultIssuer            string
+	defaultIssuerKind        string
 )
 
 const defaultResync = time.Hour * 10
@@ -38,6 +91,40 @@ func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the Prometheus metrics endpoint binds to. Set to \"0\" to disable it.")
+
+	flag.StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the /healthz and /readyz probes bind to. Set to \"0\" to disable them.")
+	flag.DurationVar(&livenessStallTime, "liveness-stall-timeout", 5*time.Minute, "Fail the liveness probe when items are queued but no worker has made progress for this long.")
+
+	flag.BoolVar(&leaderElect, "leader-elect", false, "Start a leader election client and only reconcile while holding the lease. Enable this when running more than one replica.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", 15*time.Second, "The duration that non-leader candidates will wait before attempting to acquire leadership.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", 10*time.Second, "The duration that the leader will retry refreshing leadership before giving up.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", 2*time.Second, "The duration clients should wait between attempts to acquire or renew leadership.")
+	flag.StringVar(&leaderElectNamespace, "leader-elect-resource-namespace", "", "The namespace of the Lease used for leader election. Defaults to the ingress_namespace.")
+	flag.StringVar(&leaderElectLeaseName, "leader-elect-resource-name", "ingress-operator", "The name of the Lease used for leader election.")
+
+	flag.StringVar(&gatewayAPIParent, "gateway-api-parent", "", "The Gateway, as \"namespace/name\" or \"name\", that HTTPRoutes attach to when a FunctionIngress has no parentRef.")
+
+	flag.StringVar(&gatewayName, "gateway-name", controller.DefaultGatewayName, "The name of the OpenFaaS gateway Service, when a FunctionIngress does not set gateway.name.")
+	flag.StringVar(&gatewayNamespace, "gateway-namespace", "", "The namespace of the OpenFaaS gateway Service, when a FunctionIngress does not set gateway.namespace. Defaults to the namespace of each FunctionIngress.")
+	flag.IntVar(&gatewayPort, "gateway-port", controller.DefaultGatewayPort, "The port of the OpenFaaS gateway Service, when a FunctionIngress does not set gateway.port.")
+	flag.StringVar(&clusterDomain, "cluster-domain", "cluster.local", "The DNS domain of the cluster, used to reach a gateway in another namespace.")
+	flag.StringVar(&functionNamespaces, "function-namespaces", controller.DefaultFunctionNamespace, "Comma-separated namespaces whose function Deployments and Services are watched to report the FunctionAvailable condition. Set to \"\" to disable it.")
+	flag.DurationVar(&bypassHysteresis, "bypass-hysteresis", controller.DefaultBypassHysteresis, "How long a FunctionIngress with bypassMode \"auto\" keeps sending requests through the gateway after a function was scaled to zero, before bypassing it again.")
+
+	flag.DurationVar(&certificateExpiryThreshold, "certificate-expiry-threshold", controller.DefaultCertificateExpiryThreshold, "Report a TLS certificate as CertificateExpiringSoon when it expires within this duration.")
+
+	flag.StringVar(&webhookAddr, "webhook-addr", "0", "The address the admission and conversion webhooks bind to, such as \":9443\". Set to \"0\" to disable it.")
+	flag.StringVar(&webhookNamespace, "webhook-namespace", "openfaas", "The namespace of the webhook Service and the secret with its serving certificate.")
+	flag.StringVar(&webhookService, "webhook-service", "ingress-operator-webhook", "The name of the Service that the API server calls the webhook through.")
+	flag.StringVar(&webhookSecret, "webhook-secret", "ingress-operator-webhook-cert", "The name of the secret which holds the generated serving certificate of the webhook.")
+	flag.StringVar(&webhookConfiguration, "webhook-configuration", "ingress-operator", "The name of the ValidatingWebhookConfiguration and MutatingWebhookConfiguration whose caBundle is kept up to date.")
+
+	flag.StringVar(&defaultIngressClass, "default-ingress-class", "", "The IngressClass of a FunctionIngress which sets neither ingressClassName nor ingressType. Defaults to the cluster's default IngressClass, then nginx.")
+	flag.StringVar(&defaultFunctionNamespace, "default-function-namespace", "", "The functionNamespace of a FunctionIngress which does not set one and does not bypass the gateway.")
+	flag.StringVar(&defaultIssuer, "default-issuer", "", "The cert-manager issuer of a FunctionIngress with TLS which does not set issuerRef.name.")
+	flag.StringVar(&defaultIssuerKind, "default-issuer-kind", "Issuer", "The kind of the -default-issuer, Issuer or ClusterIssuer.")
+
 }
 
 func main() {
@@ -68,32 +155,372 @@ func main() {
 		klog.Fatalf("Error building FunctionIngress clientset: %s", err.Error())
 	}
 
-	ingressNamespace := "openfaas"
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Error building dynamic clientset: %s", err.Error())
+	}
+
+	config := controller.Config{
+		DefaultParentRef: parseParentRef(gatewayAPIParent),
+		Gateway: faasv1.GatewayReference{
+			Name:      gatewayName,
+			Namespace: gatewayNamespace,
+			Port:      int32(gatewayPort),
+		},
+		ClusterDomain: clusterDomain,
+		Defaults: controller.Defaults{
+			IngressClassName:  defaultIngressClass,
+			FunctionNamespace: defaultFunctionNamespace,
+		},
+		CertificateExpiryThreshold: certificateExpiryThreshold,
+		BypassHysteresis:           bypassHysteresis,
+	}
+	if len(defaultIssuer) > 0 {
+		config.Defaults.IssuerRef = faasv1.ObjectReference{Name: defaultIssuer, Kind: defaultIssuerKind}
+	}
+
+	caps, err := getPreferredAvailableAPIs(kubeClient, "HTTPRoute")
+	if err != nil {
+		klog.Fatalf("Error discovering the Gateway API: %s", err.Error())
+	}
+	config.HTTPRouteEnabled = caps.Has(controller.HTTPRouteResource.GroupVersion().String())
+	if config.HTTPRouteEnabled {
+		klog.Infof("Found %s, enabling the %s ingressType", controller.HTTPRouteResource.GroupVersion(), controller.GatewayAPIClass)
+	}
+
+	caps, err = getPreferredAvailableAPIs(kubeClient, "IngressRoute")
+	if err != nil {
+		klog.Fatalf("Error discovering the Traefik CRDs: %s", err.Error())
+	}
+	config.TraefikCRDEnabled = caps.Has(controller.IngressRouteResource.GroupVersion().String())
+	if config.TraefikCRDEnabled {
+		klog.Infof("Found %s, enabling the %s ingressType", controller.IngressRouteResource.GroupVersion(), controller.TraefikCRDClass)
+	}
+
+	caps, err = getPreferredAvailableAPIs(kubeClient, "Certificate")
+	if err != nil {
+		klog.Fatalf("Error discovering the cert-manager CRDs: %s", err.Error())
+	}
+	config.CertificateEnabled = caps.Has(controller.CertificateResource.GroupVersion().String())
+	if config.CertificateEnabled {
+		klog.Infof("Found %s, enabling tls.certificate", controller.CertificateResource.GroupVersion())
+	}
+
+	ingressNamespaces := []string{"openfaas"}
 	if namespace, exists := os.LookupEnv("ingress_namespace"); exists {
-		ingressNamespace = namespace
+		ingressNamespaces = parseNamespaces(namespace)
+	}
+
+	clusterWide, _ := strconv.ParseBool(os.Getenv("cluster_wide"))
+	watchNamespaces := ingressNamespaces
+	if clusterWide {
+		watchNamespaces = []string{metav1.NamespaceAll}
+	}
+	config.Namespaces = watchNamespaces
+
+	// The function namespaces are watched once, and shared by the controller
+	// of each namespace
+	functionInformerFactories := map[string]kubeinformers.SharedInformerFactory{}
+	if len(strings.TrimSpace(functionNamespaces)) > 0 {
+		namespaces := parseNamespaces(functionNamespaces)
+		if clusterWide {
+			namespaces = []string{metav1.NamespaceAll}
+		}
+		for _, namespace := range namespaces {
+			// An informer which is forbidden from listing would never sync,
+			// so a namespace without the RBAC is skipped rather than
+			// blocking every controller
+			if err := canListFunctions(kubeClient, namespace); err != nil {
+				if !apierrors.IsForbidden(err) {
+					klog.Fatalf("Error listing functions in namespace %q: %s", namespace, err.Error())
+				}
+				klog.Warningf("Not watching functions in namespace %q, FunctionAvailable is Unknown for its functions: %s", namespace, err.Error())
+				continue
+			}
+
+			klog.Infof("Watching functions in namespace: %q", namespace)
+			functionInformerFactories[namespace] = kubeinformers.
+				NewSharedInformerFactoryWithOptions(kubeClient, defaultResync, kubeinformers.WithNamespace(namespace))
+		}
+	}
+
+	// A controller is created for each namespace, so that the informers are
+	// scoped to the namespaces the operator has been granted access to.
+	ctrls := []controller.BaseController{}
+	fniListers := []listers.FunctionIngressLister{}
+	fniSynced := []cache.InformerSynced{}
+	var ingressClassLister networkinglisters.IngressClassLister
+	for _, namespace := range watchNamespaces {
+		if namespace == metav1.NamespaceAll {
+			klog.Info("Watching FunctionIngresses in all namespaces")
+		} else {
+			klog.Infof("Watching FunctionIngresses in namespace: %s", namespace)
+		}
+
+		kubeInformerOpt := kubeinformers.WithNamespace(namespace)
+		kubeInformerFactory := kubeinformers.
+			NewSharedInformerFactoryWithOptions(kubeClient, defaultResync, kubeInformerOpt)
+
+		faasInformerOpt := informers.WithNamespace(namespace)
+		faasInformerFactory := informers.
+			NewSharedInformerFactoryWithOptions(faasClient, defaultResync, faasInformerOpt)
+
+		dynamicInformerFactory := dynamicinformer.
+			NewFilteredDynamicSharedInformerFactory(dynamicClient, defaultResync, namespace, nil)
+
+		ctrl := controllerv1.NewController(
+			kubeClient,
+			faasClient,
+			kubeInformerFactory,
+			faasInformerFactory,
+			dynamicClient,
+			dynamicInformerFactory,
+			functionInformerFactories,
+			namespace,
+			config,
+		)
+		ctrls = append(ctrls, ctrl)
+		fniListers = append(fniListers, faasInformerFactory.Openfaas().V1().FunctionIngresses().Lister())
+		fniSynced = append(fniSynced, faasInformerFactory.Openfaas().V1().FunctionIngresses().Informer().HasSynced)
+
+		// IngressClasses are cluster-scoped, so the lister of any namespace
+		// sees all of them
+		if ingressClassLister == nil {
+			ingressClassLister = kubeInformerFactory.Networking().V1().IngressClasses().Lister()
+		}
+
+		// Informers are started on every replica, so that a replica which is
+		// elected as the leader starts with a warm cache.
+		go kubeInformerFactory.Start(stopCh)
+		go faasInformerFactory.Start(stopCh)
+		go dynamicInformerFactory.Start(stopCh)
 	}
 
-	kubeInformerOpt := kubeinformers.WithNamespace(ingressNamespace)
-	kubeInformerFactory := kubeinformers.
-		NewSharedInformerFactoryWithOptions(kubeClient, defaultResync, kubeInformerOpt)
+	for _, factory := range functionInformerFactories {
+		go factory.Start(stopCh)
+	}
+
+	if metricsAddr != "0" {
+		metrics.Registry.MustRegister(controller.NewFunctionIngressCollector(ingressClassLister, fniListers...))
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		go serveHTTP("metrics", metricsAddr, mux, stopCh)
+	}
 
-	faasInformerOpt := informers.WithNamespace(ingressNamespace)
-	faasInformerFactory := informers.
-		NewSharedInformerFactoryWithOptions(faasClient, defaultResync, faasInformerOpt)
+	// The webhooks are served by every replica, since the API server calls
+	// any of the endpoints of its Service. Only the leader is ready, so the
+	// Service publishes the addresses of replicas which are not.
+	if webhookAddr != "0" {
+		go serveWebhook(kubeClient, dynamicClient, config.Defaults, fniListers, fniSynced, stopCh)
+	}
 
-	ctrl := controllerv1.NewController(
-		kubeClient,
-		faasClient,
-		kubeInformerFactory,
-		faasInformerFactory,
-	)
+	if probeAddr != "0" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/healthz", probeHandler(func() error {
+			for _, ctrl := range ctrls {
+				if err := ctrl.Healthz(livenessStallTime); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+		mux.HandleFunc("/readyz", probeHandler(func() error {
+			for _, ctrl := range ctrls {
+				if err := ctrl.Readyz(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+		go serveHTTP("health probes", probeAddr, mux, stopCh)
+	}
 
-	go kubeInformerFactory.Start(stopCh)
-	go faasInformerFactory.Start(stopCh)
+	if !leaderElect {
+		runControllers(ctrls, stopCh)
+		return
+	}
 
-	if err = ctrl.Run(1, stopCh); err != nil {
-		klog.Fatalf("Error running controller: %s", err.Error())
+	lockNamespace := ingressNamespaces[0]
+	if lockNamespace == metav1.NamespaceAll {
+		lockNamespace = "openfaas"
 	}
+	if len(leaderElectNamespace) > 0 {
+		lockNamespace = leaderElectNamespace
+	}
+
+	runWithLeaderElection(kubeClient, lockNamespace, stopCh, func(leaderStopCh <-chan struct{}) {
+		runControllers(ctrls, leaderStopCh)
+	})
+}
+
+// parseNamespaces splits a comma-separated list of namespaces, ignoring
+// whitespace, empty entries and duplicates.
+func parseNamespaces(value string) []string {
+	namespaces := []string{}
+	seen := map[string]bool{}
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if len(namespace) == 0 || seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+
+	// An empty value has always meant every namespace
+	if len(namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return namespaces
+}
+
+// parseParentRef parses a Gateway given as "namespace/name" or "name", an
+// empty value returns nil.
+func parseParentRef(value string) *faasv1.ParentReference {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return nil
+	}
+
+	if namespace, name, ok := strings.Cut(value, "/"); ok {
+		return &faasv1.ParentReference{Name: name, Namespace: namespace}
+	}
+	return &faasv1.ParentReference{Name: value}
+}
+
+// runControllers runs each controller until stopCh is closed
+func runControllers(ctrls []controller.BaseController, stopCh <-chan struct{}) {
+	var wg sync.WaitGroup
+	for _, ctrl := range ctrls {
+		wg.Add(1)
+		go func(ctrl controller.BaseController) {
+			defer wg.Done()
+			if err := ctrl.Run(1, stopCh); err != nil {
+				klog.Fatalf("Error running controller: %s", err.Error())
+			}
+		}(ctrl)
+	}
+	wg.Wait()
+}
+
+// serveHTTP serves handler on addr until stopCh is closed
+func serveHTTP(name, addr string, handler http.Handler, stopCh <-chan struct{}) {
+	s := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-stopCh
+		s.Close()
+	}()
+
+	klog.Infof("Serving %s on %s", name, addr)
+	if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		klog.Fatalf("Error serving %s: %s", name, err.Error())
+	}
+}
+
+// serveWebhook serves the webhooks once the FunctionIngresses and
+// IngressClasses are in the cache, so that conflicts are not missed.
+func serveWebhook(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, defaults controller.Defaults, fniListers []listers.FunctionIngressLister, fniSynced []cache.InformerSynced, stopCh <-chan struct{}) {
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, defaultResync)
+	ingressClasses := kubeInformerFactory.Networking().V1().IngressClasses()
+	synced := append(fniSynced, ingressClasses.Informer().HasSynced)
+	kubeInformerFactory.Start(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, synced...) {
+		return
+	}
+
+	certificates := webhook.NewCertificates(kubeClient, dynamicClient, webhook.CertificateConfig{
+		Namespace:                webhookNamespace,
+		Service:                  webhookService,
+		SecretName:               webhookSecret,
+		ClusterDomain:            clusterDomain,
+		CustomResourceDefinition: "functioningresses.openfaas.com",
+	}, webhookConfiguration)
+	validator := webhook.NewValidator(ingressClasses.Lister(), fniListers...)
+	defaulter := webhook.NewDefaulter(defaults, ingressClasses.Lister())
+	converter := webhook.NewConverter()
+
+	if err := webhook.Serve(webhookAddr, certificates, validator, defaulter, converter, stopCh); err != nil {
+		klog.Fatalf("Error serving the webhooks: %s", err.Error())
+	}
+}
+
+// probeHandler responds with 200 when check passes, otherwise 503 and the
+// reason, so that it can be read from the Pod's events.
+func probeHandler(check func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := check(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}
+}
+
+// runWithLeaderElection blocks until stopCh is closed or leadership is lost,
+// run is only called whilst this replica holds the Lease. Losing the Lease
+// requests a shutdown, so that the process exits and can stand for election
+// again with a fresh state.
+func runWithLeaderElection(kubeClient kubernetes.Interface, namespace string, stopCh <-chan struct{}, run func(stopCh <-chan struct{})) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("Error getting hostname for leader election: %s", err.Error())
+	}
+	id := hostname + "_" + string(uuid.NewUUID())
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaderElectLeaseName,
+			Namespace: namespace,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: id,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	klog.Infof("Waiting to acquire Lease %s/%s as %s", namespace, leaderElectLeaseName, id)
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            leaderElectLeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("Started leading as %s", id)
+				run(ctx.Done())
+			},
+			OnStoppedLeading: func() {
+				select {
+				case <-stopCh:
+					klog.Infof("Released Lease %s/%s", namespace, leaderElectLeaseName)
+				default:
+					klog.Errorf("Lost Lease %s/%s, shutting down", namespace, leaderElectLeaseName)
+					signals.RequestShutdown()
+				}
+			},
+			OnNewLeader: func(identity string) {
+				if identity != id {
+					klog.Infof("Current leader is %s", identity)
+				}
+			},
+		},
+	})
 }
 
 func setupLogging() {
@@ -124,6 +551,23 @@ func (c Capabilities) String() string {
 	return strings.Join(keys, ", ")
 }
 
+// canListFunctions returns an error when the operator can not list the
+// Deployments, Services or EndpointSlices in the namespace, such as when
+// the RBAC for a function namespace has not been applied.
+func canListFunctions(client kubernetes.Interface, namespace string) error {
+	ctx := context.Background()
+	opts := metav1.ListOptions{Limit: 1}
+
+	if _, err := client.AppsV1().Deployments(namespace).List(ctx, opts); err != nil {
+		return err
+	}
+	if _, err := client.CoreV1().Services(namespace).List(ctx, opts); err != nil {
+		return err
+	}
+	_, err := client.DiscoveryV1().EndpointSlices(namespace).List(ctx, opts)
+	return err
+}
+
 // getPreferredAvailableAPIs queries the cluster for the preferred resources information and returns a Capabilities
 // instance containing those api groups that support the specified kind.
 //
@@ -131,7 +575,9 @@ func (c Capabilities) String() string {
 func getPreferredAvailableAPIs(client kubernetes.Interface, kind string) (Capabilities, error) {
 	discoveryclient := client.Discovery()
 	lists, err := discoveryclient.ServerPreferredResources()
-	if err != nil {
+	// An unavailable aggregated API, such as metrics-server, should not hide
+	// the groups which were discovered.
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
 		return nil, err
 	}
 
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_parseNamespaces(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "single namespace", value: "openfaas", want: []string{"openfaas"}},
+		{name: "list of namespaces", value: "openfaas,team-a,team-b", want: []string{"openfaas", "team-a", "team-b"}},
+		{name: "whitespace is trimmed", value: " openfaas , team-a ", want: []string{"openfaas", "team-a"}},
+		{name: "empty entries and duplicates are ignored", value: "openfaas,,team-a,openfaas", want: []string{"openfaas", "team-a"}},
+		{name: "empty value watches all namespaces", value: "", want: []string{""}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseNamespaces(tc.value)
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_parseParentRef(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  *faasv1.ParentReference
+	}{
+		{name: "empty value", value: "", want: nil},
+		{name: "name only", value: "public", want: &faasv1.ParentReference{Name: "public"}},
+		{name: "namespace and name", value: "gateway-system/public", want: &faasv1.ParentReference{Name: "public", Namespace: "gateway-system"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseParentRef(tc.value)
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_canListFunctions(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	client.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "team-a" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, "", nil)
+	})
+
+	if err := canListFunctions(client, "openfaas-fn"); err != nil {
+		t.Errorf("want openfaas-fn to be listed, got: %s", err)
+	}
+	if err := canListFunctions(client, "team-a"); !apierrors.IsForbidden(err) {
+		t.Errorf("want a forbidden error for team-a, got: %v", err)
+	}
+}
@@ -5,9 +5,14 @@ import (
 )
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.spec.domain`
+// +kubebuilder:printcolumn:name="Function",type=string,JSONPath=`.spec.function`
+// +kubebuilder:printcolumn:name="TLS",type=string,JSONPath=`.status.tlsMode`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // FunctionIngress describes an OpenFaaS function
 type FunctionIngress struct {
@@ -22,29 +27,92 @@ type FunctionIngress struct {
 
 // FunctionIngressStatus is the status for a FunctionIngress resource
 type FunctionIngressStatus struct {
+	// Conditions such as Ready, IngressCreated, CertificateReady and Degraded
+	// +optional
+	// +listType=map
+	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+
+	// TLSMode is the TLS mode in use, including "none", so that it is shown
+	// when the spec only sets tls.enabled or has no tls
+	// +optional
+	TLSMode string `json:"tlsMode,omitempty" yaml:"tlsMode,omitempty"`
 }
 
-// FunctionIngressSpec is the spec for a FunctionIngress resource. It must
-// be created in the same namespace as the gateway, i.e. openfaas.
+// The gateway does not rewrite the path of a request which bypasses it, so
+// neither can a route.
+// +kubebuilder:validation:XValidation:rule="!((has(self.bypassGateway) && self.bypassGateway) || has(self.bypassMode)) || !has(self.routes) || self.routes.all(r, !has(r.rewrite))",message="routes can not set a rewrite when bypassing the gateway"
+// +kubebuilder:validation:XValidation:rule="!has(self.bypassMode) || !has(self.bypassGateway) || !self.bypassGateway",message="bypassMode can not be set with bypassGateway"
+// +kubebuilder:validation:XValidation:rule="(has(self.domain) && size(self.domain) > 0) || (has(self.domains) && size(self.domains) > 0)",message="domain or domains is required"
+// +kubebuilder:validation:XValidation:rule="(has(self.function) && size(self.function) > 0) || (has(self.routes) && size(self.routes) > 0 && self.routes.all(r, has(r.function) && size(r.function) > 0))",message="function is required unless every route sets one"
+
+// FunctionIngressSpec is the spec for a FunctionIngress resource. It is
+// usually created in the same namespace as the gateway, i.e. openfaas.
 type FunctionIngressSpec struct {
-	// Domain such as "api.example.com"
+	// Domain such as "api.example.com", or a wildcard such as
+	// "*.example.com"
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^((\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*)?$`
 	Domain string `json:"domain"`
 
-	// Function such as "nodeinfo"
+	// Domains are served in addition to Domain, such as "www.api.example.com"
+	// or a wildcard such as "*.eu.api.example.com"
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Domains []Domain `json:"domains,omitempty"`
+
+	// Function such as "nodeinfo", it is required unless every route sets
+	// a function
+	// +optional
 	Function string `json:"function"`
 
 	// Namespace for function such as "openfaas-fn"
+	// +kubebuilder:validation:MaxLength=63
 	FunctionNamespace string `json:"functionNamespace,omitempty"`
 
 	// Path such as "/v1/profiles/view/(.*)", or leave empty for default
 	// +optional
 	Path string `json:"path"`
 
-	// IngressType such as "nginx"
+	// PathMatch is how the paths are matched: "Prefix", "Exact" or "Regex".
+	// When it is not set a path is a regular expression, other than for the
+	// ingress controllers which only match a prefix, for which a trailing
+	// "(.*)" is dropped
+	// +optional
+	// +kubebuilder:validation:Enum=Prefix;Exact;Regex
+	PathMatch string `json:"pathMatch,omitempty"`
+
+	// Routes map several paths on the domains to functions, Path is not used
+	// when Routes are set
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Routes []FunctionRoute `json:"routes,omitempty"`
+
+	// IngressType such as "nginx", "gateway-api" to create a Gateway API
+	// HTTPRoute, or "traefik-crd" to create a Traefik IngressRoute and
+	// Middleware instead of an Ingress
 	// +optional
 	IngressType string `json:"ingressType,omitempty"`
 
+	// IngressClassName is the IngressClass of the Ingress, such as
+	// "nginx-internal". It defaults to IngressType, or the cluster's default
+	// IngressClass when both are empty. The rewrite annotations are chosen by
+	// the controller of the IngressClass.
+	// +optional
+	IngressClassName string `json:"ingressClassName,omitempty"`
+
+	// ParentRef is the Gateway that the HTTPRoute attaches to when
+	// IngressType is "gateway-api", defaults to the operator's -gateway-api-parent
+	// +optional
+	ParentRef *ParentReference `json:"parentRef,omitempty"`
+
+	// Gateway is the Service that requests are sent to, fields which are
+	// not set default to the operator's -gateway-name, -gateway-namespace
+	// and -gateway-port
+	// +optional
+	Gateway *GatewayReference `json:"gateway,omitempty"`
+
 	// Enable TLS via cert-manager
 	// +optional
 	TLS *FunctionIngressTLS `json:"tls,omitempty"`
@@ -54,30 +122,227 @@ type FunctionIngressSpec struct {
 	// in the hot path
 	// +optional
 	BypassGateway bool `json:"bypassGateway,omitempty"`
+
+	// BypassMode "auto" bypasses the gateway while the functions have ready
+	// replicas, and uses the gateway while they are scaled to zero, so that
+	// they can be woken up. It can not be set with BypassGateway.
+	// +optional
+	// +kubebuilder:validation:Enum=auto
+	BypassMode string `json:"bypassMode,omitempty"`
 }
 
+// Domain of Domains such as "www.api.example.com", or a wildcard such as
+// "*.eu.api.example.com"
+// +kubebuilder:validation:MaxLength=253
+// +kubebuilder:validation:Pattern=`^(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+type Domain string
+
+// Values of BypassMode
+const (
+	// BypassModeAuto sends requests straight to the function while it has
+	// ready replicas, and through the gateway while it is scaled to zero
+	BypassModeAuto = "auto"
+)
+
+// FunctionRoute maps a path on the domains to a function
+type FunctionRoute struct {
+	// Path such as "/v1/users/(.*)"
+	Path string `json:"path"`
+
+	// Function such as "users", defaults to the Function of the spec
+	// +optional
+	Function string `json:"function,omitempty"`
+
+	// FunctionNamespace such as "openfaas-fn", defaults to the
+	// FunctionNamespace of the spec
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	FunctionNamespace string `json:"functionNamespace,omitempty"`
+
+	// Rewrite is the path on the gateway that requests are sent to, such as
+	// "/function/users/v1", defaults to the path of the function
+	// +optional
+	Rewrite string `json:"rewrite,omitempty"`
+}
+
+// Values of PathMatch
+const (
+	// PathMatchPrefix matches the path and every path below it, such as
+	// "/v1/users" for "/v1/users/1"
+	PathMatchPrefix = "Prefix"
+	// PathMatchExact only matches the path itself
+	PathMatchExact = "Exact"
+	// PathMatchRegex matches a regular expression from the start of the path,
+	// its first capture group is kept when the path is rewritten, such as
+	// "/v1/users/(.*)"
+	PathMatchRegex = "Regex"
+)
+
+// TLS modes for a FunctionIngress
+const (
+	// TLSModeCertManager has cert-manager issue the certificate into the
+	// secret, using IssuerRef
+	TLSModeCertManager = "certManager"
+	// TLSModeExistingSecret uses a certificate which is managed outside of
+	// cert-manager, the secret is only read
+	TLSModeExistingSecret = "existingSecret"
+	// TLSModeNone serves plain HTTP
+	TLSModeNone = "none"
+)
+
 // FunctionIngressTLS TLS options
+// +kubebuilder:validation:XValidation:rule="(has(self.mode) ? self.mode == 'certManager' : (has(self.enabled) && self.enabled)) ? (has(self.issuerRef) && size(self.issuerRef.name) > 0) : true",message="issuerRef.name is required when the certificate is issued by cert-manager"
 type FunctionIngressTLS struct {
+	// Enabled is the same as a Mode of "certManager" when Mode is not set
 	// +optional
 	Enabled bool `json:"enabled"`
 
+	// Mode such as "certManager", "existingSecret" or "none"
+	// +optional
+	// +kubebuilder:validation:Enum=certManager;existingSecret;none
+	Mode string `json:"mode,omitempty"`
+
+	// SecretName of the TLS secret, defaults to the first domain followed by
+	// "-cert", such as "api.example.com-cert"
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
 	// +optional
 	IssuerRef ObjectReference `json:"issuerRef"`
+
+	// Certificate, when set with the certManager mode, has the operator
+	// create a cert-manager Certificate for the secret, rather than
+	// annotating the Ingress for cert-manager's ingress-shim
+	// +optional
+	Certificate *CertificateSpec `json:"certificate,omitempty"`
+}
+
+// CertificateSpec holds the options of the cert-manager Certificate
+type CertificateSpec struct {
+	// Duration of the certificate such as "2160h", defaults to the issuer
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before it expires that the certificate is
+	// renewed, such as "360h"
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// PrivateKey options for the certificate
+	// +optional
+	PrivateKey *CertificatePrivateKey `json:"privateKey,omitempty"`
+
+	// Usages such as "digital signature", "key encipherment" and
+	// "server auth"
+	// +optional
+	Usages []string `json:"usages,omitempty"`
+}
+
+// CertificatePrivateKey holds the private key options of the Certificate
+type CertificatePrivateKey struct {
+	// RotationPolicy is "Never" to keep the private key when the certificate
+	// is renewed, or "Always" to generate a new one
+	// +optional
+	// +kubebuilder:validation:Enum=Never;Always
+	RotationPolicy string `json:"rotationPolicy,omitempty"`
+}
+
+// TLSMode returns the Mode of the TLS options, or "certManager" when only
+// Enabled is set, and "none" when TLS is not configured.
+func (f *FunctionIngressSpec) TLSMode() string {
+	switch {
+	case f.TLS == nil:
+		return TLSModeNone
+	case len(f.TLS.Mode) > 0:
+		return f.TLS.Mode
+	case f.TLS.Enabled:
+		return TLSModeCertManager
+	default:
+		return TLSModeNone
+	}
 }
 
 // UseTLS if TLS is enabled
 func (f *FunctionIngressSpec) UseTLS() bool {
-	return f.TLS != nil && f.TLS.Enabled
+	return f.TLSMode() != TLSModeNone
+}
+
+// BypassesGateway is true when requests are sent straight to the function.
+// The controller decides whether bypassMode "auto" bypasses the gateway
+// before the FunctionIngress is rendered, until then it is false.
+func (f *FunctionIngressSpec) BypassesGateway() bool {
+	return f.BypassGateway
+}
+
+// MayBypassGateway is true when the gateway is bypassed, always or with
+// bypassMode "auto" while the functions are ready.
+func (f *FunctionIngressSpec) MayBypassGateway() bool {
+	return f.BypassGateway || f.BypassMode == BypassModeAuto
+}
+
+// ManagedCertificate is true when the operator creates the cert-manager
+// Certificate, rather than cert-manager's ingress-shim.
+func (f *FunctionIngressSpec) ManagedCertificate() bool {
+	return f.TLSMode() == TLSModeCertManager && f.TLS.Certificate != nil
+}
+
+// Hosts returns Domain followed by Domains, without empty entries or
+// duplicates. The first host names the TLS secret.
+func (f *FunctionIngressSpec) Hosts() []string {
+	hosts := []string{}
+	seen := map[string]bool{}
+	for _, domain := range append([]Domain{Domain(f.Domain)}, f.Domains...) {
+		host := string(domain)
+		if len(host) == 0 || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
 }
 
 // ObjectReference is a reference to an object with a given name and kind.
 type ObjectReference struct {
+	// +kubebuilder:validation:MaxLength=253
 	Name string `json:"name"`
 
+	// Kind of the cert-manager issuer, defaults to "Issuer"
 	// +optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
 	Kind string `json:"kind,omitempty"`
 }
 
+// ParentReference is a reference to a Gateway API Gateway
+type ParentReference struct {
+	// Name of the Gateway such as "openfaas"
+	Name string `json:"name"`
+
+	// Namespace of the Gateway, defaults to the namespace of the FunctionIngress
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName such as the name of a listener on the Gateway
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// GatewayReference is a reference to the Service of the OpenFaaS gateway
+type GatewayReference struct {
+	// Name of the Service such as "gateway"
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Service such as "openfaas", when it differs from the
+	// namespace of the FunctionIngress an ExternalName Service is created
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Port of the Service such as 8080
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // FunctionIngressList is a list of Function resources
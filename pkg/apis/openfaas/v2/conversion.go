@@ -0,0 +1,242 @@
+package v2
+
+import (
+	"encoding/json"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConversionDataAnnotation holds the spec of the version that an object was
+// converted from, when the spec can not be represented by the version it was
+// converted to. Converting back restores it, so that a round trip through
+// either version is lossless.
+const ConversionDataAnnotation = "openfaas.com/conversion-data"
+
+// IngressTypes of a v1 FunctionIngress which create an object other than an
+// Ingress
+const (
+	ingressTypeGatewayAPI = "gateway-api"
+	ingressTypeTraefikCRD = "traefik-crd"
+)
+
+// ConvertFromV1 returns the v2 FunctionIngress for a v1 FunctionIngress
+func ConvertFromV1(in *faasv1.FunctionIngress) *FunctionIngress {
+	out := &FunctionIngress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "FunctionIngress"},
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Status:     FunctionIngressStatus{Conditions: copyConditions(in.Status.Conditions), TLSMode: in.Status.TLSMode},
+	}
+	removeConversionData(&out.ObjectMeta)
+
+	saved := FunctionIngressSpec{}
+	if getConversionData(in.ObjectMeta, &saved) && equality.Semantic.DeepEqual(specToV1(saved), in.Spec) {
+		out.Spec = saved
+		return out
+	}
+
+	out.Spec = specFromV1(in.Spec)
+	if !equality.Semantic.DeepEqual(specToV1(out.Spec), in.Spec) {
+		setConversionData(&out.ObjectMeta, in.Spec)
+	}
+	return out
+}
+
+// ConvertToV1 returns the v1 FunctionIngress for a v2 FunctionIngress
+func ConvertToV1(in *FunctionIngress) *faasv1.FunctionIngress {
+	out := &faasv1.FunctionIngress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: faasv1.SchemeGroupVersion.String(), Kind: "FunctionIngress"},
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Status:     faasv1.FunctionIngressStatus{Conditions: copyConditions(in.Status.Conditions), TLSMode: in.Status.TLSMode},
+	}
+	removeConversionData(&out.ObjectMeta)
+
+	saved := faasv1.FunctionIngressSpec{}
+	if getConversionData(in.ObjectMeta, &saved) && equality.Semantic.DeepEqual(specFromV1(saved), in.Spec) {
+		out.Spec = saved
+		return out
+	}
+
+	out.Spec = specToV1(in.Spec)
+	if !equality.Semantic.DeepEqual(specFromV1(out.Spec), in.Spec) {
+		setConversionData(&out.ObjectMeta, in.Spec)
+	}
+	return out
+}
+
+func specFromV1(in faasv1.FunctionIngressSpec) FunctionIngressSpec {
+	out := FunctionIngressSpec{}
+
+	if len(in.Domain) > 0 {
+		out.Domains = append(out.Domains, Domain(in.Domain))
+	}
+	for _, domain := range in.Domains {
+		out.Domains = append(out.Domains, Domain(domain))
+	}
+
+	out.Class = ClassReference{Kind: ClassKindIngress, Name: in.IngressClassName}
+	switch in.IngressType {
+	case ingressTypeGatewayAPI:
+		out.Class.Kind = ClassKindHTTPRoute
+	case ingressTypeTraefikCRD:
+		out.Class.Kind = ClassKindIngressRoute
+	default:
+		out.Class.Dialect = in.IngressType
+	}
+	if in.ParentRef != nil {
+		parentRef := ParentReference(*in.ParentRef)
+		out.Class.ParentRef = &parentRef
+	}
+
+	out.Backend = Backend{
+		Type:              BackendTypeGateway,
+		Function:          in.Function,
+		FunctionNamespace: in.FunctionNamespace,
+	}
+	switch {
+	case in.BypassGateway:
+		out.Backend.Type = BackendTypeDirect
+	case in.BypassMode == faasv1.BypassModeAuto:
+		out.Backend.Type = BackendTypeAuto
+	}
+	if in.Gateway != nil {
+		gateway := GatewayReference(*in.Gateway)
+		out.Backend.Gateway = &gateway
+	}
+
+	// Path is the only route when Routes are not set
+	if len(in.Routes) > 0 {
+		for _, route := range in.Routes {
+			out.Routes = append(out.Routes, Route(route))
+		}
+	} else if len(in.Path) > 0 {
+		out.Routes = []Route{{Path: in.Path}}
+	}
+	out.PathMatch = in.PathMatch
+
+	if in.TLS != nil {
+		out.TLS = &TLS{
+			Mode:       in.TLSMode(),
+			SecretName: in.TLS.SecretName,
+		}
+		if in.TLS.IssuerRef != (faasv1.ObjectReference{}) {
+			out.TLS.IssuerRef = &IssuerReference{Name: in.TLS.IssuerRef.Name, Kind: in.TLS.IssuerRef.Kind}
+		}
+		if in.TLS.Certificate != nil {
+			out.TLS.Certificate = &CertificateSpec{
+				Duration:    in.TLS.Certificate.DeepCopy().Duration,
+				RenewBefore: in.TLS.Certificate.DeepCopy().RenewBefore,
+				Usages:      append([]string(nil), in.TLS.Certificate.Usages...),
+			}
+			if in.TLS.Certificate.PrivateKey != nil {
+				out.TLS.Certificate.PrivateKey = &CertificatePrivateKey{RotationPolicy: in.TLS.Certificate.PrivateKey.RotationPolicy}
+			}
+		}
+	}
+
+	return out
+}
+
+func specToV1(in FunctionIngressSpec) faasv1.FunctionIngressSpec {
+	out := faasv1.FunctionIngressSpec{}
+
+	if len(in.Domains) > 0 {
+		out.Domain = string(in.Domains[0])
+		for _, domain := range in.Domains[1:] {
+			out.Domains = append(out.Domains, faasv1.Domain(domain))
+		}
+	}
+
+	out.IngressClassName = in.Class.Name
+	switch in.Class.Kind {
+	case ClassKindHTTPRoute:
+		out.IngressType = ingressTypeGatewayAPI
+	case ClassKindIngressRoute:
+		out.IngressType = ingressTypeTraefikCRD
+	default:
+		out.IngressType = in.Class.Dialect
+	}
+	if in.Class.ParentRef != nil {
+		parentRef := faasv1.ParentReference(*in.Class.ParentRef)
+		out.ParentRef = &parentRef
+	}
+
+	out.Function = in.Backend.Function
+	out.FunctionNamespace = in.Backend.FunctionNamespace
+	switch in.Backend.Type {
+	case BackendTypeDirect:
+		out.BypassGateway = true
+	case BackendTypeAuto:
+		out.BypassMode = faasv1.BypassModeAuto
+	}
+	if in.Backend.Gateway != nil {
+		gateway := faasv1.GatewayReference(*in.Backend.Gateway)
+		out.Gateway = &gateway
+	}
+
+	// A single route with only a path is written as the Path of v1
+	if len(in.Routes) == 1 && in.Routes[0] == (Route{Path: in.Routes[0].Path}) {
+		out.Path = in.Routes[0].Path
+	} else {
+		for _, route := range in.Routes {
+			out.Routes = append(out.Routes, faasv1.FunctionRoute(route))
+		}
+	}
+	out.PathMatch = in.PathMatch
+
+	if in.TLS != nil {
+		out.TLS = &faasv1.FunctionIngressTLS{
+			Enabled:    in.TLS.Mode == TLSModeCertManager,
+			Mode:       in.TLS.Mode,
+			SecretName: in.TLS.SecretName,
+		}
+		if in.TLS.IssuerRef != nil {
+			out.TLS.IssuerRef = faasv1.ObjectReference{Name: in.TLS.IssuerRef.Name, Kind: in.TLS.IssuerRef.Kind}
+		}
+		if in.TLS.Certificate != nil {
+			out.TLS.Certificate = &faasv1.CertificateSpec{
+				Duration:    in.TLS.Certificate.DeepCopy().Duration,
+				RenewBefore: in.TLS.Certificate.DeepCopy().RenewBefore,
+				Usages:      append([]string(nil), in.TLS.Certificate.Usages...),
+			}
+			if in.TLS.Certificate.PrivateKey != nil {
+				out.TLS.Certificate.PrivateKey = &faasv1.CertificatePrivateKey{RotationPolicy: in.TLS.Certificate.PrivateKey.RotationPolicy}
+			}
+		}
+	}
+
+	return out
+}
+
+func copyConditions(conditions []metav1.Condition) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+	return append([]metav1.Condition{}, conditions...)
+}
+
+// getConversionData decodes the spec saved by a previous conversion into
+// spec, it is false when there is none
+func getConversionData(meta metav1.ObjectMeta, spec interface{}) bool {
+	data, ok := meta.Annotations[ConversionDataAnnotation]
+	if !ok {
+		return false
+	}
+	return json.Unmarshal([]byte(data), spec) == nil
+}
+
+func setConversionData(meta *metav1.ObjectMeta, spec interface{}) {
+	data, _ := json.Marshal(spec)
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[ConversionDataAnnotation] = string(data)
+}
+
+func removeConversionData(meta *metav1.ObjectMeta) {
+	delete(meta.Annotations, ConversionDataAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
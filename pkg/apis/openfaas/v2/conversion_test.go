@@ -0,0 +1,337 @@
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestV1FunctionIngress(spec faasv1.FunctionIngressSpec) *faasv1.FunctionIngress {
+	return &faasv1.FunctionIngress{
+		TypeMeta: metav1.TypeMeta{APIVersion: "openfaas.com/v1", Kind: "FunctionIngress"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nodeinfo",
+			Namespace:   "openfaas",
+			Annotations: map[string]string{"example.com/owner": "team-a"},
+		},
+		Spec: spec,
+		Status: faasv1.FunctionIngressStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Reconciled"}},
+			TLSMode:    faasv1.TLSModeNone,
+		},
+	}
+}
+
+func newTestV2FunctionIngress(spec FunctionIngressSpec) *FunctionIngress {
+	return &FunctionIngress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "openfaas.com/v2", Kind: "FunctionIngress"},
+		ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+		Spec:       spec,
+	}
+}
+
+func Test_ConvertFromV1(t *testing.T) {
+	in := newTestV1FunctionIngress(faasv1.FunctionIngressSpec{
+		Domain:            "nodeinfo.example.com",
+		Domains:           []faasv1.Domain{"www.nodeinfo.example.com"},
+		Function:          "nodeinfo",
+		FunctionNamespace: "openfaas-fn",
+		Path:              "/v1/(.*)",
+		IngressClassName:  "nginx-internal",
+		Gateway:           &faasv1.GatewayReference{Name: "gateway-internal", Port: 8080},
+		TLS: &faasv1.FunctionIngressTLS{
+			Enabled:   true,
+			Mode:      faasv1.TLSModeCertManager,
+			IssuerRef: faasv1.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+		},
+	})
+
+	got := ConvertFromV1(in)
+
+	want := FunctionIngressSpec{
+		Domains: []Domain{"nodeinfo.example.com", "www.nodeinfo.example.com"},
+		Class:   ClassReference{Kind: ClassKindIngress, Name: "nginx-internal"},
+		Backend: Backend{
+			Type:              BackendTypeGateway,
+			Function:          "nodeinfo",
+			FunctionNamespace: "openfaas-fn",
+			Gateway:           &GatewayReference{Name: "gateway-internal", Port: 8080},
+		},
+		Routes: []Route{{Path: "/v1/(.*)"}},
+		TLS: &TLS{
+			Mode:      TLSModeCertManager,
+			IssuerRef: &IssuerReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+		},
+	}
+	if diff := cmp.Diff(want, got.Spec); diff != "" {
+		t.Errorf("unexpected spec (-want +got):\n%s", diff)
+	}
+	if got.APIVersion != "openfaas.com/v2" {
+		t.Errorf("want apiVersion openfaas.com/v2, got %s", got.APIVersion)
+	}
+	if _, ok := got.Annotations[ConversionDataAnnotation]; ok {
+		t.Errorf("want no %s annotation when v2 represents the spec", ConversionDataAnnotation)
+	}
+	if diff := cmp.Diff(in.Status.Conditions, got.Status.Conditions); diff != "" {
+		t.Errorf("unexpected conditions (-want +got):\n%s", diff)
+	}
+	if got.Status.TLSMode != in.Status.TLSMode {
+		t.Errorf("want tlsMode %q, got %q", in.Status.TLSMode, got.Status.TLSMode)
+	}
+}
+
+func Test_ConvertFromV1_Class(t *testing.T) {
+	cases := []struct {
+		name        string
+		ingressType string
+		want        ClassReference
+	}{
+		{name: "default", ingressType: "", want: ClassReference{Kind: ClassKindIngress}},
+		{name: "dialect", ingressType: "traefik", want: ClassReference{Kind: ClassKindIngress, Dialect: "traefik"}},
+		{name: "gateway api", ingressType: "gateway-api", want: ClassReference{Kind: ClassKindHTTPRoute}},
+		{name: "traefik crd", ingressType: "traefik-crd", want: ClassReference{Kind: ClassKindIngressRoute}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ConvertFromV1(newTestV1FunctionIngress(faasv1.FunctionIngressSpec{Function: "nodeinfo", IngressType: tc.ingressType}))
+			if diff := cmp.Diff(tc.want, got.Spec.Class); diff != "" {
+				t.Errorf("unexpected class (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ConvertFromV1_Bypass(t *testing.T) {
+	got := ConvertFromV1(newTestV1FunctionIngress(faasv1.FunctionIngressSpec{
+		Function:          "nodeinfo",
+		FunctionNamespace: "openfaas",
+		BypassGateway:     true,
+	}))
+
+	if got.Spec.Backend.Type != BackendTypeDirect {
+		t.Errorf("want backend type %s, got %s", BackendTypeDirect, got.Spec.Backend.Type)
+	}
+}
+
+func Test_RoundTripFromV1(t *testing.T) {
+	duration := &metav1.Duration{Duration: 90 * 24 * time.Hour}
+
+	cases := []struct {
+		name string
+		spec faasv1.FunctionIngressSpec
+	}{
+		{
+			name: "minimal",
+			spec: faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo"},
+		},
+		{
+			name: "routes",
+			spec: faasv1.FunctionIngressSpec{
+				Domain: "api.example.com",
+				Routes: []faasv1.FunctionRoute{
+					{Path: "/v1/users/(.*)", Function: "users"},
+					{Path: "/v1/orders/(.*)", Function: "orders", FunctionNamespace: "shop-fn", Rewrite: "/function/orders.shop-fn/v1"},
+				},
+			},
+		},
+		{
+			name: "gateway api",
+			spec: faasv1.FunctionIngressSpec{
+				Domain:      "nodeinfo.example.com",
+				Function:    "nodeinfo",
+				IngressType: "gateway-api",
+				ParentRef:   &faasv1.ParentReference{Name: "openfaas", Namespace: "gateways", SectionName: "https"},
+			},
+		},
+		{
+			name: "auto bypass",
+			spec: faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", BypassMode: faasv1.BypassModeAuto},
+		},
+		{
+			name: "prefix path match",
+			spec: faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "/v1/nodeinfo", PathMatch: faasv1.PathMatchPrefix},
+		},
+		{
+			name: "managed certificate",
+			spec: faasv1.FunctionIngressSpec{
+				Domain:   "nodeinfo.example.com",
+				Function: "nodeinfo",
+				TLS: &faasv1.FunctionIngressTLS{
+					Enabled:    true,
+					Mode:       faasv1.TLSModeCertManager,
+					SecretName: "nodeinfo-tls",
+					IssuerRef:  faasv1.ObjectReference{Name: "letsencrypt"},
+					Certificate: &faasv1.CertificateSpec{
+						Duration:   duration,
+						PrivateKey: &faasv1.CertificatePrivateKey{RotationPolicy: "Always"},
+						Usages:     []string{"server auth"},
+					},
+				},
+			},
+		},
+		// The cases below can not be represented by v2, so the v1 spec is
+		// kept in the annotation
+		{
+			name: "enabled without a mode",
+			spec: faasv1.FunctionIngressSpec{
+				Domain:   "nodeinfo.example.com",
+				Function: "nodeinfo",
+				TLS:      &faasv1.FunctionIngressTLS{Enabled: true, IssuerRef: faasv1.ObjectReference{Name: "letsencrypt"}},
+			},
+		},
+		{
+			name: "domains without a domain",
+			spec: faasv1.FunctionIngressSpec{Domains: []faasv1.Domain{"nodeinfo.example.com"}, Function: "nodeinfo"},
+		},
+		{
+			name: "path and routes",
+			spec: faasv1.FunctionIngressSpec{
+				Function: "nodeinfo",
+				Path:     "/ignored",
+				Routes:   []faasv1.FunctionRoute{{Path: "/v1"}},
+			},
+		},
+		{
+			name: "single route without a function",
+			spec: faasv1.FunctionIngressSpec{Function: "nodeinfo", Routes: []faasv1.FunctionRoute{{Path: "/v1"}}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := newTestV1FunctionIngress(tc.spec)
+
+			got := ConvertToV1(ConvertFromV1(in.DeepCopy()))
+
+			if diff := cmp.Diff(in, got); diff != "" {
+				t.Errorf("unexpected round trip (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_RoundTripFromV2(t *testing.T) {
+	cases := []struct {
+		name string
+		spec FunctionIngressSpec
+	}{
+		{
+			name: "minimal",
+			spec: FunctionIngressSpec{
+				Domains: []Domain{"nodeinfo.example.com"},
+				Class:   ClassReference{Kind: ClassKindIngress},
+				Backend: Backend{Type: BackendTypeGateway, Function: "nodeinfo"},
+			},
+		},
+		{
+			name: "direct",
+			spec: FunctionIngressSpec{
+				Domains: []Domain{"nodeinfo.example.com", "*.nodeinfo.example.com"},
+				Class:   ClassReference{Kind: ClassKindIngress, Name: "nginx", Dialect: "nginx"},
+				Backend: Backend{Type: BackendTypeDirect, Function: "nodeinfo", FunctionNamespace: "openfaas"},
+				Routes:  []Route{{Path: "/v1"}, {Path: "/v2", Function: "nodeinfo-v2"}},
+			},
+		},
+		{
+			name: "auto",
+			spec: FunctionIngressSpec{
+				Domains: []Domain{"nodeinfo.example.com"},
+				Class:   ClassReference{Kind: ClassKindHTTPRoute},
+				Backend: Backend{Type: BackendTypeAuto, Function: "nodeinfo", FunctionNamespace: "openfaas-fn"},
+			},
+		},
+		{
+			name: "exact path match",
+			spec: FunctionIngressSpec{
+				Domains:   []Domain{"nodeinfo.example.com"},
+				Backend:   Backend{Type: BackendTypeGateway, Function: "nodeinfo"},
+				Routes:    []Route{{Path: "/healthz", Function: "health"}, {Path: "/", Function: "nodeinfo"}},
+				PathMatch: PathMatchExact,
+			},
+		},
+		{
+			name: "existing secret",
+			spec: FunctionIngressSpec{
+				Domains: []Domain{"nodeinfo.example.com"},
+				Class:   ClassReference{Kind: ClassKindIngressRoute},
+				Backend: Backend{Type: BackendTypeGateway, Function: "nodeinfo"},
+				TLS:     &TLS{Mode: TLSModeExistingSecret, SecretName: "wildcard-tls"},
+			},
+		},
+		// The cases below can not be represented by v1, so the v2 spec is
+		// kept in the annotation
+		{
+			name: "dialect of an HTTPRoute",
+			spec: FunctionIngressSpec{
+				Class:   ClassReference{Kind: ClassKindHTTPRoute, Dialect: "nginx"},
+				Backend: Backend{Type: BackendTypeGateway, Function: "nodeinfo"},
+			},
+		},
+		{
+			name: "empty kind and type",
+			spec: FunctionIngressSpec{Backend: Backend{Function: "nodeinfo"}},
+		},
+		{
+			name: "empty issuer",
+			spec: FunctionIngressSpec{
+				Class:   ClassReference{Kind: ClassKindIngress},
+				Backend: Backend{Type: BackendTypeGateway, Function: "nodeinfo"},
+				TLS:     &TLS{Mode: TLSModeCertManager, IssuerRef: &IssuerReference{}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := newTestV2FunctionIngress(tc.spec)
+
+			got := ConvertFromV1(ConvertToV1(in.DeepCopy()))
+
+			if diff := cmp.Diff(in, got); diff != "" {
+				t.Errorf("unexpected round trip (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ConvertToV1_KeepsV2OnlyFields(t *testing.T) {
+	in := newTestV2FunctionIngress(FunctionIngressSpec{
+		Class:   ClassReference{Kind: ClassKindHTTPRoute, Dialect: "nginx"},
+		Backend: Backend{Type: BackendTypeGateway, Function: "nodeinfo"},
+	})
+
+	got := ConvertToV1(in)
+
+	if _, ok := got.Annotations[ConversionDataAnnotation]; !ok {
+		t.Errorf("want the %s annotation to keep the dialect", ConversionDataAnnotation)
+	}
+	if got.Spec.IngressType != "gateway-api" {
+		t.Errorf("want ingressType gateway-api, got %q", got.Spec.IngressType)
+	}
+}
+
+func Test_ConvertFromV1_IgnoresStaleConversionData(t *testing.T) {
+	v1 := ConvertToV1(newTestV2FunctionIngress(FunctionIngressSpec{
+		Class:   ClassReference{Kind: ClassKindHTTPRoute, Dialect: "nginx"},
+		Backend: Backend{Type: BackendTypeGateway, Function: "nodeinfo"},
+	}))
+
+	// The v1 object is edited without updating the annotation
+	v1.Spec.Function = "figlet"
+
+	got := ConvertFromV1(v1)
+
+	if got.Spec.Backend.Function != "figlet" {
+		t.Errorf("want the function of the edited v1 object, got %q", got.Spec.Backend.Function)
+	}
+	if got.Spec.Class.Dialect != "" {
+		t.Errorf("want the saved v2 spec to be ignored, got dialect %q", got.Spec.Class.Dialect)
+	}
+	if _, ok := got.Annotations[ConversionDataAnnotation]; ok {
+		t.Errorf("want no %s annotation", ConversionDataAnnotation)
+	}
+}
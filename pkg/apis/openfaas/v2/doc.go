@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package,register
+
+// Package v2 is the OpenFaaS v2 version of the API.
+// +groupName=openfaas.com
+package v2
@@ -0,0 +1,306 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.spec.domains[0]`
+// +kubebuilder:printcolumn:name="Function",type=string,JSONPath=`.spec.backend.function`
+// +kubebuilder:printcolumn:name="Backend",type=string,JSONPath=`.spec.backend.type`
+// +kubebuilder:printcolumn:name="TLS",type=string,JSONPath=`.status.tlsMode`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// FunctionIngress describes an OpenFaaS function
+type FunctionIngress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FunctionIngressSpec `json:"spec"`
+
+	// +optional
+	Status FunctionIngressStatus `json:"status"`
+}
+
+// FunctionIngressStatus is the status for a FunctionIngress resource
+type FunctionIngressStatus struct {
+	// Conditions such as Ready, IngressCreated, CertificateReady and Degraded
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+
+	// TLSMode is the TLS mode in use, including "none", so that it is shown
+	// when the spec only sets tls.enabled or has no tls
+	// +optional
+	TLSMode string `json:"tlsMode,omitempty" yaml:"tlsMode,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="!has(self.backend.type) || self.backend.type == 'Gateway' || !has(self.routes) || self.routes.all(r, !has(r.rewrite))",message="routes can only set a rewrite with the Gateway backend"
+// +kubebuilder:validation:XValidation:rule="!has(self.backend.type) || self.backend.type != 'Direct' || !has(self.backend.gateway)",message="backend.gateway can not be set with the Direct backend"
+// +kubebuilder:validation:XValidation:rule="has(self.domains) && size(self.domains) > 0",message="domains is required"
+// +kubebuilder:validation:XValidation:rule="(has(self.backend.function) && size(self.backend.function) > 0) || (has(self.routes) && size(self.routes) > 0 && self.routes.all(r, has(r.function) && size(r.function) > 0))",message="backend.function is required unless every route sets one"
+
+// FunctionIngressSpec is the spec for a FunctionIngress resource
+type FunctionIngressSpec struct {
+	// Domains such as "api.example.com", or a wildcard such as
+	// "*.eu.api.example.com". The first domain names the TLS secret.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Domains []Domain `json:"domains,omitempty"`
+
+	// Class is the kind of object which is created for the FunctionIngress
+	// +optional
+	Class ClassReference `json:"class,omitempty"`
+
+	// Backend is where requests are sent
+	Backend Backend `json:"backend"`
+
+	// Routes map paths on the domains to functions, every path is sent to
+	// the function of the backend when there are no routes
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Routes []Route `json:"routes,omitempty"`
+
+	// PathMatch is how the paths of the routes are matched: "Prefix",
+	// "Exact" or "Regex". When it is not set a path is a regular expression,
+	// other than for the classes which only match a prefix, for which a
+	// trailing "(.*)" is dropped
+	// +optional
+	// +kubebuilder:validation:Enum=Prefix;Exact;Regex
+	PathMatch string `json:"pathMatch,omitempty"`
+
+	// TLS options, plain HTTP is served when not set
+	// +optional
+	TLS *TLS `json:"tls,omitempty"`
+}
+
+// Domain of Domains such as "api.example.com", or a wildcard such as
+// "*.eu.api.example.com"
+// +kubebuilder:validation:MaxLength=253
+// +kubebuilder:validation:Pattern=`^(\*\.)?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+type Domain string
+
+// Kinds of object which a FunctionIngress is rendered as
+const (
+	// ClassKindIngress creates a networking.k8s.io Ingress
+	ClassKindIngress = "Ingress"
+	// ClassKindHTTPRoute creates a Gateway API HTTPRoute
+	ClassKindHTTPRoute = "HTTPRoute"
+	// ClassKindIngressRoute creates a Traefik IngressRoute and Middleware
+	ClassKindIngressRoute = "IngressRoute"
+)
+
+// ClassReference chooses the object which is created for the
+// FunctionIngress, and the controller which serves it
+type ClassReference struct {
+	// Kind such as "Ingress", "HTTPRoute" or "IngressRoute", defaults to
+	// "Ingress"
+	// +optional
+	// +kubebuilder:validation:Enum=Ingress;HTTPRoute;IngressRoute
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the IngressClass of an Ingress such as "nginx-internal",
+	// defaults to the cluster's default IngressClass
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Dialect of the rewrite annotations of an Ingress such as "nginx",
+	// "traefik" or "skipper", when it can not be told from the controller of
+	// the IngressClass
+	// +optional
+	Dialect string `json:"dialect,omitempty"`
+
+	// ParentRef is the Gateway that an HTTPRoute attaches to, defaults to
+	// the operator's -gateway-api-parent
+	// +optional
+	ParentRef *ParentReference `json:"parentRef,omitempty"`
+}
+
+// Types of Backend
+const (
+	// BackendTypeGateway sends requests through the OpenFaaS gateway
+	BackendTypeGateway = "Gateway"
+	// BackendTypeDirect sends requests to the Service of the function,
+	// bypassing the gateway
+	BackendTypeDirect = "Direct"
+	// BackendTypeAuto sends requests to the Service of the function while it
+	// has ready replicas, and through the gateway while it is scaled to zero
+	BackendTypeAuto = "Auto"
+)
+
+// Backend is where the requests of a FunctionIngress are sent
+type Backend struct {
+	// Type is "Gateway", "Direct" or "Auto", defaults to "Gateway"
+	// +optional
+	// +kubebuilder:validation:Enum=Gateway;Direct;Auto
+	Type string `json:"type,omitempty"`
+
+	// Function such as "nodeinfo", it is required unless every route sets
+	// a function
+	// +optional
+	Function string `json:"function,omitempty"`
+
+	// FunctionNamespace such as "openfaas-fn", for the Direct and Auto types
+	// a Service is created for a function in another namespace to the
+	// FunctionIngress
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	FunctionNamespace string `json:"functionNamespace,omitempty"`
+
+	// Gateway is the Service of the gateway for the Gateway and Auto types,
+	// fields which are not set default to the operator's -gateway-name,
+	// -gateway-namespace and -gateway-port
+	// +optional
+	Gateway *GatewayReference `json:"gateway,omitempty"`
+}
+
+// Route maps a path on the domains to a function
+type Route struct {
+	// Path such as "/v1/users/(.*)"
+	Path string `json:"path"`
+
+	// Function such as "users", defaults to the function of the backend
+	// +optional
+	Function string `json:"function,omitempty"`
+
+	// FunctionNamespace such as "openfaas-fn", defaults to the function
+	// namespace of the backend
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	FunctionNamespace string `json:"functionNamespace,omitempty"`
+
+	// Rewrite is the path on the gateway that requests are sent to, such as
+	// "/function/users/v1", defaults to the path of the function
+	// +optional
+	Rewrite string `json:"rewrite,omitempty"`
+}
+
+// Values of PathMatch
+const (
+	// PathMatchPrefix matches the path and every path below it
+	PathMatchPrefix = "Prefix"
+	// PathMatchExact only matches the path itself
+	PathMatchExact = "Exact"
+	// PathMatchRegex matches a regular expression from the start of the path
+	PathMatchRegex = "Regex"
+)
+
+// TLS modes for a FunctionIngress
+const (
+	// TLSModeCertManager has cert-manager issue the certificate into the
+	// secret, using IssuerRef
+	TLSModeCertManager = "certManager"
+	// TLSModeExistingSecret uses a certificate which is managed outside of
+	// cert-manager, the secret is only read
+	TLSModeExistingSecret = "existingSecret"
+	// TLSModeNone serves plain HTTP
+	TLSModeNone = "none"
+)
+
+// TLS options of a FunctionIngress
+// +kubebuilder:validation:XValidation:rule="self.mode != 'certManager' || (has(self.issuerRef) && size(self.issuerRef.name) > 0)",message="issuerRef.name is required when the certificate is issued by cert-manager"
+type TLS struct {
+	// Mode such as "certManager", "existingSecret" or "none"
+	// +kubebuilder:validation:Enum=certManager;existingSecret;none
+	Mode string `json:"mode"`
+
+	// SecretName of the TLS secret, defaults to the first domain followed by
+	// "-cert", such as "api.example.com-cert"
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// IssuerRef is the cert-manager issuer for the certManager mode
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// Certificate, when set with the certManager mode, has the operator
+	// create a cert-manager Certificate for the secret, rather than
+	// annotating the Ingress for cert-manager's ingress-shim
+	// +optional
+	Certificate *CertificateSpec `json:"certificate,omitempty"`
+}
+
+// IssuerReference is a reference to a cert-manager Issuer or ClusterIssuer
+type IssuerReference struct {
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+
+	// Kind of the cert-manager issuer, defaults to "Issuer"
+	// +optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+}
+
+// CertificateSpec holds the options of the cert-manager Certificate
+type CertificateSpec struct {
+	// Duration of the certificate such as "2160h", defaults to the issuer
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before it expires that the certificate is
+	// renewed, such as "360h"
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// PrivateKey options for the certificate
+	// +optional
+	PrivateKey *CertificatePrivateKey `json:"privateKey,omitempty"`
+
+	// Usages such as "digital signature", "key encipherment" and
+	// "server auth"
+	// +optional
+	Usages []string `json:"usages,omitempty"`
+}
+
+// CertificatePrivateKey holds the private key options of the Certificate
+type CertificatePrivateKey struct {
+	// RotationPolicy is "Never" to keep the private key when the certificate
+	// is renewed, or "Always" to generate a new one
+	// +optional
+	// +kubebuilder:validation:Enum=Never;Always
+	RotationPolicy string `json:"rotationPolicy,omitempty"`
+}
+
+// ParentReference is a reference to a Gateway API Gateway
+type ParentReference struct {
+	// Name of the Gateway such as "openfaas"
+	Name string `json:"name"`
+
+	// Namespace of the Gateway, defaults to the namespace of the FunctionIngress
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName such as the name of a listener on the Gateway
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// GatewayReference is a reference to the Service of the OpenFaaS gateway
+type GatewayReference struct {
+	// Name of the Service such as "gateway"
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Service such as "openfaas"
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Port of the Service such as 8080
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FunctionIngressList is a list of Function resources
+type FunctionIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []FunctionIngress `json:"items"`
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// CertificatePrivateKeyApplyConfiguration represents an declarative configuration of the CertificatePrivateKey type for use
+// with apply.
+type CertificatePrivateKeyApplyConfiguration struct {
+	RotationPolicy *string `json:"rotationPolicy,omitempty"`
+}
+
+// CertificatePrivateKeyApplyConfiguration constructs an declarative configuration of the CertificatePrivateKey type for use with
+// apply.
+func CertificatePrivateKey() *CertificatePrivateKeyApplyConfiguration {
+	return &CertificatePrivateKeyApplyConfiguration{}
+}
+
+// WithRotationPolicy sets the RotationPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RotationPolicy field is set to the value of the last call.
+func (b *CertificatePrivateKeyApplyConfiguration) WithRotationPolicy(value string) *CertificatePrivateKeyApplyConfiguration {
+	b.RotationPolicy = &value
+	return b
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CertificateSpecApplyConfiguration represents an declarative configuration of the CertificateSpec type for use
+// with apply.
+type CertificateSpecApplyConfiguration struct {
+	Duration    *v1.Duration                             `json:"duration,omitempty"`
+	RenewBefore *v1.Duration                             `json:"renewBefore,omitempty"`
+	PrivateKey  *CertificatePrivateKeyApplyConfiguration `json:"privateKey,omitempty"`
+	Usages      []string                                 `json:"usages,omitempty"`
+}
+
+// CertificateSpecApplyConfiguration constructs an declarative configuration of the CertificateSpec type for use with
+// apply.
+func CertificateSpec() *CertificateSpecApplyConfiguration {
+	return &CertificateSpecApplyConfiguration{}
+}
+
+// WithDuration sets the Duration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Duration field is set to the value of the last call.
+func (b *CertificateSpecApplyConfiguration) WithDuration(value v1.Duration) *CertificateSpecApplyConfiguration {
+	b.Duration = &value
+	return b
+}
+
+// WithRenewBefore sets the RenewBefore field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RenewBefore field is set to the value of the last call.
+func (b *CertificateSpecApplyConfiguration) WithRenewBefore(value v1.Duration) *CertificateSpecApplyConfiguration {
+	b.RenewBefore = &value
+	return b
+}
+
+// WithPrivateKey sets the PrivateKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PrivateKey field is set to the value of the last call.
+func (b *CertificateSpecApplyConfiguration) WithPrivateKey(value *CertificatePrivateKeyApplyConfiguration) *CertificateSpecApplyConfiguration {
+	b.PrivateKey = value
+	return b
+}
+
+// WithUsages adds the given value to the Usages field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Usages field.
+func (b *CertificateSpecApplyConfiguration) WithUsages(values ...string) *CertificateSpecApplyConfiguration {
+	for i := range values {
+		b.Usages = append(b.Usages, values[i])
+	}
+	return b
+}
@@ -8,16 +8,27 @@ Licensed under the MIT license. See LICENSE file in the project root for full li
 
 package v1
 
+import (
+	v1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
 // FunctionIngressSpecApplyConfiguration represents an declarative configuration of the FunctionIngressSpec type for use
 // with apply.
 type FunctionIngressSpecApplyConfiguration struct {
 	Domain            *string                               `json:"domain,omitempty"`
+	Domains           []v1.Domain                           `json:"domains,omitempty"`
 	Function          *string                               `json:"function,omitempty"`
 	FunctionNamespace *string                               `json:"functionNamespace,omitempty"`
 	Path              *string                               `json:"path,omitempty"`
+	PathMatch         *string                               `json:"pathMatch,omitempty"`
+	Routes            []FunctionRouteApplyConfiguration     `json:"routes,omitempty"`
 	IngressType       *string                               `json:"ingressType,omitempty"`
+	IngressClassName  *string                               `json:"ingressClassName,omitempty"`
+	ParentRef         *ParentReferenceApplyConfiguration    `json:"parentRef,omitempty"`
+	Gateway           *GatewayReferenceApplyConfiguration   `json:"gateway,omitempty"`
 	TLS               *FunctionIngressTLSApplyConfiguration `json:"tls,omitempty"`
 	BypassGateway     *bool                                 `json:"bypassGateway,omitempty"`
+	BypassMode        *string                               `json:"bypassMode,omitempty"`
 }
 
 // FunctionIngressSpecApplyConfiguration constructs an declarative configuration of the FunctionIngressSpec type for use with
@@ -34,6 +45,16 @@ func (b *FunctionIngressSpecApplyConfiguration) WithDomain(value string) *Functi
 	return b
 }
 
+// WithDomains adds the given value to the Domains field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Domains field.
+func (b *FunctionIngressSpecApplyConfiguration) WithDomains(values ...v1.Domain) *FunctionIngressSpecApplyConfiguration {
+	for i := range values {
+		b.Domains = append(b.Domains, values[i])
+	}
+	return b
+}
+
 // WithFunction sets the Function field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Function field is set to the value of the last call.
@@ -58,6 +79,27 @@ func (b *FunctionIngressSpecApplyConfiguration) WithPath(value string) *Function
 	return b
 }
 
+// WithPathMatch sets the PathMatch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PathMatch field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithPathMatch(value string) *FunctionIngressSpecApplyConfiguration {
+	b.PathMatch = &value
+	return b
+}
+
+// WithRoutes adds the given value to the Routes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Routes field.
+func (b *FunctionIngressSpecApplyConfiguration) WithRoutes(values ...*FunctionRouteApplyConfiguration) *FunctionIngressSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRoutes")
+		}
+		b.Routes = append(b.Routes, *values[i])
+	}
+	return b
+}
+
 // WithIngressType sets the IngressType field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the IngressType field is set to the value of the last call.
@@ -66,6 +108,30 @@ func (b *FunctionIngressSpecApplyConfiguration) WithIngressType(value string) *F
 	return b
 }
 
+// WithIngressClassName sets the IngressClassName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IngressClassName field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithIngressClassName(value string) *FunctionIngressSpecApplyConfiguration {
+	b.IngressClassName = &value
+	return b
+}
+
+// WithParentRef sets the ParentRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ParentRef field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithParentRef(value *ParentReferenceApplyConfiguration) *FunctionIngressSpecApplyConfiguration {
+	b.ParentRef = value
+	return b
+}
+
+// WithGateway sets the Gateway field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Gateway field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithGateway(value *GatewayReferenceApplyConfiguration) *FunctionIngressSpecApplyConfiguration {
+	b.Gateway = value
+	return b
+}
+
 // WithTLS sets the TLS field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the TLS field is set to the value of the last call.
@@ -81,3 +147,11 @@ func (b *FunctionIngressSpecApplyConfiguration) WithBypassGateway(value bool) *F
 	b.BypassGateway = &value
 	return b
 }
+
+// WithBypassMode sets the BypassMode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BypassMode field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithBypassMode(value string) *FunctionIngressSpecApplyConfiguration {
+	b.BypassMode = &value
+	return b
+}
@@ -16,6 +16,7 @@ import (
 // with apply.
 type FunctionIngressStatusApplyConfiguration struct {
 	Conditions []v1.Condition `json:"conditions,omitempty"`
+	TLSMode    *string        `json:"tlsMode,omitempty"`
 }
 
 // FunctionIngressStatusApplyConfiguration constructs an declarative configuration of the FunctionIngressStatus type for use with
@@ -33,3 +34,11 @@ func (b *FunctionIngressStatusApplyConfiguration) WithConditions(values ...v1.Co
 	}
 	return b
 }
+
+// WithTLSMode sets the TLSMode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLSMode field is set to the value of the last call.
+func (b *FunctionIngressStatusApplyConfiguration) WithTLSMode(value string) *FunctionIngressStatusApplyConfiguration {
+	b.TLSMode = &value
+	return b
+}
@@ -11,8 +11,11 @@ package v1
 // FunctionIngressTLSApplyConfiguration represents an declarative configuration of the FunctionIngressTLS type for use
 // with apply.
 type FunctionIngressTLSApplyConfiguration struct {
-	Enabled   *bool                              `json:"enabled,omitempty"`
-	IssuerRef *ObjectReferenceApplyConfiguration `json:"issuerRef,omitempty"`
+	Enabled     *bool                              `json:"enabled,omitempty"`
+	Mode        *string                            `json:"mode,omitempty"`
+	SecretName  *string                            `json:"secretName,omitempty"`
+	IssuerRef   *ObjectReferenceApplyConfiguration `json:"issuerRef,omitempty"`
+	Certificate *CertificateSpecApplyConfiguration `json:"certificate,omitempty"`
 }
 
 // FunctionIngressTLSApplyConfiguration constructs an declarative configuration of the FunctionIngressTLS type for use with
@@ -29,6 +32,22 @@ func (b *FunctionIngressTLSApplyConfiguration) WithEnabled(value bool) *Function
 	return b
 }
 
+// WithMode sets the Mode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mode field is set to the value of the last call.
+func (b *FunctionIngressTLSApplyConfiguration) WithMode(value string) *FunctionIngressTLSApplyConfiguration {
+	b.Mode = &value
+	return b
+}
+
+// WithSecretName sets the SecretName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretName field is set to the value of the last call.
+func (b *FunctionIngressTLSApplyConfiguration) WithSecretName(value string) *FunctionIngressTLSApplyConfiguration {
+	b.SecretName = &value
+	return b
+}
+
 // WithIssuerRef sets the IssuerRef field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the IssuerRef field is set to the value of the last call.
@@ -36,3 +55,11 @@ func (b *FunctionIngressTLSApplyConfiguration) WithIssuerRef(value *ObjectRefere
 	b.IssuerRef = value
 	return b
 }
+
+// WithCertificate sets the Certificate field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Certificate field is set to the value of the last call.
+func (b *FunctionIngressTLSApplyConfiguration) WithCertificate(value *CertificateSpecApplyConfiguration) *FunctionIngressTLSApplyConfiguration {
+	b.Certificate = value
+	return b
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// FunctionRouteApplyConfiguration represents an declarative configuration of the FunctionRoute type for use
+// with apply.
+type FunctionRouteApplyConfiguration struct {
+	Path              *string `json:"path,omitempty"`
+	Function          *string `json:"function,omitempty"`
+	FunctionNamespace *string `json:"functionNamespace,omitempty"`
+	Rewrite           *string `json:"rewrite,omitempty"`
+}
+
+// FunctionRouteApplyConfiguration constructs an declarative configuration of the FunctionRoute type for use with
+// apply.
+func FunctionRoute() *FunctionRouteApplyConfiguration {
+	return &FunctionRouteApplyConfiguration{}
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *FunctionRouteApplyConfiguration) WithPath(value string) *FunctionRouteApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithFunction sets the Function field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Function field is set to the value of the last call.
+func (b *FunctionRouteApplyConfiguration) WithFunction(value string) *FunctionRouteApplyConfiguration {
+	b.Function = &value
+	return b
+}
+
+// WithFunctionNamespace sets the FunctionNamespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FunctionNamespace field is set to the value of the last call.
+func (b *FunctionRouteApplyConfiguration) WithFunctionNamespace(value string) *FunctionRouteApplyConfiguration {
+	b.FunctionNamespace = &value
+	return b
+}
+
+// WithRewrite sets the Rewrite field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Rewrite field is set to the value of the last call.
+func (b *FunctionRouteApplyConfiguration) WithRewrite(value string) *FunctionRouteApplyConfiguration {
+	b.Rewrite = &value
+	return b
+}
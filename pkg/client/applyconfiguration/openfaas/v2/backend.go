@@ -0,0 +1,56 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+// BackendApplyConfiguration represents an declarative configuration of the Backend type for use
+// with apply.
+type BackendApplyConfiguration struct {
+	Type              *string                             `json:"type,omitempty"`
+	Function          *string                             `json:"function,omitempty"`
+	FunctionNamespace *string                             `json:"functionNamespace,omitempty"`
+	Gateway           *GatewayReferenceApplyConfiguration `json:"gateway,omitempty"`
+}
+
+// BackendApplyConfiguration constructs an declarative configuration of the Backend type for use with
+// apply.
+func Backend() *BackendApplyConfiguration {
+	return &BackendApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *BackendApplyConfiguration) WithType(value string) *BackendApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithFunction sets the Function field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Function field is set to the value of the last call.
+func (b *BackendApplyConfiguration) WithFunction(value string) *BackendApplyConfiguration {
+	b.Function = &value
+	return b
+}
+
+// WithFunctionNamespace sets the FunctionNamespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FunctionNamespace field is set to the value of the last call.
+func (b *BackendApplyConfiguration) WithFunctionNamespace(value string) *BackendApplyConfiguration {
+	b.FunctionNamespace = &value
+	return b
+}
+
+// WithGateway sets the Gateway field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Gateway field is set to the value of the last call.
+func (b *BackendApplyConfiguration) WithGateway(value *GatewayReferenceApplyConfiguration) *BackendApplyConfiguration {
+	b.Gateway = value
+	return b
+}
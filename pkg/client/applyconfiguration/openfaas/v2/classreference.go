@@ -0,0 +1,56 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+// ClassReferenceApplyConfiguration represents an declarative configuration of the ClassReference type for use
+// with apply.
+type ClassReferenceApplyConfiguration struct {
+	Kind      *string                            `json:"kind,omitempty"`
+	Name      *string                            `json:"name,omitempty"`
+	Dialect   *string                            `json:"dialect,omitempty"`
+	ParentRef *ParentReferenceApplyConfiguration `json:"parentRef,omitempty"`
+}
+
+// ClassReferenceApplyConfiguration constructs an declarative configuration of the ClassReference type for use with
+// apply.
+func ClassReference() *ClassReferenceApplyConfiguration {
+	return &ClassReferenceApplyConfiguration{}
+}
+
+// WithKind sets the Kind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Kind field is set to the value of the last call.
+func (b *ClassReferenceApplyConfiguration) WithKind(value string) *ClassReferenceApplyConfiguration {
+	b.Kind = &value
+	return b
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *ClassReferenceApplyConfiguration) WithName(value string) *ClassReferenceApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithDialect sets the Dialect field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Dialect field is set to the value of the last call.
+func (b *ClassReferenceApplyConfiguration) WithDialect(value string) *ClassReferenceApplyConfiguration {
+	b.Dialect = &value
+	return b
+}
+
+// WithParentRef sets the ParentRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ParentRef field is set to the value of the last call.
+func (b *ClassReferenceApplyConfiguration) WithParentRef(value *ParentReferenceApplyConfiguration) *ClassReferenceApplyConfiguration {
+	b.ParentRef = value
+	return b
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
+)
+
+// FunctionIngressSpecApplyConfiguration represents an declarative configuration of the FunctionIngressSpec type for use
+// with apply.
+type FunctionIngressSpecApplyConfiguration struct {
+	Domains   []v2.Domain                       `json:"domains,omitempty"`
+	Class     *ClassReferenceApplyConfiguration `json:"class,omitempty"`
+	Backend   *BackendApplyConfiguration        `json:"backend,omitempty"`
+	Routes    []RouteApplyConfiguration         `json:"routes,omitempty"`
+	PathMatch *string                           `json:"pathMatch,omitempty"`
+	TLS       *TLSApplyConfiguration            `json:"tls,omitempty"`
+}
+
+// FunctionIngressSpecApplyConfiguration constructs an declarative configuration of the FunctionIngressSpec type for use with
+// apply.
+func FunctionIngressSpec() *FunctionIngressSpecApplyConfiguration {
+	return &FunctionIngressSpecApplyConfiguration{}
+}
+
+// WithDomains adds the given value to the Domains field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Domains field.
+func (b *FunctionIngressSpecApplyConfiguration) WithDomains(values ...v2.Domain) *FunctionIngressSpecApplyConfiguration {
+	for i := range values {
+		b.Domains = append(b.Domains, values[i])
+	}
+	return b
+}
+
+// WithClass sets the Class field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Class field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithClass(value *ClassReferenceApplyConfiguration) *FunctionIngressSpecApplyConfiguration {
+	b.Class = value
+	return b
+}
+
+// WithBackend sets the Backend field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Backend field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithBackend(value *BackendApplyConfiguration) *FunctionIngressSpecApplyConfiguration {
+	b.Backend = value
+	return b
+}
+
+// WithRoutes adds the given value to the Routes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Routes field.
+func (b *FunctionIngressSpecApplyConfiguration) WithRoutes(values ...*RouteApplyConfiguration) *FunctionIngressSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRoutes")
+		}
+		b.Routes = append(b.Routes, *values[i])
+	}
+	return b
+}
+
+// WithPathMatch sets the PathMatch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PathMatch field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithPathMatch(value string) *FunctionIngressSpecApplyConfiguration {
+	b.PathMatch = &value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *FunctionIngressSpecApplyConfiguration) WithTLS(value *TLSApplyConfiguration) *FunctionIngressSpecApplyConfiguration {
+	b.TLS = value
+	return b
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+// TLSApplyConfiguration represents an declarative configuration of the TLS type for use
+// with apply.
+type TLSApplyConfiguration struct {
+	Mode        *string                            `json:"mode,omitempty"`
+	SecretName  *string                            `json:"secretName,omitempty"`
+	IssuerRef   *IssuerReferenceApplyConfiguration `json:"issuerRef,omitempty"`
+	Certificate *CertificateSpecApplyConfiguration `json:"certificate,omitempty"`
+}
+
+// TLSApplyConfiguration constructs an declarative configuration of the TLS type for use with
+// apply.
+func TLS() *TLSApplyConfiguration {
+	return &TLSApplyConfiguration{}
+}
+
+// WithMode sets the Mode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mode field is set to the value of the last call.
+func (b *TLSApplyConfiguration) WithMode(value string) *TLSApplyConfiguration {
+	b.Mode = &value
+	return b
+}
+
+// WithSecretName sets the SecretName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretName field is set to the value of the last call.
+func (b *TLSApplyConfiguration) WithSecretName(value string) *TLSApplyConfiguration {
+	b.SecretName = &value
+	return b
+}
+
+// WithIssuerRef sets the IssuerRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IssuerRef field is set to the value of the last call.
+func (b *TLSApplyConfiguration) WithIssuerRef(value *IssuerReferenceApplyConfiguration) *TLSApplyConfiguration {
+	b.IssuerRef = value
+	return b
+}
+
+// WithCertificate sets the Certificate field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Certificate field is set to the value of the last call.
+func (b *TLSApplyConfiguration) WithCertificate(value *CertificateSpecApplyConfiguration) *TLSApplyConfiguration {
+	b.Certificate = value
+	return b
+}
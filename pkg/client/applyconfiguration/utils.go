@@ -10,7 +10,9 @@ package applyconfiguration
 
 import (
 	v1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	v2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
 	openfaasv1 "github.com/openfaas/ingress-operator/pkg/client/applyconfiguration/openfaas/v1"
+	openfaasv2 "github.com/openfaas/ingress-operator/pkg/client/applyconfiguration/openfaas/v2"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -19,6 +21,10 @@ import (
 func ForKind(kind schema.GroupVersionKind) interface{} {
 	switch kind {
 	// Group=openfaas.com, Version=v1
+	case v1.SchemeGroupVersion.WithKind("CertificatePrivateKey"):
+		return &openfaasv1.CertificatePrivateKeyApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("CertificateSpec"):
+		return &openfaasv1.CertificateSpecApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("FunctionIngress"):
 		return &openfaasv1.FunctionIngressApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("FunctionIngressSpec"):
@@ -27,8 +33,40 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &openfaasv1.FunctionIngressStatusApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("FunctionIngressTLS"):
 		return &openfaasv1.FunctionIngressTLSApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("FunctionRoute"):
+		return &openfaasv1.FunctionRouteApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("GatewayReference"):
+		return &openfaasv1.GatewayReferenceApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("ObjectReference"):
 		return &openfaasv1.ObjectReferenceApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("ParentReference"):
+		return &openfaasv1.ParentReferenceApplyConfiguration{}
+
+		// Group=openfaas.com, Version=v2
+	case v2.SchemeGroupVersion.WithKind("Backend"):
+		return &openfaasv2.BackendApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("CertificatePrivateKey"):
+		return &openfaasv2.CertificatePrivateKeyApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("CertificateSpec"):
+		return &openfaasv2.CertificateSpecApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("ClassReference"):
+		return &openfaasv2.ClassReferenceApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("FunctionIngress"):
+		return &openfaasv2.FunctionIngressApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("FunctionIngressSpec"):
+		return &openfaasv2.FunctionIngressSpecApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("FunctionIngressStatus"):
+		return &openfaasv2.FunctionIngressStatusApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("GatewayReference"):
+		return &openfaasv2.GatewayReferenceApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("IssuerReference"):
+		return &openfaasv2.IssuerReferenceApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("ParentReference"):
+		return &openfaasv2.ParentReferenceApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("Route"):
+		return &openfaasv2.RouteApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("TLS"):
+		return &openfaasv2.TLSApplyConfiguration{}
 
 	}
 	return nil
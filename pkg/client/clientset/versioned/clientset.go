@@ -13,6 +13,7 @@ import (
 	"net/http"
 
 	openfaasv1 "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/typed/openfaas/v1"
+	openfaasv2 "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/typed/openfaas/v2"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -21,12 +22,14 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	OpenfaasV1() openfaasv1.OpenfaasV1Interface
+	OpenfaasV2() openfaasv2.OpenfaasV2Interface
 }
 
 // Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
 	openfaasV1 *openfaasv1.OpenfaasV1Client
+	openfaasV2 *openfaasv2.OpenfaasV2Client
 }
 
 // OpenfaasV1 retrieves the OpenfaasV1Client
@@ -34,6 +37,11 @@ func (c *Clientset) OpenfaasV1() openfaasv1.OpenfaasV1Interface {
 	return c.openfaasV1
 }
 
+// OpenfaasV2 retrieves the OpenfaasV2Client
+func (c *Clientset) OpenfaasV2() openfaasv2.OpenfaasV2Interface {
+	return c.openfaasV2
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -82,6 +90,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.openfaasV2, err = openfaasv2.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
@@ -104,6 +116,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.openfaasV1 = openfaasv1.New(c)
+	cs.openfaasV2 = openfaasv2.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -12,6 +12,8 @@ import (
 	clientset "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned"
 	openfaasv1 "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/typed/openfaas/v1"
 	fakeopenfaasv1 "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/typed/openfaas/v1/fake"
+	openfaasv2 "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/typed/openfaas/v2"
+	fakeopenfaasv2 "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/typed/openfaas/v2/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -73,3 +75,8 @@ var (
 func (c *Clientset) OpenfaasV1() openfaasv1.OpenfaasV1Interface {
 	return &fakeopenfaasv1.FakeOpenfaasV1{Fake: &c.Fake}
 }
+
+// OpenfaasV2 retrieves the OpenfaasV2Client
+func (c *Clientset) OpenfaasV2() openfaasv2.OpenfaasV2Interface {
+	return &fakeopenfaasv2.FakeOpenfaasV2{Fake: &c.Fake}
+}
@@ -10,6 +10,7 @@ package scheme
 
 import (
 	openfaasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	openfaasv2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -22,6 +23,7 @@ var Codecs = serializer.NewCodecFactory(Scheme)
 var ParameterCodec = runtime.NewParameterCodec(Scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	openfaasv1.AddToScheme,
+	openfaasv2.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
@@ -94,6 +94,18 @@ func (c *FakeFunctionIngresses) Update(ctx context.Context, functionIngress *v1.
 	return obj.(*v1.FunctionIngress), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeFunctionIngresses) UpdateStatus(ctx context.Context, functionIngress *v1.FunctionIngress, opts metav1.UpdateOptions) (*v1.FunctionIngress, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(functioningressesResource, "status", c.ns, functionIngress), &v1.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.FunctionIngress), err
+}
+
 // Delete takes name of the functionIngress and deletes it. Returns an error if one occurs.
 func (c *FakeFunctionIngresses) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -142,3 +154,26 @@ func (c *FakeFunctionIngresses) Apply(ctx context.Context, functionIngress *open
 	}
 	return obj.(*v1.FunctionIngress), err
 }
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeFunctionIngresses) ApplyStatus(ctx context.Context, functionIngress *openfaasv1.FunctionIngressApplyConfiguration, opts metav1.ApplyOptions) (result *v1.FunctionIngress, err error) {
+	if functionIngress == nil {
+		return nil, fmt.Errorf("functionIngress provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(functionIngress)
+	if err != nil {
+		return nil, err
+	}
+	name := functionIngress.Name
+	if name == nil {
+		return nil, fmt.Errorf("functionIngress.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(functioningressesResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.FunctionIngress), err
+}
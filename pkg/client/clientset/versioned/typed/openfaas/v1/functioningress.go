@@ -33,6 +33,7 @@ type FunctionIngressesGetter interface {
 type FunctionIngressInterface interface {
 	Create(ctx context.Context, functionIngress *v1.FunctionIngress, opts metav1.CreateOptions) (*v1.FunctionIngress, error)
 	Update(ctx context.Context, functionIngress *v1.FunctionIngress, opts metav1.UpdateOptions) (*v1.FunctionIngress, error)
+	UpdateStatus(ctx context.Context, functionIngress *v1.FunctionIngress, opts metav1.UpdateOptions) (*v1.FunctionIngress, error)
 	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
 	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.FunctionIngress, error)
@@ -40,6 +41,7 @@ type FunctionIngressInterface interface {
 	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.FunctionIngress, err error)
 	Apply(ctx context.Context, functionIngress *openfaasv1.FunctionIngressApplyConfiguration, opts metav1.ApplyOptions) (result *v1.FunctionIngress, err error)
+	ApplyStatus(ctx context.Context, functionIngress *openfaasv1.FunctionIngressApplyConfiguration, opts metav1.ApplyOptions) (result *v1.FunctionIngress, err error)
 	FunctionIngressExpansion
 }
 
@@ -129,6 +131,22 @@ func (c *functionIngresses) Update(ctx context.Context, functionIngress *v1.Func
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *functionIngresses) UpdateStatus(ctx context.Context, functionIngress *v1.FunctionIngress, opts metav1.UpdateOptions) (result *v1.FunctionIngress, err error) {
+	result = &v1.FunctionIngress{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(functionIngress.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(functionIngress).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the functionIngress and deletes it. Returns an error if one occurs.
 func (c *functionIngresses) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
 	return c.client.Delete().
@@ -196,3 +214,33 @@ func (c *functionIngresses) Apply(ctx context.Context, functionIngress *openfaas
 		Into(result)
 	return
 }
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *functionIngresses) ApplyStatus(ctx context.Context, functionIngress *openfaasv1.FunctionIngressApplyConfiguration, opts metav1.ApplyOptions) (result *v1.FunctionIngress, err error) {
+	if functionIngress == nil {
+		return nil, fmt.Errorf("functionIngress provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(functionIngress)
+	if err != nil {
+		return nil, err
+	}
+
+	name := functionIngress.Name
+	if name == nil {
+		return nil, fmt.Errorf("functionIngress.Name must be provided to Apply")
+	}
+
+	result = &v1.FunctionIngress{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+
+	v2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
+	openfaasv2 "github.com/openfaas/ingress-operator/pkg/client/applyconfiguration/openfaas/v2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeFunctionIngresses implements FunctionIngressInterface
+type FakeFunctionIngresses struct {
+	Fake *FakeOpenfaasV2
+	ns   string
+}
+
+var functioningressesResource = v2.SchemeGroupVersion.WithResource("functioningresses")
+
+var functioningressesKind = v2.SchemeGroupVersion.WithKind("FunctionIngress")
+
+// Get takes name of the functionIngress, and returns the corresponding functionIngress object, and an error if there is any.
+func (c *FakeFunctionIngresses) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2.FunctionIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(functioningressesResource, c.ns, name), &v2.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.FunctionIngress), err
+}
+
+// List takes label and field selectors, and returns the list of FunctionIngresses that match those selectors.
+func (c *FakeFunctionIngresses) List(ctx context.Context, opts v1.ListOptions) (result *v2.FunctionIngressList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(functioningressesResource, functioningressesKind, c.ns, opts), &v2.FunctionIngressList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v2.FunctionIngressList{ListMeta: obj.(*v2.FunctionIngressList).ListMeta}
+	for _, item := range obj.(*v2.FunctionIngressList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested functionIngresses.
+func (c *FakeFunctionIngresses) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(functioningressesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a functionIngress and creates it.  Returns the server's representation of the functionIngress, and an error, if there is any.
+func (c *FakeFunctionIngresses) Create(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.CreateOptions) (result *v2.FunctionIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(functioningressesResource, c.ns, functionIngress), &v2.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.FunctionIngress), err
+}
+
+// Update takes the representation of a functionIngress and updates it. Returns the server's representation of the functionIngress, and an error, if there is any.
+func (c *FakeFunctionIngresses) Update(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.UpdateOptions) (result *v2.FunctionIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(functioningressesResource, c.ns, functionIngress), &v2.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.FunctionIngress), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeFunctionIngresses) UpdateStatus(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.UpdateOptions) (*v2.FunctionIngress, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(functioningressesResource, "status", c.ns, functionIngress), &v2.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.FunctionIngress), err
+}
+
+// Delete takes name of the functionIngress and deletes it. Returns an error if one occurs.
+func (c *FakeFunctionIngresses) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(functioningressesResource, c.ns, name, opts), &v2.FunctionIngress{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeFunctionIngresses) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(functioningressesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v2.FunctionIngressList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched functionIngress.
+func (c *FakeFunctionIngresses) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.FunctionIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(functioningressesResource, c.ns, name, pt, data, subresources...), &v2.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.FunctionIngress), err
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied functionIngress.
+func (c *FakeFunctionIngresses) Apply(ctx context.Context, functionIngress *openfaasv2.FunctionIngressApplyConfiguration, opts v1.ApplyOptions) (result *v2.FunctionIngress, err error) {
+	if functionIngress == nil {
+		return nil, fmt.Errorf("functionIngress provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(functionIngress)
+	if err != nil {
+		return nil, err
+	}
+	name := functionIngress.Name
+	if name == nil {
+		return nil, fmt.Errorf("functionIngress.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(functioningressesResource, c.ns, *name, types.ApplyPatchType, data), &v2.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.FunctionIngress), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeFunctionIngresses) ApplyStatus(ctx context.Context, functionIngress *openfaasv2.FunctionIngressApplyConfiguration, opts v1.ApplyOptions) (result *v2.FunctionIngress, err error) {
+	if functionIngress == nil {
+		return nil, fmt.Errorf("functionIngress provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(functionIngress)
+	if err != nil {
+		return nil, err
+	}
+	name := functionIngress.Name
+	if name == nil {
+		return nil, fmt.Errorf("functionIngress.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(functioningressesResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v2.FunctionIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2.FunctionIngress), err
+}
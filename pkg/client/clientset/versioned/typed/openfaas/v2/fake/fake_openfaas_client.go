@@ -0,0 +1,30 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v2 "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/typed/openfaas/v2"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeOpenfaasV2 struct {
+	*testing.Fake
+}
+
+func (c *FakeOpenfaasV2) FunctionIngresses(namespace string) v2.FunctionIngressInterface {
+	return &FakeFunctionIngresses{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeOpenfaasV2) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+	"time"
+
+	v2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
+	openfaasv2 "github.com/openfaas/ingress-operator/pkg/client/applyconfiguration/openfaas/v2"
+	scheme "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// FunctionIngressesGetter has a method to return a FunctionIngressInterface.
+// A group's client should implement this interface.
+type FunctionIngressesGetter interface {
+	FunctionIngresses(namespace string) FunctionIngressInterface
+}
+
+// FunctionIngressInterface has methods to work with FunctionIngress resources.
+type FunctionIngressInterface interface {
+	Create(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.CreateOptions) (*v2.FunctionIngress, error)
+	Update(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.UpdateOptions) (*v2.FunctionIngress, error)
+	UpdateStatus(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.UpdateOptions) (*v2.FunctionIngress, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v2.FunctionIngress, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v2.FunctionIngressList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.FunctionIngress, err error)
+	Apply(ctx context.Context, functionIngress *openfaasv2.FunctionIngressApplyConfiguration, opts v1.ApplyOptions) (result *v2.FunctionIngress, err error)
+	ApplyStatus(ctx context.Context, functionIngress *openfaasv2.FunctionIngressApplyConfiguration, opts v1.ApplyOptions) (result *v2.FunctionIngress, err error)
+	FunctionIngressExpansion
+}
+
+// functionIngresses implements FunctionIngressInterface
+type functionIngresses struct {
+	client rest.Interface
+	ns     string
+}
+
+// newFunctionIngresses returns a FunctionIngresses
+func newFunctionIngresses(c *OpenfaasV2Client, namespace string) *functionIngresses {
+	return &functionIngresses{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the functionIngress, and returns the corresponding functionIngress object, and an error if there is any.
+func (c *functionIngresses) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2.FunctionIngress, err error) {
+	result = &v2.FunctionIngress{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of FunctionIngresses that match those selectors.
+func (c *functionIngresses) List(ctx context.Context, opts v1.ListOptions) (result *v2.FunctionIngressList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v2.FunctionIngressList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested functionIngresses.
+func (c *functionIngresses) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a functionIngress and creates it.  Returns the server's representation of the functionIngress, and an error, if there is any.
+func (c *functionIngresses) Create(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.CreateOptions) (result *v2.FunctionIngress, err error) {
+	result = &v2.FunctionIngress{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(functionIngress).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a functionIngress and updates it. Returns the server's representation of the functionIngress, and an error, if there is any.
+func (c *functionIngresses) Update(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.UpdateOptions) (result *v2.FunctionIngress, err error) {
+	result = &v2.FunctionIngress{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(functionIngress.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(functionIngress).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *functionIngresses) UpdateStatus(ctx context.Context, functionIngress *v2.FunctionIngress, opts v1.UpdateOptions) (result *v2.FunctionIngress, err error) {
+	result = &v2.FunctionIngress{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(functionIngress.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(functionIngress).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the functionIngress and deletes it. Returns an error if one occurs.
+func (c *functionIngresses) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *functionIngresses) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("functioningresses").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched functionIngress.
+func (c *functionIngresses) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2.FunctionIngress, err error) {
+	result = &v2.FunctionIngress{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied functionIngress.
+func (c *functionIngresses) Apply(ctx context.Context, functionIngress *openfaasv2.FunctionIngressApplyConfiguration, opts v1.ApplyOptions) (result *v2.FunctionIngress, err error) {
+	if functionIngress == nil {
+		return nil, fmt.Errorf("functionIngress provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(functionIngress)
+	if err != nil {
+		return nil, err
+	}
+	name := functionIngress.Name
+	if name == nil {
+		return nil, fmt.Errorf("functionIngress.Name must be provided to Apply")
+	}
+	result = &v2.FunctionIngress{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *functionIngresses) ApplyStatus(ctx context.Context, functionIngress *openfaasv2.FunctionIngressApplyConfiguration, opts v1.ApplyOptions) (result *v2.FunctionIngress, err error) {
+	if functionIngress == nil {
+		return nil, fmt.Errorf("functionIngress provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(functionIngress)
+	if err != nil {
+		return nil, err
+	}
+
+	name := functionIngress.Name
+	if name == nil {
+		return nil, fmt.Errorf("functionIngress.Name must be provided to Apply")
+	}
+
+	result = &v2.FunctionIngress{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("functioningresses").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
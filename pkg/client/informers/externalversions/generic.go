@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	v1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	v2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -46,6 +47,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1.SchemeGroupVersion.WithResource("functioningresses"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Openfaas().V1().FunctionIngresses().Informer()}, nil
 
+		// Group=openfaas.com, Version=v2
+	case v2.SchemeGroupVersion.WithResource("functioningresses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Openfaas().V2().FunctionIngresses().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
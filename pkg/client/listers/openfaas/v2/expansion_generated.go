@@ -0,0 +1,17 @@
+/*
+Copyright 2023 OpenFaaS Author(s)
+
+Licensed under the MIT license. See LICENSE file in the project root for full license information.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+// FunctionIngressListerExpansion allows custom methods to be added to
+// FunctionIngressLister.
+type FunctionIngressListerExpansion interface{}
+
+// FunctionIngressNamespaceListerExpansion allows custom methods to be added to
+// FunctionIngressNamespaceLister.
+type FunctionIngressNamespaceListerExpansion interface{}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+// FunctionLabel is set on the Services and EndpointSlices created for a
+// function in another namespace when the gateway is bypassed, to the
+// function as it is addressed on the gateway, such as "nodeinfo.openfaas-fn".
+const FunctionLabel = "com.openfaas.ingress.function"
+
+// EndpointSliceManager is the endpointslice.kubernetes.io/managed-by label of
+// the EndpointSlices mirrored from a function, so that they are left alone by
+// the EndpointSlice controllers of Kubernetes.
+const EndpointSliceManager = "ingress-operator.openfaas.com"
+
+// maxNameLength is the longest name of a Service, a DNS-1035 label.
+const maxNameLength = 63
+
+// DefaultBypassHysteresis is how long a FunctionIngress with bypassMode
+// "auto" keeps using the gateway after falling back to it, so that a function
+// which is scaled up and down does not switch the Ingress back and forth.
+const DefaultBypassHysteresis = time.Minute
+
+// BypassCrossNamespace is true when the gateway is bypassed for a function
+// in another namespace to the FunctionIngress. An Ingress can only send
+// requests to a Service in its own namespace, so one is created for the
+// function, see FunctionServiceName.
+func BypassCrossNamespace(fni *faasv1.FunctionIngress, function FunctionRef) bool {
+	return fni.Spec.MayBypassGateway() && len(function.Namespace) > 0 && function.Namespace != fni.Namespace
+}
+
+// ResolveBypass returns a copy of a FunctionIngress with bypassMode "auto"
+// which either bypasses the gateway or not, to be rendered. The functions
+// keep the namespaces they have on the gateway, see Functions.
+func ResolveBypass(fni *faasv1.FunctionIngress, bypass bool) *faasv1.FunctionIngress {
+	resolved := fni.DeepCopy()
+	resolved.Spec.BypassMode = ""
+	resolved.Spec.BypassGateway = bypass
+	if !bypass {
+		return resolved
+	}
+
+	if len(resolved.Spec.FunctionNamespace) == 0 {
+		resolved.Spec.FunctionNamespace = DefaultFunctionNamespace
+	}
+	return resolved
+}
+
+// FunctionServiceName is the name of the Service created in the namespace of
+// the FunctionIngress for a function in another namespace, such as
+// "nodeinfo-nodeinfo-openfaas-fn-1a2b3c4d", see UniqueName.
+func FunctionServiceName(fni *faasv1.FunctionIngress, function FunctionRef) string {
+	return UniqueName(fni.Name, function.Name, function.Namespace)
+}
+
+// ShortName joins the parts with "-" into a name which can be used for a
+// Service, when it is too long the end is replaced by a hash of the parts.
+func ShortName(parts ...string) string {
+	name := joinName(parts)
+	if len(name) <= maxNameLength {
+		return name
+	}
+	return withHash(name, parts)
+}
+
+// UniqueName is ShortName with a hash of the parts always added, as parts
+// which contain a "-", such as "foo" and "bar-baz", join into the same name
+// as "foo-bar" and "baz".
+func UniqueName(parts ...string) string {
+	return withHash(joinName(parts), parts)
+}
+
+func joinName(parts []string) string {
+	return strings.ReplaceAll(strings.Join(parts, "-"), ".", "-")
+}
+
+// withHash ends the name with a hash of the parts, shortening it to fit
+func withHash(name string, parts []string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.Join(parts, "/")))
+	suffix := fmt.Sprintf("-%08x", h.Sum32())
+	if len(name) > maxNameLength-len(suffix) {
+		name = strings.TrimRight(name[:maxNameLength-len(suffix)], "-")
+	}
+	return name + suffix
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+func TestBackend_Bypass(t *testing.T) {
+	gateway := Gateway{Name: "gateway", Namespace: "openfaas", Port: 8080, Service: "gateway"}
+
+	cases := []struct {
+		name        string
+		bypass      bool
+		route       Route
+		wantService string
+		wantPort    int32
+	}{
+		{
+			name:        "gateway",
+			route:       Route{Function: "nodeinfo", FunctionNamespace: "openfaas-fn"},
+			wantService: "gateway",
+			wantPort:    8080,
+		},
+		{
+			name:        "bypass in the same namespace",
+			bypass:      true,
+			route:       Route{Function: "nodeinfo", FunctionNamespace: "openfaas"},
+			wantService: "nodeinfo",
+			wantPort:    OpenfaasWorkloadPort,
+		},
+		{
+			name:        "bypass without a namespace",
+			bypass:      true,
+			route:       Route{Function: "nodeinfo"},
+			wantService: "nodeinfo",
+			wantPort:    OpenfaasWorkloadPort,
+		},
+		{
+			name:        "bypass to another namespace",
+			bypass:      true,
+			route:       Route{Function: "nodeinfo", FunctionNamespace: "openfaas-fn"},
+			wantService: "api-nodeinfo-openfaas-fn-d32540fb",
+			wantPort:    OpenfaasWorkloadPort,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "openfaas"},
+				Spec:       faasv1.FunctionIngressSpec{BypassGateway: tc.bypass},
+			}
+
+			service, port := Backend(fni, tc.route, gateway)
+			if service != tc.wantService || port != tc.wantPort {
+				t.Errorf("want %s:%d, got %s:%d", tc.wantService, tc.wantPort, service, port)
+			}
+		})
+	}
+}
+
+func TestShortName(t *testing.T) {
+	if got := ShortName("api.example", "nodeinfo", "openfaas-fn"); got != "api-example-nodeinfo-openfaas-fn" {
+		t.Errorf("want dots to be replaced, got %s", got)
+	}
+
+	long := strings.Repeat("a", 40)
+	first := ShortName(long, "nodeinfo", "openfaas-fn")
+	second := ShortName(long, "nodeinfo", "staging-fn")
+	if first == second {
+		t.Errorf("want names for different namespaces to differ, got %s", first)
+	}
+	for _, name := range []string{first, second} {
+		if msgs := validation.IsDNS1035Label(name); len(msgs) > 0 {
+			t.Errorf("want %s to be a valid Service name, got %v", name, msgs)
+		}
+	}
+}
+
+func TestFunctionServiceName_IsUnique(t *testing.T) {
+	first := FunctionServiceName(&faasv1.FunctionIngress{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+		FunctionRef{Name: "bar-baz", Namespace: "openfaas-fn"})
+	second := FunctionServiceName(&faasv1.FunctionIngress{ObjectMeta: metav1.ObjectMeta{Name: "foo-bar"}},
+		FunctionRef{Name: "baz", Namespace: "openfaas-fn"})
+	if first == second {
+		t.Errorf("want names for different functions to differ, got %s", first)
+	}
+
+	long := FunctionServiceName(&faasv1.FunctionIngress{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 60)}},
+		FunctionRef{Name: "nodeinfo", Namespace: "openfaas-fn"})
+	for _, name := range []string{first, second, long} {
+		if msgs := validation.IsDNS1035Label(name); len(msgs) > 0 {
+			t.Errorf("want %s to be a valid Service name, got %v", name, msgs)
+		}
+	}
+}
+
+func TestResolveBypass(t *testing.T) {
+	fni := &faasv1.FunctionIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+		Spec:       faasv1.FunctionIngressSpec{Function: "nodeinfo", BypassMode: faasv1.BypassModeAuto},
+	}
+
+	gateway := ResolveBypass(fni, false)
+	if gateway.Spec.BypassesGateway() || gateway.Spec.FunctionNamespace != "" {
+		t.Errorf("want the gateway with the gateway's namespace, got %+v", gateway.Spec)
+	}
+	if routes := MakeRoutes(gateway); routes[0].Rewrite != "/function/nodeinfo" {
+		t.Errorf("want the path to be rewritten, got %+v", routes[0])
+	}
+
+	bypass := ResolveBypass(fni, true)
+	if !bypass.Spec.BypassesGateway() || bypass.Spec.FunctionNamespace != DefaultFunctionNamespace {
+		t.Errorf("want the gateway to be bypassed for %s, got %+v", DefaultFunctionNamespace, bypass.Spec)
+	}
+	if service, _ := Backend(bypass, MakeRoutes(bypass)[0], Gateway{}); service != "nodeinfo-nodeinfo-openfaas-fn-5f045a21" {
+		t.Errorf("want the Service created for the function, got %s", service)
+	}
+
+	if fni.Spec.BypassMode != faasv1.BypassModeAuto {
+		t.Errorf("want the FunctionIngress to be unchanged, got %q", fni.Spec.BypassMode)
+	}
+}
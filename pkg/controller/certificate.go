@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultCertificateExpiryThreshold is how long before NotAfter a
+// certificate is reported as expiring soon, unless the operator sets
+// -certificate-expiry-threshold.
+const DefaultCertificateExpiryThreshold = 14 * 24 * time.Hour
+
+// ParseCertificate returns the leaf certificate from the tls.crt key of a
+// TLS secret.
+func ParseCertificate(secret *corev1.Secret) (*x509.Certificate, error) {
+	data := secret.Data[corev1.TLSCertKey]
+	if len(data) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", secret.Name, corev1.TLSCertKey)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("secret %s has no PEM encoded certificate in %s", secret.Name, corev1.TLSCertKey)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s has an invalid certificate: %w", secret.Name, err)
+	}
+	return cert, nil
+}
+
+// UncoveredHosts returns the hosts which are not valid for the certificate.
+// A wildcard host, such as "*.example.com", is only covered by the same
+// wildcard in the SANs of the certificate.
+func UncoveredHosts(cert *x509.Certificate, hosts []string) []string {
+	uncovered := []string{}
+	for _, host := range hosts {
+		if strings.HasPrefix(host, "*.") {
+			if !hasDNSName(cert, host) {
+				uncovered = append(uncovered, host)
+			}
+			continue
+		}
+		if err := cert.VerifyHostname(host); err != nil {
+			uncovered = append(uncovered, host)
+		}
+	}
+	return uncovered
+}
+
+func hasDNSName(cert *x509.Certificate, name string) bool {
+	for _, dnsName := range cert.DNSNames {
+		if strings.EqualFold(dnsName, name) {
+			return true
+		}
+	}
+	return false
+}
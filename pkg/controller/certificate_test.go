@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"crypto/x509"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUncoveredHosts(t *testing.T) {
+	cases := []struct {
+		name     string
+		dnsNames []string
+		hosts    []string
+		want     []string
+	}{
+		{
+			name:     "exact match",
+			dnsNames: []string{"api.example.com"},
+			hosts:    []string{"api.example.com"},
+			want:     []string{},
+		},
+		{
+			name:     "wildcard certificate covers a subdomain",
+			dnsNames: []string{"*.example.com"},
+			hosts:    []string{"api.example.com", "www.api.example.com"},
+			want:     []string{"www.api.example.com"},
+		},
+		{
+			name:     "wildcard host needs the same wildcard",
+			dnsNames: []string{"api.eu.example.com"},
+			hosts:    []string{"*.eu.example.com"},
+			want:     []string{"*.eu.example.com"},
+		},
+		{
+			name:     "wildcard host is covered by the same wildcard",
+			dnsNames: []string{"*.EU.example.com"},
+			hosts:    []string{"*.eu.example.com"},
+			want:     []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cert := &x509.Certificate{DNSNames: tc.dnsNames}
+
+			got := UncoveredHosts(cert, tc.hosts)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseCertificate_Invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		data map[string][]byte
+	}{
+		{name: "no certificate", data: map[string][]byte{}},
+		{name: "not PEM", data: map[string][]byte{corev1.TLSCertKey: []byte("cert")}},
+		{name: "not a certificate", data: map[string][]byte{corev1.TLSCertKey: []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "api.example.com-cert"},
+				Data:       tc.data,
+			}
+
+			if _, err := ParseCertificate(secret); err == nil {
+				t.Errorf("want an error")
+			}
+		})
+	}
+}
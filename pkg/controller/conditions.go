@@ -0,0 +1,183 @@
+package controller
+
+import (
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReady is True when every resource for the FunctionIngress
+	// has been reconciled and is able to serve traffic
+	ConditionReady = "Ready"
+	// ConditionIngressCreated is True when the Ingress record, or the objects
+	// rendered instead of it such as an HTTPRoute, exist and match the
+	// FunctionIngress spec
+	ConditionIngressCreated = "IngressCreated"
+	// ConditionCertificateReady is True when the TLS secret for the domain
+	// has been issued, it is omitted when TLS is disabled
+	ConditionCertificateReady = "CertificateReady"
+	// ConditionDegraded is True when the last reconciliation failed
+	ConditionDegraded = "Degraded"
+	// ConditionTLSSecretMissing is True when the existingSecret TLS mode is
+	// used, but the secret does not exist or holds no certificate. It is
+	// omitted for other TLS modes
+	ConditionTLSSecretMissing = "TLSSecretMissing"
+	// ConditionCertificateExpiringSoon is True when the certificate in the
+	// TLS secret expires within the operator's expiry threshold, it is
+	// omitted when there is no certificate
+	ConditionCertificateExpiringSoon = "CertificateExpiringSoon"
+	// ConditionFunctionAvailable is True when the Deployment and Service of
+	// every function of the FunctionIngress exist, it is Unknown when the
+	// namespace of a function is not watched
+	ConditionFunctionAvailable = "FunctionAvailable"
+	// ConditionGatewayBypassed is True while the requests of a
+	// FunctionIngress with bypassMode "auto" are sent straight to its
+	// functions, and False while they are sent through the gateway. It is
+	// omitted when bypassMode is not set
+	ConditionGatewayBypassed = "GatewayBypassed"
+)
+
+const (
+	// ReasonReconciled is used when all resources are in the desired state
+	ReasonReconciled = "Reconciled"
+	// ReasonIngressCreated is used when the Ingress was created
+	ReasonIngressCreated = "IngressCreated"
+	// ReasonIngressUpdated is used when the Ingress was updated to match the spec
+	ReasonIngressUpdated = "IngressUpdated"
+	// ReasonIngressAdopted is used when a pre-existing Ingress was adopted
+	ReasonIngressAdopted = "IngressAdopted"
+	// ReasonIngressSynced is used when an existing Ingress already matches the spec
+	ReasonIngressSynced = "IngressSynced"
+	// ReasonIngressPending is used while the Ingress has not been created yet
+	ReasonIngressPending = "IngressPending"
+	// ReasonCertificateIssued is used when the TLS secret contains a certificate
+	ReasonCertificateIssued = "CertificateIssued"
+	// ReasonCertificatePending is used while waiting for cert-manager to
+	// populate the TLS secret
+	ReasonCertificatePending = "CertificatePending"
+	// ReasonTLSSecretMissing is used when the secret of the existingSecret
+	// TLS mode does not exist or holds no certificate
+	ReasonTLSSecretMissing = "TLSSecretMissing"
+	// ReasonTLSSecretFound is used when the secret of the existingSecret TLS
+	// mode holds a certificate
+	ReasonTLSSecretFound = "TLSSecretFound"
+	// ReasonCertificateInvalid is used when the TLS secret holds a
+	// certificate which can not be parsed
+	ReasonCertificateInvalid = "CertificateInvalid"
+	// ReasonCertificateExpired is used when the certificate is past NotAfter
+	ReasonCertificateExpired = "CertificateExpired"
+	// ReasonCertificateHostMismatch is used when the SANs of the certificate
+	// do not cover every domain of the FunctionIngress
+	ReasonCertificateHostMismatch = "CertificateHostMismatch"
+	// ReasonCertificateExpiringSoon is used when the certificate expires
+	// within the expiry threshold
+	ReasonCertificateExpiringSoon = "CertificateExpiringSoon"
+	// ReasonCertificateValid is used when the certificate does not expire
+	// within the expiry threshold
+	ReasonCertificateValid = "CertificateValid"
+	// ReasonCertificateUnsupported is used when tls.certificate is set, but
+	// the cert-manager CRDs are not installed
+	ReasonCertificateUnsupported = "CertificateUnsupported"
+	// ReasonInvalidSpec is used when the FunctionIngress can not be rendered
+	ReasonInvalidSpec = "InvalidSpec"
+	// ReasonPathMatchUnsupported is used when the class of the
+	// FunctionIngress can not match its paths as pathMatch asks
+	ReasonPathMatchUnsupported = "PathMatchUnsupported"
+	// ReasonHTTPRouteCreated is used when the HTTPRoute was created
+	ReasonHTTPRouteCreated = "HTTPRouteCreated"
+	// ReasonHTTPRouteUpdated is used when the HTTPRoute was updated to match the spec
+	ReasonHTTPRouteUpdated = "HTTPRouteUpdated"
+	// ReasonHTTPRouteSynced is used when an existing HTTPRoute already matches the spec
+	ReasonHTTPRouteSynced = "HTTPRouteSynced"
+	// ReasonHTTPRouteUnsupported is used when the gateway-api IngressType is
+	// requested, but the HTTPRoute CRD is not installed
+	ReasonHTTPRouteUnsupported = "HTTPRouteUnsupported"
+	// ReasonIngressRouteCreated is used when the Traefik IngressRoute was created
+	ReasonIngressRouteCreated = "IngressRouteCreated"
+	// ReasonIngressRouteUpdated is used when the Traefik IngressRoute or its
+	// Middleware was updated to match the spec
+	ReasonIngressRouteUpdated = "IngressRouteUpdated"
+	// ReasonIngressRouteSynced is used when an existing IngressRoute and its
+	// Middleware already match the spec
+	ReasonIngressRouteSynced = "IngressRouteSynced"
+	// ReasonIngressRouteUnsupported is used when the traefik-crd IngressType
+	// is requested, but the Traefik CRDs are not installed
+	ReasonIngressRouteUnsupported = "IngressRouteUnsupported"
+	// ReasonCleanupFailed is used when an object tracked with the OwnerLabel
+	// could not be deleted along with the FunctionIngress
+	ReasonCleanupFailed = "CleanupFailed"
+	// ReasonCleanedUp is used when the tracked objects have been deleted and
+	// the Finalizer removed
+	ReasonCleanedUp = "CleanedUp"
+	// ReasonFunctionFound is used when every function has a Deployment and
+	// a Service
+	ReasonFunctionFound = "FunctionFound"
+	// ReasonFunctionNotFound is used when a function has no Deployment or
+	// no Service
+	ReasonFunctionNotFound = "FunctionNotFound"
+	// ReasonFunctionNamespaceNotWatched is used when the namespace of a
+	// function is not one of the operator's -function-namespaces
+	ReasonFunctionNamespaceNotWatched = "FunctionNamespaceNotWatched"
+	// ReasonFunctionReady is used when every function has ready replicas,
+	// so the gateway is bypassed
+	ReasonFunctionReady = "FunctionReady"
+	// ReasonFunctionScaledToZero is used when a function has no ready
+	// replicas, so requests are sent through the gateway which can wake it
+	ReasonFunctionScaledToZero = "FunctionScaledToZero"
+	// ReasonBypassPending is used when every function has ready replicas,
+	// but the gateway is kept until the bypass hysteresis has passed
+	ReasonBypassPending = "BypassPending"
+)
+
+// SetCondition adds or updates a condition on the FunctionIngress status,
+// the observedGeneration is taken from the object's metadata.
+func SetCondition(fni *faasv1.FunctionIngress, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&fni.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: fni.Generation,
+	})
+}
+
+// RemoveCondition removes a condition from the FunctionIngress status
+func RemoveCondition(fni *faasv1.FunctionIngress, conditionType string) {
+	meta.RemoveStatusCondition(&fni.Status.Conditions, conditionType)
+}
+
+// IsConditionTrue returns true when the condition is present and True
+func IsConditionTrue(fni *faasv1.FunctionIngress, conditionType string) bool {
+	return meta.IsStatusConditionTrue(fni.Status.Conditions, conditionType)
+}
+
+// SetReady derives the Ready condition from the other conditions on the
+// FunctionIngress, it is called after a successful sync so it also clears
+// the Degraded condition.
+func SetReady(fni *faasv1.FunctionIngress) {
+	SetCondition(fni, ConditionDegraded, metav1.ConditionFalse, ReasonReconciled, MessageResourceSynced)
+
+	if !IsConditionTrue(fni, ConditionIngressCreated) {
+		SetCondition(fni, ConditionReady, metav1.ConditionFalse, ReasonIngressPending, "Ingress has not been created")
+		return
+	}
+
+	if c := meta.FindStatusCondition(fni.Status.Conditions, ConditionCertificateReady); c != nil && c.Status != metav1.ConditionTrue {
+		SetCondition(fni, ConditionReady, metav1.ConditionFalse, c.Reason, c.Message)
+		return
+	}
+
+	if c := meta.FindStatusCondition(fni.Status.Conditions, ConditionFunctionAvailable); c != nil && c.Status == metav1.ConditionFalse {
+		SetCondition(fni, ConditionReady, metav1.ConditionFalse, c.Reason, c.Message)
+		return
+	}
+
+	SetCondition(fni, ConditionReady, metav1.ConditionTrue, ReasonReconciled, MessageResourceSynced)
+}
+
+// SetDegraded marks the FunctionIngress as not ready due to a failure
+func SetDegraded(fni *faasv1.FunctionIngress, reason, message string) {
+	SetCondition(fni, ConditionDegraded, metav1.ConditionTrue, reason, message)
+	SetCondition(fni, ConditionReady, metav1.ConditionFalse, reason, message)
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"time"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GatewayAPIClass is the IngressType used to create a Gateway API HTTPRoute
+// instead of an Ingress.
+const GatewayAPIClass = "gateway-api"
+
+// TraefikCRDClass is the IngressType used to create a Traefik IngressRoute
+// and Middleware instead of an Ingress.
+const TraefikCRDClass = "traefik-crd"
+
+// HTTPRouteResource is the Gateway API HTTPRoute, it is handled as
+// unstructured data, so that the operator does not depend on the Gateway API
+// module.
+var HTTPRouteResource = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "httproutes",
+}
+
+// IngressRouteResource and MiddlewareResource are Traefik's CRDs, they are
+// handled as unstructured data for the same reason as HTTPRouteResource.
+var (
+	IngressRouteResource = schema.GroupVersionResource{
+		Group:    "traefik.io",
+		Version:  "v1alpha1",
+		Resource: "ingressroutes",
+	}
+	MiddlewareResource = schema.GroupVersionResource{
+		Group:    "traefik.io",
+		Version:  "v1alpha1",
+		Resource: "middlewares",
+	}
+)
+
+// CertificateResource is cert-manager's Certificate, it is handled as
+// unstructured data for the same reason as HTTPRouteResource.
+var CertificateResource = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+// Config holds the operator level options which apply to every FunctionIngress
+type Config struct {
+	// HTTPRouteEnabled is true when the HTTPRouteResource is served by the
+	// cluster, as detected at start-up.
+	HTTPRouteEnabled bool
+
+	// TraefikCRDEnabled is true when the IngressRouteResource and
+	// MiddlewareResource are served by the cluster, as detected at start-up.
+	TraefikCRDEnabled bool
+
+	// CertificateEnabled is true when the CertificateResource is served by
+	// the cluster, as detected at start-up.
+	CertificateEnabled bool
+
+	// DefaultParentRef is the Gateway that an HTTPRoute attaches to when the
+	// FunctionIngress does not set a parentRef.
+	DefaultParentRef *faasv1.ParentReference
+
+	// Gateway holds the defaults for the gateway of every FunctionIngress,
+	// see ResolveGateway.
+	Gateway faasv1.GatewayReference
+
+	// ClusterDomain such as "cluster.local" is used to address a gateway in
+	// another namespace from an ExternalName Service.
+	ClusterDomain string
+
+	// Defaults are applied to every FunctionIngress, see SetDefaults.
+	Defaults Defaults
+
+	// Namespaces are watched by the operator, so it may have created objects
+	// in them. They are searched for objects with the OwnerLabel when a
+	// FunctionIngress is deleted, an empty namespace searches all of them.
+	// Only the namespace of the FunctionIngress is searched when unset.
+	Namespaces []string
+
+	// CertificateExpiryThreshold is how long before it expires that a
+	// certificate is reported as CertificateExpiringSoon, it defaults to
+	// DefaultCertificateExpiryThreshold.
+	CertificateExpiryThreshold time.Duration
+
+	// BypassHysteresis is how long a FunctionIngress with bypassMode
+	// "auto" keeps sending requests through the gateway after falling back
+	// to it, it defaults to DefaultBypassHysteresis.
+	BypassHysteresis time.Duration
+}
@@ -4,15 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	faasv2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
 	"github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/scheme"
 	faasscheme "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/scheme"
 	v1 "github.com/openfaas/ingress-operator/pkg/client/informers/externalversions/openfaas/v1"
 	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -31,18 +34,75 @@ const AgentName = "ingress-operator"
 const FaasIngressKind = "FunctionIngress"
 const OpenfaasWorkloadPort = 8080
 
+// SpecAnnotation holds the FunctionIngress that the Ingress was last
+// rendered from, serialised as JSON.
+const SpecAnnotation = "com.openfaas.spec"
+
+// AdoptAnnotation can be set to "true" on a FunctionIngress to allow the
+// operator to take ownership of a pre-existing Ingress with the same name
+// which has no controller.
+const AdoptAnnotation = "com.openfaas.adopt"
+
+// OwnerLabel is set to the UID of the FunctionIngress on every object that
+// the operator creates for it. It finds the objects which an owner
+// reference can not cover, such as those in another namespace, so that they
+// are deleted with the FunctionIngress.
+const OwnerLabel = "com.openfaas.ingress.owner-uid"
+
+// Finalizer keeps a deleted FunctionIngress until the objects found with
+// the OwnerLabel have been deleted.
+const Finalizer = "openfaas.com/ingress-cleanup"
+
 const (
 	// SuccessSynced is used as part of the Event 'reason' when a Function is synced
 	SuccessSynced = "Synced"
-	// ErrResourceExists is used as part of the Event 'reason' when a Function fails
-	// to sync due to a Deployment of the same name already existing.
+	// ErrResourceExists is used as part of the Event 'reason' when a FunctionIngress fails
+	// to sync due to an Ingress of the same name already existing.
 	ErrResourceExists = "ErrResourceExists"
 	// MessageResourceExists is the message used for Events when a resource
-	// fails to sync due to a Deployment already existing
+	// fails to sync due to an Ingress already existing
 	MessageResourceExists = "Resource %q already exists and is not managed by controller"
+	// ErrObjectCreate is formatted with the kind of an object rendered
+	// instead of an Ingress, such as an HTTPRoute, to give the Event and
+	// condition reason when it can not be created
+	ErrObjectCreate = "%sCreateFailed"
+	// MessageObjectCreate is the message used for Events when an object
+	// rendered instead of an Ingress can not be created
+	MessageObjectCreate = "Unable to create %s %q: %s"
+	// ErrObjectUpdate is formatted with the kind of an object rendered
+	// instead of an Ingress to give the Event and condition reason when it
+	// can not be updated
+	ErrObjectUpdate = "%sUpdateFailed"
+	// MessageObjectUpdate is the message used for Events when an object
+	// rendered instead of an Ingress can not be updated
+	MessageObjectUpdate = "Unable to update %s %q: %s"
+	// DriftCorrected is used as part of the Event 'reason' when an Ingress
+	// edited outside of the operator is restored
+	DriftCorrected = "DriftCorrected"
+	// MessageDriftCorrected is the message used for an Event fired when
+	// drifted fields of an Ingress are restored
+	MessageDriftCorrected = "Ingress %q was modified outside of the operator, restored: %s"
+	// SuccessAdopted is used as part of the Event 'reason' when an existing
+	// Ingress is adopted by a FunctionIngress
+	SuccessAdopted = "Adopted"
+	// MessageResourceAdopted is the message used for an Event fired when an
+	// existing Ingress is adopted
+	MessageResourceAdopted = "Resource %q adopted by FunctionIngress"
 	// MessageResourceSynced is the message used for an Event fired when a Function
 	// is synced successfully
 	MessageResourceSynced = "FunctionIngress synced successfully"
+	// ErrIngressCreate is used as part of the Event 'reason' and condition
+	// reason when the Ingress can not be created
+	ErrIngressCreate = "IngressCreateFailed"
+	// MessageIngressCreate is the message used for Events when the Ingress
+	// can not be created
+	MessageIngressCreate = "Unable to create Ingress %q: %s"
+	// ErrIngressUpdate is used as part of the Event 'reason' and condition
+	// reason when the Ingress can not be updated
+	ErrIngressUpdate = "IngressUpdateFailed"
+	// MessageIngressUpdate is the message used for Events when the Ingress
+	// can not be updated
+	MessageIngressUpdate = "Unable to update Ingress %q: %s"
 )
 
 // BaseController is the controller contains the common function ingress
@@ -51,6 +111,10 @@ type BaseController struct {
 	FunctionsLister listers.FunctionIngressLister
 	FunctionsSynced cache.InformerSynced
 
+	// CachesSynced are the informers for the secondary resources that
+	// must be synced before the workers are started.
+	CachesSynced []cache.InformerSynced
+
 	// Workqueue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
 	// means we can ensure we only process a fixed amount of resources at a
@@ -59,6 +123,9 @@ type BaseController struct {
 	Workqueue workqueue.RateLimitingInterface
 
 	SyncHandler func(ctx context.Context, key string) error
+
+	// Health is used by the liveness and readiness probes, it is optional.
+	Health *Health
 }
 
 func (c BaseController) Run(threadiness int, stopCh <-chan struct{}) error {
@@ -70,7 +137,8 @@ func (c BaseController) Run(threadiness int, stopCh <-chan struct{}) error {
 	// Start the informer factories to begin populating the informer caches
 	// Wait for the caches to be synced before starting workers
 	klog.Info("Waiting for informer caches to sync")
-	if ok := cache.WaitForCacheSync(stopCh, c.FunctionsSynced); !ok {
+	synced := append([]cache.InformerSynced{c.FunctionsSynced}, c.CachesSynced...)
+	if ok := cache.WaitForCacheSync(stopCh, synced...); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
@@ -80,6 +148,11 @@ func (c BaseController) Run(threadiness int, stopCh <-chan struct{}) error {
 		go wait.Until(c.runWorker(ctx), time.Second, stopCh)
 	}
 
+	if c.Health != nil {
+		c.Health.started()
+		defer c.Health.stopped()
+	}
+
 	klog.Info("Started workers")
 	<-stopCh
 	klog.Info("Shutting down workers")
@@ -87,6 +160,24 @@ func (c BaseController) Run(threadiness int, stopCh <-chan struct{}) error {
 	return nil
 }
 
+// Readyz returns an error until the caches have synced and the workers
+// have been started.
+func (c BaseController) Readyz() error {
+	if c.Health == nil {
+		return fmt.Errorf("health is not tracked")
+	}
+	return c.Health.Ready()
+}
+
+// Healthz returns an error when the workers have stopped draining the
+// workqueue for longer than stallTimeout.
+func (c BaseController) Healthz(stallTimeout time.Duration) error {
+	if c.Health == nil {
+		return nil
+	}
+	return c.Health.Live(c.Workqueue.Len(), stallTimeout)
+}
+
 // runWorker is a long-running function that will continually call the
 // processNextWorkItem function in order to read and process a message on the workqueue.
 func (c BaseController) runWorker(ctx context.Context) func() {
@@ -105,6 +196,11 @@ func (c BaseController) processNextWorkItem(ctx context.Context) bool {
 		return false
 	}
 
+	if c.Health != nil {
+		c.Health.observe()
+		defer c.Health.observe()
+	}
+
 	err := func(obj interface{}) error {
 		defer c.Workqueue.Done(obj)
 		var key string
@@ -114,9 +210,15 @@ func (c BaseController) processNextWorkItem(ctx context.Context) bool {
 			runtime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
 			return nil
 		}
+		start := time.Now()
 		if err := c.SyncHandler(ctx, key); err != nil {
-			return fmt.Errorf("error syncing '%s': %s", key, err.Error())
+			metrics.ObserveReconcile(metrics.ResultError, time.Since(start))
+			// Put the item back on the workqueue to handle any transient errors,
+			// the rate limiter backs off on each failure.
+			c.Workqueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
 		}
+		metrics.ObserveReconcile(metrics.ResultSuccess, time.Since(start))
 		c.Workqueue.Forget(obj)
 		return nil
 	}(obj)
@@ -201,8 +303,11 @@ func (c BaseController) SetupEventHandlers(
 			}
 			diffSpec := cmp.Diff(oldFn.Spec, newFn.Spec)
 			diffAnnotations := cmp.Diff(oldFn.ObjectMeta.Annotations, newFn.ObjectMeta.Annotations)
+			// Deleting a FunctionIngress with a finalizer only sets its
+			// deletionTimestamp, which must be handled to remove the finalizer
+			deleted := oldFn.DeletionTimestamp == nil && newFn.DeletionTimestamp != nil
 
-			if diffSpec != "" || diffAnnotations != "" {
+			if diffSpec != "" || diffAnnotations != "" || deleted {
 				c.EnqueueFunction(new)
 			}
 		},
@@ -248,48 +353,114 @@ func GetIssuerKind(issuerType string) string {
 	}
 }
 
-func MakeAnnotations(fni *faasv1.FunctionIngress) map[string]string {
-	class := GetClass(fni.Spec.IngressType)
+// MakeAnnotations returns the annotations of the Ingress, the rewrites are
+// written for the dialect of its IngressClass, see ResolveIngressClass.
+func MakeAnnotations(fni *faasv1.FunctionIngress, dialect string) map[string]string {
 	specJSON, _ := json.Marshal(fni)
 	annotations := make(map[string]string)
 
-	annotations["kubernetes.io/ingress.class"] = class
-	annotations["com.openfaas.spec"] = string(specJSON)
+	annotations[SpecAnnotation] = string(specJSON)
 
-	fnNamespace := ""
-	if fni.Spec.FunctionNamespace != "" {
-		fnNamespace = fmt.Sprintf(".%s", fni.Spec.FunctionNamespace)
+	paths := MakePaths(fni, dialect)
+	if dialect == "nginx" && paths[0].Type == faasv1.PathMatchRegex {
+		annotations["nginx.ingress.kubernetes.io/use-regex"] = "true"
 	}
 
-	if !fni.Spec.BypassGateway {
-		switch class {
-		case "nginx":
-			annotations["nginx.ingress.kubernetes.io/rewrite-target"] = "/function/" + fni.Spec.Function + fnNamespace + "/$1"
-			break
-		case "skipper":
-			annotations["zalando.org/skipper-filter"] = `setPath("/function/` + fni.Spec.Function + fnNamespace + `")`
-			break
-		case "traefik":
-			annotations["traefik.ingress.kubernetes.io/rewrite-target"] = "/function/" + fni.Spec.Function + fnNamespace
-			annotations["traefik.ingress.kubernetes.io/rule-type"] = `PathPrefix`
-			break
+	if !fni.Spec.BypassesGateway() {
+		if functionPath, ok := SingleRewrite(MakeRoutes(fni)); ok {
+			switch dialect {
+			case "nginx":
+				annotations["nginx.ingress.kubernetes.io/rewrite-target"] = paths[0].Replacement
+			case "skipper":
+				// A regular expression keeps the setPath filter used before
+				// pathMatch existed
+				if paths[0].Type == faasv1.PathMatchRegex {
+					annotations["zalando.org/skipper-filter"] = `setPath("` + functionPath + `")`
+				} else {
+					annotations["zalando.org/skipper-filter"] = skipperRewrites(paths)
+				}
+			case "traefik":
+				annotations["traefik.ingress.kubernetes.io/rewrite-target"] = functionPath
+				annotations["traefik.ingress.kubernetes.io/rule-type"] = `PathPrefix`
+				if paths[0].Type == faasv1.PathMatchExact {
+					annotations["traefik.ingress.kubernetes.io/rule-type"] = `Path`
+				}
+			}
+		} else {
+			// The rewrite annotations apply to every path of the Ingress, so
+			// each route is rewritten by a regex on its own path instead
+			switch dialect {
+			case "nginx":
+				annotations["nginx.ingress.kubernetes.io/configuration-snippet"] = nginxRewrites(paths)
+			case "skipper":
+				annotations["zalando.org/skipper-filter"] = skipperRewrites(paths)
+			}
 		}
 	}
 
-	if fni.Spec.UseTLS() {
+	// The issuer annotation has cert-manager's ingress-shim create a
+	// Certificate, which is not wanted when the operator creates one
+	if fni.Spec.TLSMode() == faasv1.TLSModeCertManager && !fni.Spec.ManagedCertificate() {
 		issuerType := GetIssuerKind(fni.Spec.TLS.IssuerRef.Kind)
 		annotations[issuerType] = fni.Spec.TLS.IssuerRef.Name
 	}
 
 	// Set annotations with overrides from FunctionIngress
-	// annotations
+	// annotations, other than the spec kept for conversion to v2
 	for k, v := range fni.ObjectMeta.Annotations {
+		if k == faasv2.ConversionDataAnnotation {
+			continue
+		}
 		annotations[k] = v
 	}
 
 	return annotations
 }
 
+// FunctionPath is the path of the function on the OpenFaaS gateway, such as
+// "/function/nodeinfo" or "/function/nodeinfo.staging-fn"
+func FunctionPath(fni *faasv1.FunctionIngress) string {
+	return functionPath(fni.Spec.Function, fni.Spec.FunctionNamespace)
+}
+
+func functionPath(function, namespace string) string {
+	fnNamespace := ""
+	if namespace != "" {
+		fnNamespace = fmt.Sprintf(".%s", namespace)
+	}
+
+	return "/function/" + function + fnNamespace
+}
+
+// nginxRewrites returns a rewrite directive for each route, the snippet is
+// added to the location of every path so only the first rewrite to match the
+// request is applied. The longest paths come first, so that a route such as
+// "/(.*)" does not rewrite requests for more specific routes. ingress-nginx
+// matches paths case-insensitively when use-regex is set, so the rewrites do
+// too.
+func nginxRewrites(paths []RoutePath) string {
+	sorted := append([]RoutePath{}, paths...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Regex) > len(sorted[j].Regex)
+	})
+
+	rewrites := []string{}
+	for _, path := range sorted {
+		rewrites = append(rewrites, fmt.Sprintf(`rewrite "(?i)^%s" %s break;`, path.Regex, path.Replacement))
+	}
+	return strings.Join(rewrites, "\n") + "\n"
+}
+
+// skipperRewrites chains a modPath filter for each route, only the filter
+// matching the path of the request changes it.
+func skipperRewrites(paths []RoutePath) string {
+	filters := []string{}
+	for _, path := range paths {
+		filters = append(filters, fmt.Sprintf(`modPath("^%s", "%s")`, path.Regex, path.Replacement))
+	}
+	return strings.Join(filters, " -> ")
+}
+
 func MakeOwnerRef(fni *faasv1.FunctionIngress) []metav1.OwnerReference {
 	ref := []metav1.OwnerReference{
 		*metav1.NewControllerRef(fni, schema.GroupVersionKind{
@@ -301,6 +472,30 @@ func MakeOwnerRef(fni *faasv1.FunctionIngress) []metav1.OwnerReference {
 	return ref
 }
 
+// MakeOwnerLabels returns the OwnerLabel for the objects created for the
+// FunctionIngress, so that they can be found when it is deleted.
+func MakeOwnerLabels(fni *faasv1.FunctionIngress) map[string]string {
+	return map[string]string{
+		OwnerLabel: string(fni.UID),
+	}
+}
+
+// HasFinalizer returns true when the FunctionIngress holds the Finalizer
+func HasFinalizer(fni *faasv1.FunctionIngress) bool {
+	for _, finalizer := range fni.Finalizers {
+		if finalizer == Finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAdopt returns true when the FunctionIngress opts into adopting an
+// existing Ingress via the AdoptAnnotation.
+func CanAdopt(fni *faasv1.FunctionIngress) bool {
+	return fni.ObjectMeta.Annotations[AdoptAnnotation] == "true"
+}
+
 func CheckCustomResourceType(obj interface{}) (faasv1.FunctionIngress, bool) {
 	var fn *faasv1.FunctionIngress
 	var ok bool
@@ -1,11 +1,15 @@
 package controller
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
 
 	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	faasv2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
 )
 
 func TestMakeAnnotations(t *testing.T) {
@@ -26,10 +30,26 @@ func TestMakeAnnotations(t *testing.T) {
 				},
 			},
 			expected: map[string]string{
-				"test":                        "test",
-				"example":                     "example",
-				"kubernetes.io/ingress.class": "nginx",
+				"test":    "test",
+				"example": "example",
+				"nginx.ingress.kubernetes.io/rewrite-target": "/function//$1",
 			},
+			excluded: []string{"kubernetes.io/ingress.class"},
+		},
+		{
+			name: "the spec kept for conversion to v2 is not copied",
+			ingress: faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"test":                          "test",
+						faasv2.ConversionDataAnnotation: `{"backend":{"function":"nodeinfo"}}`,
+					},
+				},
+			},
+			expected: map[string]string{
+				"test": "test",
+			},
+			excluded: []string{faasv2.ConversionDataAnnotation},
 		},
 		{
 			name: "can override ingress class value",
@@ -76,6 +96,7 @@ func TestMakeAnnotations(t *testing.T) {
 			},
 			expected: map[string]string{
 				"nginx.ingress.kubernetes.io/rewrite-target": "/function//$1",
+				"nginx.ingress.kubernetes.io/use-regex":      "true",
 			},
 		},
 		{
@@ -176,6 +197,134 @@ func TestMakeAnnotations(t *testing.T) {
 				"zalando.org/skipper-filter":  `setPath("/function/nodeinfo.staging-fn")`,
 			},
 		},
+		{
+			name: "routes to the same function keep the rewrite-target",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType: "nginx",
+					Function:    "nodeinfo",
+					Routes: []faasv1.FunctionRoute{
+						{Path: "/v1/(.*)"},
+						{Path: "/v2/(.*)"},
+					},
+				},
+			},
+			expected: map[string]string{
+				"nginx.ingress.kubernetes.io/rewrite-target": "/function/nodeinfo/$1",
+			},
+			excluded: []string{"nginx.ingress.kubernetes.io/configuration-snippet"},
+		},
+		{
+			name: "routes to several functions are rewritten per path with nginx",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType: "nginx",
+					Routes: []faasv1.FunctionRoute{
+						{Path: "/(.*)", Function: "www"},
+						{Path: "/v1/users/(.*)", Function: "users"},
+						{Path: "/v1/orders/(.*)", Function: "orders", FunctionNamespace: "staging-fn"},
+						{Path: "/v1/legacy/(.*)", Function: "users", Rewrite: "/function/users/legacy"},
+					},
+				},
+			},
+			expected: map[string]string{
+				"nginx.ingress.kubernetes.io/use-regex": "true",
+				"nginx.ingress.kubernetes.io/configuration-snippet": `rewrite "(?i)^/v1/orders/(.*)" /function/orders.staging-fn/$1 break;` + "\n" +
+					`rewrite "(?i)^/v1/legacy/(.*)" /function/users/legacy/$1 break;` + "\n" +
+					`rewrite "(?i)^/v1/users/(.*)" /function/users/$1 break;` + "\n" +
+					`rewrite "(?i)^/(.*)" /function/www/$1 break;` + "\n",
+			},
+			excluded: []string{"nginx.ingress.kubernetes.io/rewrite-target"},
+		},
+		{
+			name: "routes to several functions are rewritten per path with skipper",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType: "skipper",
+					Routes: []faasv1.FunctionRoute{
+						{Path: "/v1/users/(.*)", Function: "users"},
+						{Path: "/v1/orders/(.*)", Function: "orders"},
+					},
+				},
+			},
+			expected: map[string]string{
+				"zalando.org/skipper-filter": `modPath("^/v1/users/(.*)", "/function/users/$1") -> modPath("^/v1/orders/(.*)", "/function/orders/$1")`,
+			},
+		},
+		{
+			name: "prefix path is rewritten with a regex for nginx",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType: "nginx",
+					Function:    "nodeinfo",
+					Path:        "/v1/nodeinfo",
+					PathMatch:   faasv1.PathMatchPrefix,
+				},
+			},
+			expected: map[string]string{
+				"nginx.ingress.kubernetes.io/use-regex":      "true",
+				"nginx.ingress.kubernetes.io/rewrite-target": "/function/nodeinfo/$1",
+			},
+		},
+		{
+			name: "exact paths are rewritten without the rest of the path for nginx",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType: "nginx",
+					PathMatch:   faasv1.PathMatchExact,
+					Routes: []faasv1.FunctionRoute{
+						{Path: "/healthz", Function: "health"},
+						{Path: "/", Function: "www"},
+					},
+				},
+			},
+			expected: map[string]string{
+				"nginx.ingress.kubernetes.io/use-regex": "true",
+				"nginx.ingress.kubernetes.io/configuration-snippet": `rewrite "(?i)^/healthz$" /function/health break;` + "\n" +
+					`rewrite "(?i)^/$" /function/www break;` + "\n",
+			},
+		},
+		{
+			name: "bypass with a prefix path does not use a regex for nginx",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType:   "nginx",
+					Function:      "nodeinfo",
+					BypassGateway: true,
+					PathMatch:     faasv1.PathMatchPrefix,
+				},
+			},
+			excluded: []string{"nginx.ingress.kubernetes.io/use-regex", "nginx.ingress.kubernetes.io/rewrite-target"},
+		},
+		{
+			name: "exact path uses the Path rule-type for traefik",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType: "traefik",
+					Function:    "nodeinfo",
+					Path:        "/healthz",
+					PathMatch:   faasv1.PathMatchExact,
+				},
+			},
+			expected: map[string]string{
+				"traefik.ingress.kubernetes.io/rewrite-target": "/function/nodeinfo",
+				"traefik.ingress.kubernetes.io/rule-type":      "Path",
+			},
+		},
+		{
+			name: "prefix path keeps the rest of the path for skipper",
+			ingress: faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					IngressType: "skipper",
+					Function:    "nodeinfo",
+					Path:        "/v1.0/nodeinfo",
+					PathMatch:   faasv1.PathMatchPrefix,
+				},
+			},
+			expected: map[string]string{
+				"zalando.org/skipper-filter": `modPath("^/v1\.0/nodeinfo(?:/|$)(.*)", "/function/nodeinfo/$1")`,
+			},
+		},
 		{
 			name: "creates tls issuer annotation",
 			ingress: faasv1.FunctionIngress{
@@ -231,7 +380,7 @@ func TestMakeAnnotations(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := MakeAnnotations(&tc.ingress)
+			result := MakeAnnotations(&tc.ingress, ResolveIngressClass(&tc.ingress, nil).Dialect)
 			for key, value := range tc.expected {
 				found, ok := result[key]
 				if !ok {
@@ -252,3 +401,45 @@ func TestMakeAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessNextWorkItem_RequeuesOnError(t *testing.T) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+	defer queue.ShutDown()
+
+	c := BaseController{
+		Workqueue: queue,
+		SyncHandler: func(ctx context.Context, key string) error {
+			return fmt.Errorf("unable to create ingress")
+		},
+	}
+
+	queue.Add("openfaas/nodeinfo")
+	if !c.processNextWorkItem(context.Background()) {
+		t.Fatalf("want worker to continue after an error")
+	}
+
+	if got := queue.NumRequeues("openfaas/nodeinfo"); got != 1 {
+		t.Fatalf("want key to be requeued once with backoff, got %d", got)
+	}
+}
+
+func TestProcessNextWorkItem_ForgetsOnSuccess(t *testing.T) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+	defer queue.ShutDown()
+
+	c := BaseController{
+		Workqueue: queue,
+		SyncHandler: func(ctx context.Context, key string) error {
+			return nil
+		},
+	}
+
+	queue.AddRateLimited("openfaas/nodeinfo")
+	if !c.processNextWorkItem(context.Background()) {
+		t.Fatalf("want worker to continue")
+	}
+
+	if got := queue.NumRequeues("openfaas/nodeinfo"); got != 0 {
+		t.Fatalf("want key to be forgotten after a successful sync, got %d requeues", got)
+	}
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+)
+
+// Defaults are the operator level defaults of a FunctionIngress, set with
+// the -default-* flags.
+type Defaults struct {
+	// IngressClassName is used when the FunctionIngress sets neither
+	// ingressClassName nor ingressType, before the cluster's default
+	// IngressClass
+	IngressClassName string
+
+	// FunctionNamespace such as "openfaas-fn" is used when the
+	// FunctionIngress does not set functionNamespace and does not bypass
+	// the gateway
+	FunctionNamespace string
+
+	// IssuerRef is used for the certManager TLS mode when the
+	// FunctionIngress does not name an issuer
+	IssuerRef faasv1.ObjectReference
+}
+
+// SetDefaults writes the values into the spec which would otherwise be
+// inferred when the FunctionIngress is rendered. It is used by the mutating
+// webhook, so that the stored object shows what is rendered, and by the
+// controller for FunctionIngresses stored without the defaults.
+func SetDefaults(fni *faasv1.FunctionIngress, defaults Defaults, ingressClasses networkinglisters.IngressClassLister) {
+	spec := &fni.Spec
+
+	class := GetClass(spec.IngressType)
+	if len(spec.IngressClassName) == 0 && class != GatewayAPIClass && class != TraefikCRDClass {
+		if len(spec.IngressType) == 0 && len(defaults.IngressClassName) > 0 {
+			spec.IngressClassName = defaults.IngressClassName
+		} else {
+			spec.IngressClassName = ResolveIngressClass(fni, ingressClasses).Name
+		}
+	}
+
+	if len(spec.FunctionNamespace) == 0 {
+		spec.FunctionNamespace = defaultFunctionNamespace(fni, defaults)
+	}
+
+	// The path of "auto" depends on whether the gateway is bypassed, so it
+	// is left empty
+	if len(spec.Routes) == 0 && len(spec.Path) == 0 && spec.BypassMode != faasv1.BypassModeAuto {
+		spec.Path = MakeRoutes(fni)[0].Path
+	}
+
+	if spec.TLS != nil {
+		spec.TLS.Mode = spec.TLSMode()
+
+		if spec.TLS.Mode == faasv1.TLSModeCertManager {
+			if len(spec.TLS.IssuerRef.Name) == 0 {
+				spec.TLS.IssuerRef = defaults.IssuerRef
+			}
+			if len(spec.TLS.IssuerRef.Kind) == 0 {
+				spec.TLS.IssuerRef.Kind = "Issuer"
+			}
+		}
+	}
+}
+
+// ClearDerivedDefaults empties the fields of an updated FunctionIngress
+// which SetDefaults derived from a field that the update changed, so that
+// they are derived again rather than kept from the old object. A derived
+// field which the update also changed is kept as it was set.
+func ClearDerivedDefaults(fni, old *faasv1.FunctionIngress) {
+	spec, oldSpec := &fni.Spec, &old.Spec
+
+	if spec.IngressType != oldSpec.IngressType && spec.IngressClassName == oldSpec.IngressClassName {
+		spec.IngressClassName = ""
+	}
+
+	bypassChanged := spec.BypassGateway != oldSpec.BypassGateway || spec.BypassMode != oldSpec.BypassMode
+	if bypassChanged && spec.FunctionNamespace == oldSpec.FunctionNamespace {
+		spec.FunctionNamespace = ""
+	}
+
+	if (bypassChanged || spec.PathMatch != oldSpec.PathMatch) &&
+		spec.Path == oldSpec.Path {
+		spec.Path = ""
+	}
+
+	if spec.TLS != nil && oldSpec.TLS != nil &&
+		spec.TLS.Enabled != oldSpec.TLS.Enabled && spec.TLS.Mode == oldSpec.TLS.Mode {
+		spec.TLS.Mode = ""
+	}
+}
+
+// defaultFunctionNamespace is the functionNamespace of a FunctionIngress
+// which does not set one. A FunctionIngress which always bypasses the
+// gateway is usually created alongside its functions.
+func defaultFunctionNamespace(fni *faasv1.FunctionIngress, defaults Defaults) string {
+	if fni.Spec.BypassesGateway() {
+		return fni.Namespace
+	}
+	return defaults.FunctionNamespace
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+func TestSetDefaults(t *testing.T) {
+	defaults := Defaults{
+		IngressClassName:  "nginx-internal",
+		FunctionNamespace: "openfaas-fn",
+		IssuerRef:         faasv1.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+	}
+
+	cases := []struct {
+		name     string
+		defaults Defaults
+		spec     faasv1.FunctionIngressSpec
+		want     faasv1.FunctionIngressSpec
+	}{
+		{
+			name: "without operator defaults",
+			spec: faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo"},
+			want: faasv1.FunctionIngressSpec{
+				Domain:           "nodeinfo.example.com",
+				Function:         "nodeinfo",
+				IngressClassName: "nginx",
+				Path:             "/(.*)",
+			},
+		},
+		{
+			name:     "operator defaults",
+			defaults: defaults,
+			spec: faasv1.FunctionIngressSpec{
+				Domain:   "nodeinfo.example.com",
+				Function: "nodeinfo",
+				TLS:      &faasv1.FunctionIngressTLS{Enabled: true},
+			},
+			want: faasv1.FunctionIngressSpec{
+				Domain:            "nodeinfo.example.com",
+				Function:          "nodeinfo",
+				IngressClassName:  "nginx-internal",
+				FunctionNamespace: "openfaas-fn",
+				Path:              "/(.*)",
+				TLS: &faasv1.FunctionIngressTLS{
+					Enabled:   true,
+					Mode:      faasv1.TLSModeCertManager,
+					IssuerRef: faasv1.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+				},
+			},
+		},
+		{
+			name:     "values in the spec are kept",
+			defaults: defaults,
+			spec: faasv1.FunctionIngressSpec{
+				Domain:            "nodeinfo.example.com",
+				Function:          "nodeinfo",
+				IngressType:       "traefik",
+				FunctionNamespace: "staging-fn",
+				Path:              "/v1/(.*)",
+				TLS: &faasv1.FunctionIngressTLS{
+					Enabled:   true,
+					IssuerRef: faasv1.ObjectReference{Name: "letsencrypt-staging"},
+				},
+			},
+			want: faasv1.FunctionIngressSpec{
+				Domain:            "nodeinfo.example.com",
+				Function:          "nodeinfo",
+				IngressType:       "traefik",
+				IngressClassName:  "traefik",
+				FunctionNamespace: "staging-fn",
+				Path:              "/v1/(.*)",
+				TLS: &faasv1.FunctionIngressTLS{
+					Enabled:   true,
+					Mode:      faasv1.TLSModeCertManager,
+					IssuerRef: faasv1.ObjectReference{Name: "letsencrypt-staging", Kind: "Issuer"},
+				},
+			},
+		},
+		{
+			name:     "bypass uses the namespace of the FunctionIngress",
+			defaults: defaults,
+			spec:     faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", BypassGateway: true},
+			want: faasv1.FunctionIngressSpec{
+				Domain:            "nodeinfo.example.com",
+				Function:          "nodeinfo",
+				BypassGateway:     true,
+				IngressClassName:  "nginx-internal",
+				FunctionNamespace: "openfaas",
+				Path:              "/",
+			},
+		},
+		{
+			name:     "auto bypass uses the gateway's namespace and leaves the path empty",
+			defaults: defaults,
+			spec:     faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", BypassMode: faasv1.BypassModeAuto},
+			want: faasv1.FunctionIngressSpec{
+				Domain:            "nodeinfo.example.com",
+				Function:          "nodeinfo",
+				BypassMode:        faasv1.BypassModeAuto,
+				IngressClassName:  "nginx-internal",
+				FunctionNamespace: "openfaas-fn",
+			},
+		},
+		{
+			name:     "gateway-api has no IngressClass and routes have no path",
+			defaults: defaults,
+			spec: faasv1.FunctionIngressSpec{
+				Domain:      "nodeinfo.example.com",
+				IngressType: GatewayAPIClass,
+				Routes:      []faasv1.FunctionRoute{{Path: "/v1/(.*)", Function: "v1"}},
+			},
+			want: faasv1.FunctionIngressSpec{
+				Domain:            "nodeinfo.example.com",
+				IngressType:       GatewayAPIClass,
+				FunctionNamespace: "openfaas-fn",
+				Routes:            []faasv1.FunctionRoute{{Path: "/v1/(.*)", Function: "v1"}},
+			},
+		},
+		{
+			name:     "existing secret has no issuer",
+			defaults: defaults,
+			spec: faasv1.FunctionIngressSpec{
+				Domain:   "nodeinfo.example.com",
+				Function: "nodeinfo",
+				TLS:      &faasv1.FunctionIngressTLS{Mode: faasv1.TLSModeExistingSecret},
+			},
+			want: faasv1.FunctionIngressSpec{
+				Domain:            "nodeinfo.example.com",
+				Function:          "nodeinfo",
+				IngressClassName:  "nginx-internal",
+				FunctionNamespace: "openfaas-fn",
+				Path:              "/(.*)",
+				TLS:               &faasv1.FunctionIngressTLS{Mode: faasv1.TLSModeExistingSecret},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+				Spec:       tc.spec,
+			}
+
+			SetDefaults(fni, tc.defaults, nil)
+			if diff := cmp.Diff(tc.want, fni.Spec); diff != "" {
+				t.Errorf("unexpected spec (-want +got):\n%s", diff)
+			}
+
+			// Defaulting twice must not change the spec again
+			again := fni.DeepCopy()
+			SetDefaults(again, tc.defaults, nil)
+			if diff := cmp.Diff(fni.Spec, again.Spec); diff != "" {
+				t.Errorf("want defaults to be idempotent (-first +second):\n%s", diff)
+			}
+		})
+	}
+}
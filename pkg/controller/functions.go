@@ -0,0 +1,61 @@
+package controller
+
+import (
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+// DefaultFunctionNamespace is where the OpenFaaS gateway deploys functions,
+// it is used for a route which does not set a functionNamespace.
+const DefaultFunctionNamespace = "openfaas-fn"
+
+// FunctionRef is a function served by a FunctionIngress, and the namespace
+// of its Deployment and Service.
+type FunctionRef struct {
+	Name      string
+	Namespace string
+}
+
+// String returns the function as it is addressed on the gateway, such as
+// "nodeinfo.openfaas-fn"
+func (f FunctionRef) String() string {
+	return f.Name + "." + f.Namespace
+}
+
+// Functions returns each function served by the routes of the FunctionIngress
+// once, in the order of the routes. Routes without a function are skipped.
+// The defaults resolve the namespace of a FunctionIngress which was stored
+// without them, as SetDefaults would.
+func Functions(fni *faasv1.FunctionIngress, defaults Defaults) []FunctionRef {
+	functions := []FunctionRef{}
+	seen := map[FunctionRef]bool{}
+	for _, route := range MakeRoutes(fni) {
+		if len(route.Function) == 0 {
+			continue
+		}
+
+		ref := FunctionRef{Name: route.Function, Namespace: route.FunctionNamespace}
+		if len(ref.Namespace) == 0 {
+			ref.Namespace = defaultFunctionNamespace(fni, defaults)
+		}
+		if len(ref.Namespace) == 0 {
+			ref.Namespace = DefaultFunctionNamespace
+		}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		functions = append(functions, ref)
+	}
+	return functions
+}
+
+// ServesFunction is true when a route of the FunctionIngress is sent to the
+// function.
+func ServesFunction(fni *faasv1.FunctionIngress, defaults Defaults, function FunctionRef) bool {
+	for _, ref := range Functions(fni, defaults) {
+		if ref == function {
+			return true
+		}
+	}
+	return false
+}
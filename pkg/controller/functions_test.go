@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+func TestFunctions(t *testing.T) {
+	cases := []struct {
+		name     string
+		defaults Defaults
+		spec     faasv1.FunctionIngressSpec
+		want     []FunctionRef
+	}{
+		{
+			name: "function of the spec",
+			spec: faasv1.FunctionIngressSpec{Function: "nodeinfo", FunctionNamespace: "staging-fn"},
+			want: []FunctionRef{{Name: "nodeinfo", Namespace: "staging-fn"}},
+		},
+		{
+			name: "the gateway's namespace without a functionNamespace",
+			spec: faasv1.FunctionIngressSpec{Function: "nodeinfo"},
+			want: []FunctionRef{{Name: "nodeinfo", Namespace: DefaultFunctionNamespace}},
+		},
+		{
+			name:     "operator default namespace",
+			defaults: Defaults{FunctionNamespace: "team-a-fn"},
+			spec:     faasv1.FunctionIngressSpec{Function: "nodeinfo"},
+			want:     []FunctionRef{{Name: "nodeinfo", Namespace: "team-a-fn"}},
+		},
+		{
+			name:     "bypass uses the namespace of the FunctionIngress",
+			defaults: Defaults{FunctionNamespace: "team-a-fn"},
+			spec:     faasv1.FunctionIngressSpec{Function: "nodeinfo", BypassGateway: true},
+			want:     []FunctionRef{{Name: "nodeinfo", Namespace: "openfaas"}},
+		},
+		{
+			name: "each function of the routes once",
+			spec: faasv1.FunctionIngressSpec{
+				Function:          "nodeinfo",
+				FunctionNamespace: "openfaas-fn",
+				Routes: []faasv1.FunctionRoute{
+					{Path: "/v1/users/(.*)", Function: "users"},
+					{Path: "/v2/users/(.*)", Function: "users"},
+					{Path: "/v1/orders/(.*)", Function: "orders", FunctionNamespace: "staging-fn"},
+					{Path: "/v1/info/(.*)"},
+				},
+			},
+			want: []FunctionRef{
+				{Name: "users", Namespace: "openfaas-fn"},
+				{Name: "orders", Namespace: "staging-fn"},
+				{Name: "nodeinfo", Namespace: "openfaas-fn"},
+			},
+		},
+		{
+			name: "routes without a function are skipped",
+			spec: faasv1.FunctionIngressSpec{
+				Routes: []faasv1.FunctionRoute{{Path: "/v1/(.*)"}},
+			},
+			want: []FunctionRef{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+				Spec:       tc.spec,
+			}
+
+			got := Functions(fni, tc.defaults)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServesFunction(t *testing.T) {
+	fni := &faasv1.FunctionIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+		Spec:       faasv1.FunctionIngressSpec{Function: "nodeinfo"},
+	}
+
+	if !ServesFunction(fni, Defaults{}, FunctionRef{Name: "nodeinfo", Namespace: "openfaas-fn"}) {
+		t.Errorf("want nodeinfo.openfaas-fn to be served")
+	}
+	if ServesFunction(fni, Defaults{}, FunctionRef{Name: "nodeinfo", Namespace: "staging-fn"}) {
+		t.Errorf("want nodeinfo.staging-fn not to be served")
+	}
+	if ServesFunction(fni, Defaults{}, FunctionRef{Name: "figlet", Namespace: "openfaas-fn"}) {
+		t.Errorf("want figlet.openfaas-fn not to be served")
+	}
+}
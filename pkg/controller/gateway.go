@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"fmt"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+// DefaultGatewayName and DefaultGatewayPort are used when neither the
+// operator nor the FunctionIngress set the gateway.
+const (
+	DefaultGatewayName = "gateway"
+	DefaultGatewayPort = 8080
+)
+
+// Gateway is the Service of the OpenFaaS gateway that a FunctionIngress
+// sends requests to.
+type Gateway struct {
+	Name      string
+	Namespace string
+	Port      int32
+
+	// Service is the name of the backend Service in the namespace of the
+	// FunctionIngress. It is the gateway itself, or an ExternalName Service
+	// when the gateway is in another namespace.
+	Service string
+
+	// crossNamespace is set by ResolveGateway when the gateway is in another
+	// namespace to the FunctionIngress
+	crossNamespace bool
+}
+
+// CrossNamespace is true when an ExternalName Service is needed to reach
+// the gateway.
+func (g Gateway) CrossNamespace() bool {
+	return g.crossNamespace
+}
+
+// ResolveGateway applies the defaults to the gateway of the FunctionIngress,
+// each field is taken from the spec, then the operator's defaults, then
+// DefaultGatewayName, the namespace of the FunctionIngress and
+// DefaultGatewayPort.
+func ResolveGateway(fni *faasv1.FunctionIngress, defaults faasv1.GatewayReference) Gateway {
+	gateway := Gateway{
+		Name:      defaults.Name,
+		Namespace: defaults.Namespace,
+		Port:      defaults.Port,
+	}
+
+	if ref := fni.Spec.Gateway; ref != nil {
+		if len(ref.Name) > 0 {
+			gateway.Name = ref.Name
+		}
+		if len(ref.Namespace) > 0 {
+			gateway.Namespace = ref.Namespace
+		}
+		if ref.Port > 0 {
+			gateway.Port = ref.Port
+		}
+	}
+
+	if len(gateway.Name) == 0 {
+		gateway.Name = DefaultGatewayName
+	}
+	if len(gateway.Namespace) == 0 {
+		gateway.Namespace = fni.Namespace
+	}
+	if gateway.Port == 0 {
+		gateway.Port = DefaultGatewayPort
+	}
+
+	gateway.Service = gateway.Name
+	if gateway.Namespace != fni.Namespace {
+		gateway.Service = GatewayServiceName(fni)
+		gateway.crossNamespace = true
+	}
+
+	return gateway
+}
+
+// GatewayServiceName is the name of the ExternalName Service created for a
+// FunctionIngress in another namespace to the gateway, see ShortName.
+func GatewayServiceName(fni *faasv1.FunctionIngress) string {
+	return ShortName(fni.Name, "gateway")
+}
+
+// ExternalName is the DNS name of the gateway within the cluster, such as
+// "gateway.openfaas.svc.cluster.local".
+func (g Gateway) ExternalName(clusterDomain string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", g.Name, g.Namespace, clusterDomain)
+}
+
+// Backend returns the Service and port that a route is sent to, which is the
+// function itself when the gateway is bypassed, or the Service created for it
+// when the function is in another namespace.
+func Backend(fni *faasv1.FunctionIngress, route Route, gateway Gateway) (string, int32) {
+	if fni.Spec.BypassesGateway() {
+		function := FunctionRef{Name: route.Function, Namespace: route.FunctionNamespace}
+		if BypassCrossNamespace(fni, function) {
+			return FunctionServiceName(fni, function), OpenfaasWorkloadPort
+		}
+		return route.Function, OpenfaasWorkloadPort
+	}
+	return gateway.Service, gateway.Port
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestResolveGateway(t *testing.T) {
+	cases := []struct {
+		name     string
+		gateway  *faasv1.GatewayReference
+		defaults faasv1.GatewayReference
+		want     Gateway
+	}{
+		{
+			name: "no defaults uses the gateway in the same namespace",
+			want: Gateway{Name: "gateway", Namespace: "openfaas", Port: 8080, Service: "gateway"},
+		},
+		{
+			name:     "operator defaults rename the gateway",
+			defaults: faasv1.GatewayReference{Name: "openfaas-gateway", Port: 80},
+			want:     Gateway{Name: "openfaas-gateway", Namespace: "openfaas", Port: 80, Service: "openfaas-gateway"},
+		},
+		{
+			name:     "spec overrides each field of the defaults",
+			gateway:  &faasv1.GatewayReference{Port: 8081},
+			defaults: faasv1.GatewayReference{Name: "openfaas-gateway", Port: 80},
+			want:     Gateway{Name: "openfaas-gateway", Namespace: "openfaas", Port: 8081, Service: "openfaas-gateway"},
+		},
+		{
+			name:    "gateway in another namespace uses an ExternalName Service",
+			gateway: &faasv1.GatewayReference{Namespace: "openfaas-system"},
+			want:    Gateway{Name: "gateway", Namespace: "openfaas-system", Port: 8080, Service: "nodeinfo-gateway", crossNamespace: true},
+		},
+		{
+			name:     "operator default namespace applies to every FunctionIngress",
+			defaults: faasv1.GatewayReference{Namespace: "openfaas-system"},
+			want:     Gateway{Name: "gateway", Namespace: "openfaas-system", Port: 8080, Service: "nodeinfo-gateway", crossNamespace: true},
+		},
+		{
+			name:    "gateway named like the ExternalName Service in another namespace",
+			gateway: &faasv1.GatewayReference{Name: "nodeinfo-gateway", Namespace: "openfaas-system"},
+			want:    Gateway{Name: "nodeinfo-gateway", Namespace: "openfaas-system", Port: 8080, Service: "nodeinfo-gateway", crossNamespace: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+				Spec:       faasv1.FunctionIngressSpec{Gateway: tc.gateway},
+			}
+
+			got := ResolveGateway(fni, tc.defaults)
+			if got != tc.want {
+				t.Errorf("want %+v, got %+v", tc.want, got)
+			}
+			if got.CrossNamespace() != (tc.want.Namespace != "openfaas") {
+				t.Errorf("want CrossNamespace %v", tc.want.Namespace != "openfaas")
+			}
+		})
+	}
+}
+
+func TestGatewayServiceName_FitsAServiceName(t *testing.T) {
+	fni := &faasv1.FunctionIngress{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 60)}}
+
+	name := GatewayServiceName(fni)
+	if msgs := validation.IsDNS1035Label(name); len(msgs) > 0 {
+		t.Errorf("want %s to be a valid Service name, got %v", name, msgs)
+	}
+}
+
+func TestGateway_ExternalName(t *testing.T) {
+	gateway := Gateway{Name: "gateway", Namespace: "openfaas"}
+
+	want := "gateway.openfaas.svc.cluster.local"
+	if got := gateway.ExternalName("cluster.local"); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
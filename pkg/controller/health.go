@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Health records the state used by the liveness and readiness probes, it
+// is shared by copies of the BaseController.
+type Health struct {
+	// running is true whilst the workers are started, i.e. after the
+	// caches have synced and, with leader election, only on the leader.
+	running atomic.Bool
+
+	// lastActivity is the time in unix nanoseconds that a worker last took
+	// an item from, or finished an item on the workqueue.
+	lastActivity atomic.Int64
+}
+
+// NewHealth returns a Health which is not ready
+func NewHealth() *Health {
+	return &Health{}
+}
+
+func (h *Health) started() {
+	h.observe()
+	h.running.Store(true)
+}
+
+func (h *Health) stopped() {
+	h.running.Store(false)
+}
+
+func (h *Health) observe() {
+	h.lastActivity.Store(time.Now().UnixNano())
+}
+
+// Ready returns an error until the informer caches have synced and the
+// workers are started.
+func (h *Health) Ready() error {
+	if !h.running.Load() {
+		return fmt.Errorf("workers have not been started")
+	}
+	return nil
+}
+
+// Live returns an error when the workers are started and there are items
+// on the workqueue, but no worker has made progress within stallTimeout.
+func (h *Health) Live(queueLen int, stallTimeout time.Duration) error {
+	if !h.running.Load() || queueLen == 0 {
+		return nil
+	}
+
+	idle := time.Since(time.Unix(0, h.lastActivity.Load()))
+	if idle > stallTimeout {
+		return fmt.Errorf("%d items in the workqueue, but no item has been processed for %s", queueLen, idle.Round(time.Second))
+	}
+
+	return nil
+}
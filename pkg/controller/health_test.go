@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestHealth_NotReadyUntilStarted(t *testing.T) {
+	h := NewHealth()
+	if err := h.Ready(); err == nil {
+		t.Fatalf("want not ready before the workers are started")
+	}
+
+	h.started()
+	if err := h.Ready(); err != nil {
+		t.Fatalf("want ready after the workers are started, got: %s", err)
+	}
+
+	h.stopped()
+	if err := h.Ready(); err == nil {
+		t.Fatalf("want not ready after the workers are stopped, i.e. leadership was lost")
+	}
+}
+
+func TestHealth_Live(t *testing.T) {
+	cases := []struct {
+		name     string
+		running  bool
+		queueLen int
+		idle     time.Duration
+		wantErr  bool
+	}{
+		{name: "not started, i.e. not the leader", running: false, queueLen: 10, idle: time.Hour},
+		{name: "empty queue", running: true, queueLen: 0, idle: time.Hour},
+		{name: "recent progress", running: true, queueLen: 10, idle: time.Second},
+		{name: "stalled", running: true, queueLen: 10, idle: time.Hour, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHealth()
+			h.running.Store(tc.running)
+			h.lastActivity.Store(time.Now().Add(-tc.idle).UnixNano())
+
+			err := h.Live(tc.queueLen, time.Minute)
+			if tc.wantErr && err == nil {
+				t.Fatalf("want an error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("want no error, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestProcessNextWorkItem_RecordsActivity(t *testing.T) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+	defer queue.ShutDown()
+
+	c := BaseController{
+		Workqueue: queue,
+		SyncHandler: func(ctx context.Context, key string) error {
+			return nil
+		},
+		Health: NewHealth(),
+	}
+	c.Health.running.Store(true)
+	c.Health.lastActivity.Store(time.Now().Add(-time.Hour).UnixNano())
+
+	queue.Add("openfaas/nodeinfo")
+	queue.Add("openfaas/figlet")
+	if err := c.Healthz(time.Minute); err == nil {
+		t.Fatalf("want stalled workqueue to fail the liveness check")
+	}
+
+	c.processNextWorkItem(context.Background())
+	if err := c.Healthz(time.Minute); err != nil {
+		t.Fatalf("want liveness check to pass after progress, got: %s", err)
+	}
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	klog "k8s.io/klog"
+)
+
+// IngressClassAnnotation is the deprecated annotation which selected the
+// ingress controller before IngressClassName was added to the Ingress spec.
+const IngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// defaultIngressClass is used when the FunctionIngress does not name a class
+// and the cluster has no default IngressClass.
+const defaultIngressClass = "nginx"
+
+// ingressControllers maps the spec.controller of an IngressClass to the
+// dialect of rewrite annotations that the controller understands.
+var ingressControllers = map[string]string{
+	"k8s.io/ingress-nginx":          "nginx",
+	"traefik.io/ingress-controller": "traefik",
+	"zalando.org/skipper":           "skipper",
+}
+
+// IngressClass is the class that the Ingress of a FunctionIngress is
+// created for.
+type IngressClass struct {
+	// Name is set as the IngressClassName of the Ingress
+	Name string
+
+	// Dialect is the set of annotations used for rewrites, such as "nginx",
+	// "traefik" or "skipper". It is empty when the controller is not known,
+	// then no rewrite annotations are set.
+	Dialect string
+}
+
+// ResolveIngressClass picks the IngressClass for the Ingress of the
+// FunctionIngress. The name is taken from IngressClassName, then IngressType,
+// then the cluster's default IngressClass. The dialect is inferred from the
+// controller of the IngressClass, so that a custom class name still gets the
+// right rewrite annotations, otherwise it falls back to IngressType or the
+// name itself. A nil lister resolves the class as if no IngressClass existed.
+func ResolveIngressClass(fni *faasv1.FunctionIngress, lister networkinglisters.IngressClassLister) IngressClass {
+	name := fni.Spec.IngressClassName
+	if len(name) == 0 {
+		name = fni.Spec.IngressType
+	}
+	if len(name) == 0 {
+		name = defaultIngressClassName(lister)
+	}
+
+	if lister != nil {
+		ingressClass, err := lister.Get(name)
+		if err == nil {
+			if dialect, ok := ingressControllers[ingressClass.Spec.Controller]; ok {
+				return IngressClass{Name: name, Dialect: dialect}
+			}
+		} else if !errors.IsNotFound(err) {
+			klog.Errorf("cannot get IngressClass %s: %v", name, err)
+		}
+	}
+
+	for _, dialect := range []string{fni.Spec.IngressType, name} {
+		if isDialect(dialect) {
+			return IngressClass{Name: name, Dialect: dialect}
+		}
+	}
+
+	return IngressClass{Name: name}
+}
+
+// defaultIngressClassName returns the IngressClass marked as the cluster
+// default. When more than one is marked, the newest wins, as it does for the
+// DefaultIngressClass admission plugin.
+func defaultIngressClassName(lister networkinglisters.IngressClassLister) string {
+	if lister == nil {
+		return defaultIngressClass
+	}
+
+	classes, err := lister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("cannot list IngressClasses: %v", err)
+		return defaultIngressClass
+	}
+
+	var newest *netv1.IngressClass
+	for _, ingressClass := range classes {
+		if ingressClass.Annotations[netv1.AnnotationIsDefaultIngressClass] != "true" {
+			continue
+		}
+		if newest == nil ||
+			ingressClass.CreationTimestamp.After(newest.CreationTimestamp.Time) ||
+			(ingressClass.CreationTimestamp.Equal(&newest.CreationTimestamp) && ingressClass.Name < newest.Name) {
+			newest = ingressClass
+		}
+	}
+
+	if newest == nil {
+		return defaultIngressClass
+	}
+	return newest.Name
+}
+
+func isDialect(value string) bool {
+	for _, dialect := range ingressControllers {
+		if value == dialect {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newIngressClass(name, controller string, isDefault bool, created time.Time) *netv1.IngressClass {
+	ingressClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations:       map[string]string{},
+		},
+		Spec: netv1.IngressClassSpec{
+			Controller: controller,
+		},
+	}
+	if isDefault {
+		ingressClass.Annotations[netv1.AnnotationIsDefaultIngressClass] = "true"
+	}
+	return ingressClass
+}
+
+func newIngressClassLister(t *testing.T, classes ...*netv1.IngressClass) networkinglisters.IngressClassLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ingressClass := range classes {
+		if err := indexer.Add(ingressClass); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	return networkinglisters.NewIngressClassLister(indexer)
+}
+
+func TestResolveIngressClass(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	classes := []*netv1.IngressClass{
+		newIngressClass("nginx-internal", "k8s.io/ingress-nginx", false, older),
+		newIngressClass("edge", "traefik.io/ingress-controller", true, older),
+		newIngressClass("public", "k8s.io/ingress-nginx", true, newer),
+		newIngressClass("haproxy", "haproxy.org/ingress-controller/haproxy", false, older),
+	}
+
+	cases := []struct {
+		name    string
+		spec    faasv1.FunctionIngressSpec
+		classes []*netv1.IngressClass
+		want    IngressClass
+	}{
+		{
+			name: "no IngressClasses defaults to nginx",
+			spec: faasv1.FunctionIngressSpec{},
+			want: IngressClass{Name: "nginx", Dialect: "nginx"},
+		},
+		{
+			name:    "newest default IngressClass is used when no class is named",
+			spec:    faasv1.FunctionIngressSpec{},
+			classes: classes,
+			want:    IngressClass{Name: "public", Dialect: "nginx"},
+		},
+		{
+			name:    "ingressClassName with a custom name gets the dialect of its controller",
+			spec:    faasv1.FunctionIngressSpec{IngressClassName: "nginx-internal"},
+			classes: classes,
+			want:    IngressClass{Name: "nginx-internal", Dialect: "nginx"},
+		},
+		{
+			name:    "ingressType names the class when ingressClassName is empty",
+			spec:    faasv1.FunctionIngressSpec{IngressType: "edge"},
+			classes: classes,
+			want:    IngressClass{Name: "edge", Dialect: "traefik"},
+		},
+		{
+			name:    "controller of the IngressClass wins over ingressType",
+			spec:    faasv1.FunctionIngressSpec{IngressType: "traefik", IngressClassName: "nginx-internal"},
+			classes: classes,
+			want:    IngressClass{Name: "nginx-internal", Dialect: "nginx"},
+		},
+		{
+			name:    "unknown controller falls back to ingressType",
+			spec:    faasv1.FunctionIngressSpec{IngressType: "skipper", IngressClassName: "haproxy"},
+			classes: classes,
+			want:    IngressClass{Name: "haproxy", Dialect: "skipper"},
+		},
+		{
+			name:    "unknown controller without ingressType has no dialect",
+			spec:    faasv1.FunctionIngressSpec{IngressClassName: "haproxy"},
+			classes: classes,
+			want:    IngressClass{Name: "haproxy"},
+		},
+		{
+			name: "missing IngressClass falls back to its name",
+			spec: faasv1.FunctionIngressSpec{IngressType: "traefik"},
+			want: IngressClass{Name: "traefik", Dialect: "traefik"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{Spec: tc.spec}
+
+			got := ResolveIngressClass(fni, newIngressClassLister(t, tc.classes...))
+			if got != tc.want {
+				t.Errorf("want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveIngressClass_NilLister(t *testing.T) {
+	fni := &faasv1.FunctionIngress{}
+
+	want := IngressClass{Name: "nginx", Dialect: "nginx"}
+	if got := ResolveIngressClass(fni, nil); got != want {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+)
+
+var (
+	functionIngressesByClassDesc = prometheus.NewDesc(
+		"ingress_operator_functioningresses_by_class",
+		"Number of FunctionIngresses managed by the operator by ingress class.",
+		[]string{"ingress_class"}, nil,
+	)
+
+	functionIngressesByTLSDesc = prometheus.NewDesc(
+		"ingress_operator_functioningresses_by_tls",
+		"Number of FunctionIngresses managed by the operator by whether TLS is enabled.",
+		[]string{"tls"}, nil,
+	)
+)
+
+// FunctionIngressCollector reports gauges of the FunctionIngresses in the
+// informer caches, they are computed when the metrics are scraped.
+type FunctionIngressCollector struct {
+	ingressClassLister networkinglisters.IngressClassLister
+	listers            []listers.FunctionIngressLister
+}
+
+// NewFunctionIngressCollector returns a collector which lists
+// FunctionIngresses from each of the watched namespaces' listers, and
+// groups them by the IngressClass that ResolveIngressClass picks for them
+func NewFunctionIngressCollector(ingressClassLister networkinglisters.IngressClassLister, listers ...listers.FunctionIngressLister) *FunctionIngressCollector {
+	return &FunctionIngressCollector{ingressClassLister: ingressClassLister, listers: listers}
+}
+
+// Describe implements prometheus.Collector
+func (c *FunctionIngressCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- functionIngressesByClassDesc
+	ch <- functionIngressesByTLSDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *FunctionIngressCollector) Collect(ch chan<- prometheus.Metric) {
+	byClass := map[string]int{}
+	byTLS := map[string]int{"enabled": 0, "disabled": 0}
+	for _, lister := range c.listers {
+		fnis, err := lister.List(labels.Everything())
+		if err != nil {
+			runtime.HandleError(err)
+			return
+		}
+
+		for _, fni := range fnis {
+			byClass[ResolveIngressClass(fni, c.ingressClassLister).Name]++
+			if fni.Spec.UseTLS() {
+				byTLS["enabled"]++
+			} else {
+				byTLS["disabled"]++
+			}
+		}
+	}
+
+	for class, count := range byClass {
+		ch <- prometheus.MustNewConstMetric(functionIngressesByClassDesc, prometheus.GaugeValue, float64(count), class)
+	}
+	for tls, count := range byTLS {
+		ch <- prometheus.MustNewConstMetric(functionIngressesByTLSDesc, prometheus.GaugeValue, float64(count), tls)
+	}
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+)
+
+func TestFunctionIngressCollector(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&faasv1.FunctionIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+	})
+	indexer.Add(&faasv1.FunctionIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "figlet", Namespace: "openfaas"},
+		Spec: faasv1.FunctionIngressSpec{
+			IngressType: "traefik",
+			TLS:         &faasv1.FunctionIngressTLS{Enabled: true},
+		},
+	})
+	indexer.Add(&faasv1.FunctionIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "env", Namespace: "openfaas"},
+		Spec: faasv1.FunctionIngressSpec{
+			IngressType:      "nginx",
+			IngressClassName: "nginx-internal",
+		},
+	})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewFunctionIngressCollector(nil, listers.NewFunctionIngressLister(indexer)))
+
+	want := `
+# HELP ingress_operator_functioningresses_by_class Number of FunctionIngresses managed by the operator by ingress class.
+# TYPE ingress_operator_functioningresses_by_class gauge
+ingress_operator_functioningresses_by_class{ingress_class="nginx"} 1
+ingress_operator_functioningresses_by_class{ingress_class="nginx-internal"} 1
+ingress_operator_functioningresses_by_class{ingress_class="traefik"} 1
+# HELP ingress_operator_functioningresses_by_tls Number of FunctionIngresses managed by the operator by whether TLS is enabled.
+# TYPE ingress_operator_functioningresses_by_tls gauge
+ingress_operator_functioningresses_by_tls{tls="disabled"} 2
+ingress_operator_functioningresses_by_tls{tls="enabled"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+// RoutePath is a route with its path translated for the class which serves
+// it, see MakePaths.
+type RoutePath struct {
+	Route
+
+	// Match is the path written to the Ingress, HTTPRoute or IngressRoute,
+	// such as "/v1/users" or "/v1/users(?:/|$)(.*)"
+	Match string
+
+	// Type is how Match is compared, one of the values of PathMatch
+	Type string
+
+	// Regex matches the path of a request for the route from its start, its
+	// first capture group is the rest of the path kept by Replacement
+	Regex string
+
+	// Replacement is the path on the gateway that a request matched by Regex
+	// is rewritten to, such as "/function/users/$1". It is empty when the
+	// gateway is bypassed
+	Replacement string
+}
+
+// ClassDialect returns the dialect that the paths of the FunctionIngress are
+// written for, which is the class itself for the gateway-api and traefik-crd
+// IngressTypes, otherwise the dialect of the IngressClass.
+func ClassDialect(fni *faasv1.FunctionIngress, dialect string) string {
+	class := GetClass(fni.Spec.IngressType)
+	if class == GatewayAPIClass || class == TraefikCRDClass {
+		return class
+	}
+	return dialect
+}
+
+// PathMatch returns how the paths of the FunctionIngress are matched by the
+// dialect. Without pathMatch a path is a regular expression, other than for
+// the prefixOnlyDialects.
+func PathMatch(fni *faasv1.FunctionIngress, dialect string) string {
+	if len(fni.Spec.PathMatch) > 0 {
+		return fni.Spec.PathMatch
+	}
+	if prefixOnlyDialects[ClassDialect(fni, dialect)] {
+		return faasv1.PathMatchPrefix
+	}
+	return faasv1.PathMatchRegex
+}
+
+// MakePaths returns the routes of the FunctionIngress with their paths
+// translated for the dialect, see ValidatePathMatch for the combinations
+// which can not be translated.
+func MakePaths(fni *faasv1.FunctionIngress, dialect string) []RoutePath {
+	dialect = ClassDialect(fni, dialect)
+	pathMatch := PathMatch(fni, dialect)
+
+	paths := []RoutePath{}
+	for _, route := range MakeRoutes(fni) {
+		path := RoutePath{Route: route, Match: route.Path, Type: pathMatch}
+
+		switch pathMatch {
+		case faasv1.PathMatchPrefix:
+			path.Match = pathPrefix(route.Path)
+			path.Regex = "/(.*)"
+			if path.Match != "/" {
+				path.Regex = regexp.QuoteMeta(path.Match) + "(?:/|$)(.*)"
+			}
+			path.Replacement = route.Rewrite + "/$1"
+		case faasv1.PathMatchExact:
+			path.Regex = regexp.QuoteMeta(route.Path) + "$"
+			path.Replacement = route.Rewrite
+		default:
+			path.Regex = route.Path
+			path.Replacement = route.Rewrite + "/$1"
+		}
+
+		if len(route.Rewrite) == 0 {
+			path.Replacement = ""
+		} else if dialect == "nginx" {
+			// ingress-nginx can only rewrite a path which it matches with a
+			// regular expression
+			path.Match = path.Regex
+			path.Type = faasv1.PathMatchRegex
+		}
+
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// ValidatePathMatch returns an error when the dialect can not match the
+// paths of the FunctionIngress as its pathMatch asks.
+func ValidatePathMatch(fni *faasv1.FunctionIngress, dialect string) error {
+	if fni.Spec.PathMatch != faasv1.PathMatchRegex {
+		return nil
+	}
+
+	switch ClassDialect(fni, dialect) {
+	case "traefik":
+		return fmt.Errorf("the traefik ingressType can not match a path with a regular expression, use pathMatch Prefix or the %s ingressType instead", TraefikCRDClass)
+	case GatewayAPIClass:
+		// A URLRewrite filter can not use the capture groups of a path
+		if !fni.Spec.BypassesGateway() {
+			return fmt.Errorf("the %s ingressType can only rewrite a Prefix or Exact path, use one of them or bypass the gateway", GatewayAPIClass)
+		}
+	}
+	return nil
+}
+
+// pathPrefix converts a path, which may end with a regex capture group such
+// as "/v1/profiles/(.*)", to a plain prefix without a trailing slash.
+func pathPrefix(path string) string {
+	path = strings.TrimSuffix(path, "(.*)")
+	path = strings.TrimSuffix(path, "/")
+	if len(path) == 0 {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+func TestMakePaths(t *testing.T) {
+	cases := []struct {
+		name            string
+		spec            faasv1.FunctionIngressSpec
+		dialect         string
+		wantMatch       string
+		wantType        string
+		wantReplacement string
+	}{
+		{
+			name:            "regex by default with nginx",
+			spec:            faasv1.FunctionIngressSpec{Function: "nodeinfo", Path: "/v1/(.*)"},
+			dialect:         "nginx",
+			wantMatch:       "/v1/(.*)",
+			wantType:        faasv1.PathMatchRegex,
+			wantReplacement: "/function/nodeinfo/$1",
+		},
+		{
+			name:            "prefix by default with traefik",
+			spec:            faasv1.FunctionIngressSpec{Function: "nodeinfo", Path: "/v1/(.*)"},
+			dialect:         "traefik",
+			wantMatch:       "/v1",
+			wantType:        faasv1.PathMatchPrefix,
+			wantReplacement: "/function/nodeinfo/$1",
+		},
+		{
+			name:            "prefix is a regex when nginx rewrites it",
+			spec:            faasv1.FunctionIngressSpec{Function: "nodeinfo", Path: "/v1.0/users", PathMatch: faasv1.PathMatchPrefix},
+			dialect:         "nginx",
+			wantMatch:       `/v1\.0/users(?:/|$)(.*)`,
+			wantType:        faasv1.PathMatchRegex,
+			wantReplacement: "/function/nodeinfo/$1",
+		},
+		{
+			name:      "prefix without a rewrite with nginx",
+			spec:      faasv1.FunctionIngressSpec{Function: "nodeinfo", Path: "/v1/users/", PathMatch: faasv1.PathMatchPrefix, BypassGateway: true},
+			dialect:   "nginx",
+			wantMatch: "/v1/users",
+			wantType:  faasv1.PathMatchPrefix,
+		},
+		{
+			name:            "exact with skipper",
+			spec:            faasv1.FunctionIngressSpec{Function: "nodeinfo", Path: "/healthz", PathMatch: faasv1.PathMatchExact},
+			dialect:         "skipper",
+			wantMatch:       "/healthz",
+			wantType:        faasv1.PathMatchExact,
+			wantReplacement: "/function/nodeinfo",
+		},
+		{
+			name:            "exact defaults to the root path",
+			spec:            faasv1.FunctionIngressSpec{Function: "nodeinfo", PathMatch: faasv1.PathMatchExact, IngressType: GatewayAPIClass},
+			dialect:         "nginx",
+			wantMatch:       "/",
+			wantType:        faasv1.PathMatchExact,
+			wantReplacement: "/function/nodeinfo",
+		},
+		{
+			name:            "regex with traefik-crd",
+			spec:            faasv1.FunctionIngressSpec{Function: "nodeinfo", Path: "/v[0-9]+/(.*)", PathMatch: faasv1.PathMatchRegex, IngressType: TraefikCRDClass},
+			dialect:         "nginx",
+			wantMatch:       "/v[0-9]+/(.*)",
+			wantType:        faasv1.PathMatchRegex,
+			wantReplacement: "/function/nodeinfo/$1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+				Spec:       tc.spec,
+			}
+
+			path := MakePaths(fni, tc.dialect)[0]
+			if path.Match != tc.wantMatch || path.Type != tc.wantType || path.Replacement != tc.wantReplacement {
+				t.Errorf("want %s %q rewritten to %q, got %s %q rewritten to %q",
+					tc.wantType, tc.wantMatch, tc.wantReplacement, path.Type, path.Match, path.Replacement)
+			}
+		})
+	}
+}
+
+func TestValidatePathMatch(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    faasv1.FunctionIngressSpec
+		dialect string
+		wantErr string
+	}{
+		{
+			name:    "regex with nginx",
+			spec:    faasv1.FunctionIngressSpec{PathMatch: faasv1.PathMatchRegex},
+			dialect: "nginx",
+		},
+		{
+			name:    "regex with traefik",
+			spec:    faasv1.FunctionIngressSpec{PathMatch: faasv1.PathMatchRegex},
+			dialect: "traefik",
+			wantErr: "traefik ingressType can not match a path with a regular expression",
+		},
+		{
+			name:    "regex rewritten by an HTTPRoute",
+			spec:    faasv1.FunctionIngressSpec{PathMatch: faasv1.PathMatchRegex, IngressType: GatewayAPIClass},
+			wantErr: "can only rewrite a Prefix or Exact path",
+		},
+		{
+			name: "regex with an HTTPRoute which bypasses the gateway",
+			spec: faasv1.FunctionIngressSpec{PathMatch: faasv1.PathMatchRegex, IngressType: GatewayAPIClass, BypassGateway: true},
+		},
+		{
+			name:    "exact with traefik",
+			spec:    faasv1.FunctionIngressSpec{PathMatch: faasv1.PathMatchExact},
+			dialect: "traefik",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePathMatch(&faasv1.FunctionIngress{Spec: tc.spec}, tc.dialect)
+			if len(tc.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("want no error, got %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("want an error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: "/"},
+		{path: "/", want: "/"},
+		{path: "/(.*)", want: "/"},
+		{path: "/v1/profiles/view/(.*)", want: "/v1/profiles/view"},
+		{path: "/v1/", want: "/v1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := pathPrefix(tc.path); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
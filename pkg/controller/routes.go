@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+// Route is a path on the domains of a FunctionIngress and the function which
+// serves it, with the defaults from the spec applied.
+type Route struct {
+	// Path such as "/v1/users/(.*)"
+	Path string
+
+	Function string
+
+	FunctionNamespace string
+
+	// Rewrite is the path on the gateway that the path is rewritten to, such
+	// as "/function/users", it is empty when the gateway is bypassed
+	Rewrite string
+}
+
+// MakeRoutes returns the routes of the FunctionIngress. Without Routes in the
+// spec there is a single route built from Path and Function.
+func MakeRoutes(fni *faasv1.FunctionIngress) []Route {
+	if len(fni.Spec.Routes) == 0 {
+		return []Route{
+			makeRoute(fni, faasv1.FunctionRoute{Path: fni.Spec.Path}),
+		}
+	}
+
+	routes := make([]Route, 0, len(fni.Spec.Routes))
+	for _, r := range fni.Spec.Routes {
+		routes = append(routes, makeRoute(fni, r))
+	}
+	return routes
+}
+
+func makeRoute(fni *faasv1.FunctionIngress, r faasv1.FunctionRoute) Route {
+	route := Route{
+		Path:              r.Path,
+		Function:          r.Function,
+		FunctionNamespace: r.FunctionNamespace,
+	}
+
+	if len(route.Path) == 0 {
+		route.Path = "/(.*)"
+		if fni.Spec.BypassesGateway() || fni.Spec.PathMatch == faasv1.PathMatchPrefix || fni.Spec.PathMatch == faasv1.PathMatchExact {
+			route.Path = "/"
+		}
+	}
+
+	if len(route.Function) == 0 {
+		route.Function = fni.Spec.Function
+	}
+
+	if len(route.FunctionNamespace) == 0 {
+		route.FunctionNamespace = fni.Spec.FunctionNamespace
+	}
+
+	if !fni.Spec.BypassesGateway() {
+		route.Rewrite = r.Rewrite
+		if len(route.Rewrite) == 0 {
+			route.Rewrite = functionPath(route.Function, route.FunctionNamespace)
+		}
+	}
+
+	return route
+}
+
+// ValidateRoutes returns an error when the routes of the FunctionIngress can
+// not be rendered with the annotations of the dialect, see ResolveIngressClass.
+func ValidateRoutes(fni *faasv1.FunctionIngress, dialect string) error {
+	paths := map[string]bool{}
+	rewrites := map[string]bool{}
+
+	for i, route := range MakeRoutes(fni) {
+		if len(route.Function) == 0 {
+			return fmt.Errorf("route %d has no function, set function on the route or the spec", i)
+		}
+
+		if paths[route.Path] {
+			return fmt.Errorf("route %d uses the path %q more than once", i, route.Path)
+		}
+		paths[route.Path] = true
+
+		if fni.Spec.MayBypassGateway() && len(fni.Spec.Routes) > 0 && len(fni.Spec.Routes[i].Rewrite) > 0 {
+			return fmt.Errorf("route %d can not set a rewrite when bypassing the gateway", i)
+		}
+
+		rewrites[route.Rewrite] = true
+	}
+
+	if len(rewrites) < 2 {
+		return nil
+	}
+
+	switch dialect {
+	case "traefik":
+		// The rewrite-target annotation applies to every path of the Ingress
+		return fmt.Errorf("the traefik ingressType can only send every route to the same function, use %s instead", TraefikCRDClass)
+	case "skipper":
+		return validateSkipperRewrites(MakePaths(fni, dialect))
+	}
+
+	return nil
+}
+
+// validateSkipperRewrites checks that only one filter in the chain from
+// skipperRewrites changes the path of a request. The chain is applied to
+// every path of the Ingress, so the path of each route must not match the
+// path or the rewrite of any other route.
+func validateSkipperRewrites(paths []RoutePath) error {
+	for i, path := range paths {
+		re, err := regexp.Compile("^" + path.Regex)
+		if err != nil {
+			return fmt.Errorf("route %d has an invalid path %q: %w", i, path.Path, err)
+		}
+
+		for j, other := range paths {
+			if i == j {
+				continue
+			}
+			if re.MatchString(strings.TrimSuffix(other.Match, "(.*)")) || re.MatchString(other.Rewrite+"/") {
+				return fmt.Errorf("route %d with path %q overlaps route %d, skipper can only rewrite routes which do not overlap", i, path.Path, j)
+			}
+		}
+	}
+	return nil
+}
+
+// SingleRewrite returns the rewrite shared by every route and true, or false
+// when the routes are sent to different paths on the gateway.
+func SingleRewrite(routes []Route) (string, bool) {
+	for _, route := range routes[1:] {
+		if route.Rewrite != routes[0].Rewrite {
+			return "", false
+		}
+	}
+	return routes[0].Rewrite, true
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+func TestMakeRoutes(t *testing.T) {
+	cases := []struct {
+		name string
+		spec faasv1.FunctionIngressSpec
+		want []Route
+	}{
+		{
+			name: "without routes the path and function are used",
+			spec: faasv1.FunctionIngressSpec{
+				Function:          "nodeinfo",
+				FunctionNamespace: "staging-fn",
+				Path:              "/v1/profiles/(.*)",
+			},
+			want: []Route{
+				{Path: "/v1/profiles/(.*)", Function: "nodeinfo", FunctionNamespace: "staging-fn", Rewrite: "/function/nodeinfo.staging-fn"},
+			},
+		},
+		{
+			name: "default path",
+			spec: faasv1.FunctionIngressSpec{Function: "nodeinfo"},
+			want: []Route{
+				{Path: "/(.*)", Function: "nodeinfo", Rewrite: "/function/nodeinfo"},
+			},
+		},
+		{
+			name: "default path with bypass has no rewrite",
+			spec: faasv1.FunctionIngressSpec{Function: "nodeinfo", BypassGateway: true},
+			want: []Route{
+				{Path: "/", Function: "nodeinfo"},
+			},
+		},
+		{
+			name: "routes default to the function of the spec",
+			spec: faasv1.FunctionIngressSpec{
+				Function:          "nodeinfo",
+				FunctionNamespace: "openfaas-fn",
+				Path:              "/ignored/(.*)",
+				Routes: []faasv1.FunctionRoute{
+					{Path: "/v1/users/(.*)", Function: "users"},
+					{Path: "/v1/orders/(.*)", Function: "orders", FunctionNamespace: "staging-fn"},
+					{Path: "/v1/info/(.*)", Rewrite: "/function/nodeinfo/v1"},
+				},
+			},
+			want: []Route{
+				{Path: "/v1/users/(.*)", Function: "users", FunctionNamespace: "openfaas-fn", Rewrite: "/function/users.openfaas-fn"},
+				{Path: "/v1/orders/(.*)", Function: "orders", FunctionNamespace: "staging-fn", Rewrite: "/function/orders.staging-fn"},
+				{Path: "/v1/info/(.*)", Function: "nodeinfo", FunctionNamespace: "openfaas-fn", Rewrite: "/function/nodeinfo/v1"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MakeRoutes(&faasv1.FunctionIngress{Spec: tc.spec})
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf("want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    faasv1.FunctionIngressSpec
+		wantErr string
+	}{
+		{
+			name: "single function is valid",
+			spec: faasv1.FunctionIngressSpec{Function: "nodeinfo"},
+		},
+		{
+			name:    "function is required",
+			spec:    faasv1.FunctionIngressSpec{},
+			wantErr: "has no function",
+		},
+		{
+			name: "route without a function or a default is invalid",
+			spec: faasv1.FunctionIngressSpec{
+				Routes: []faasv1.FunctionRoute{{Path: "/v1/users/(.*)", Function: "users"}, {Path: "/v1/orders/(.*)"}},
+			},
+			wantErr: "route 1 has no function",
+		},
+		{
+			name: "duplicate paths are invalid",
+			spec: faasv1.FunctionIngressSpec{
+				Routes: []faasv1.FunctionRoute{{Path: "/v1/(.*)", Function: "users"}, {Path: "/v1/(.*)", Function: "orders"}},
+			},
+			wantErr: "more than once",
+		},
+		{
+			name: "rewrite with bypass is invalid",
+			spec: faasv1.FunctionIngressSpec{
+				BypassGateway:     true,
+				FunctionNamespace: "openfaas",
+				Routes:            []faasv1.FunctionRoute{{Path: "/v1/(.*)", Function: "users", Rewrite: "/v1"}},
+			},
+			wantErr: "can not set a rewrite",
+		},
+		{
+			name: "bypass route in another namespace is valid",
+			spec: faasv1.FunctionIngressSpec{
+				BypassGateway:     true,
+				FunctionNamespace: "openfaas",
+				Routes:            []faasv1.FunctionRoute{{Path: "/v1/(.*)", Function: "users", FunctionNamespace: "openfaas-fn"}},
+			},
+		},
+		{
+			name: "traefik can not send routes to several functions",
+			spec: faasv1.FunctionIngressSpec{
+				IngressType: "traefik",
+				Routes:      []faasv1.FunctionRoute{{Path: "/v1/users", Function: "users"}, {Path: "/v1/orders", Function: "orders"}},
+			},
+			wantErr: TraefikCRDClass,
+		},
+		{
+			name: "traefik can send several routes to one function",
+			spec: faasv1.FunctionIngressSpec{
+				IngressType: "traefik",
+				Function:    "nodeinfo",
+				Routes:      []faasv1.FunctionRoute{{Path: "/v1"}, {Path: "/v2"}},
+			},
+		},
+		{
+			name: "skipper catch-all after another route is invalid",
+			spec: faasv1.FunctionIngressSpec{
+				IngressType: "skipper",
+				Routes:      []faasv1.FunctionRoute{{Path: "/v1/users/(.*)", Function: "users"}, {Path: "/(.*)", Function: "www"}},
+			},
+			wantErr: "route 1 with path \"/(.*)\" overlaps route 0",
+		},
+		{
+			name: "skipper catch-all before another route is invalid",
+			spec: faasv1.FunctionIngressSpec{
+				IngressType: "skipper",
+				Routes:      []faasv1.FunctionRoute{{Path: "/(.*)", Function: "www"}, {Path: "/v1/users/(.*)", Function: "users"}},
+			},
+			wantErr: "route 0 with path \"/(.*)\" overlaps route 1",
+		},
+		{
+			name: "skipper distinct prefixes are valid",
+			spec: faasv1.FunctionIngressSpec{
+				IngressType: "skipper",
+				Routes:      []faasv1.FunctionRoute{{Path: "/v1/users/(.*)", Function: "users"}, {Path: "/v1/orders/(.*)", Function: "orders"}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "openfaas"},
+				Spec:       tc.spec,
+			}
+
+			err := ValidateRoutes(fni, ResolveIngressClass(fni, nil).Dialect)
+			if len(tc.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("want no error, got %s", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("want error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog"
+)
+
+// syncFunctionServices converges a Service in the namespace of the
+// FunctionIngress for each function in another namespace which is reached by
+// bypassing the gateway, since an Ingress can only send requests to Services
+// in its own namespace. When the namespace of the function is watched, the
+// Service has no selector and the EndpointSlices of the function are
+// mirrored into it, otherwise it is an ExternalName Service. Services and
+// EndpointSlices which are no longer needed are deleted.
+func (h SyncHandler) syncFunctionServices(ctx context.Context, fni, next *faasv1.FunctionIngress) error {
+	services := []*corev1.Service{}
+	slices := []*discoveryv1.EndpointSlice{}
+	for _, function := range controller.Functions(fni, h.config.Defaults) {
+		if !controller.BypassCrossNamespace(fni, function) {
+			continue
+		}
+
+		service, functionSlices, err := h.makeFunctionService(fni, function)
+		if err != nil {
+			return err
+		}
+		services = append(services, service)
+		slices = append(slices, functionSlices...)
+	}
+
+	for _, service := range services {
+		if err := h.applyService(ctx, fni, next, service); err != nil {
+			return err
+		}
+	}
+	for _, slice := range slices {
+		if err := h.applyEndpointSlice(ctx, fni, next, slice); err != nil {
+			return err
+		}
+	}
+
+	return h.deleteStaleFunctionServices(ctx, fni, services, slices)
+}
+
+// makeFunctionService renders the Service for a function in another
+// namespace, along with the EndpointSlices mirrored from the function's own
+// Service when its namespace is watched.
+func (h SyncHandler) makeFunctionService(fni *faasv1.FunctionIngress, function controller.FunctionRef) (*corev1.Service, []*discoveryv1.EndpointSlice, error) {
+	name := controller.FunctionServiceName(fni, function)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       fni.Namespace,
+			Labels:          makeFunctionLabels(fni, function),
+			OwnerReferences: controller.MakeOwnerRef(fni),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       controller.OpenfaasWorkloadPort,
+					TargetPort: intstr.FromInt32(controller.OpenfaasWorkloadPort),
+				},
+			},
+		},
+	}
+
+	functionLister, ok := h.functionListerFor(function.Namespace)
+	if !ok {
+		service.Spec.Type = corev1.ServiceTypeExternalName
+		service.Spec.ExternalName = fmt.Sprintf("%s.%s.svc.%s", function.Name, function.Namespace, h.clusterDomain())
+		return service, nil, nil
+	}
+
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	source, err := functionLister.services.Services(function.Namespace).Get(function.Name)
+	if err == nil {
+		// The ports are named after those of the function's Service, so that
+		// they match the ports of the mirrored EndpointSlices
+		service.Spec.Ports = make([]corev1.ServicePort, 0, len(source.Spec.Ports))
+		for _, port := range source.Spec.Ports {
+			service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+				Name:        port.Name,
+				Protocol:    port.Protocol,
+				AppProtocol: port.AppProtocol,
+				Port:        port.Port,
+				TargetPort:  port.TargetPort,
+			})
+		}
+	} else if !errors.IsNotFound(err) {
+		return nil, nil, err
+	}
+
+	sourceSlices, err := functionLister.endpointSlices.EndpointSlices(function.Namespace).List(
+		labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: function.Name}))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(sourceSlices, func(i, j int) bool { return sourceSlices[i].Name < sourceSlices[j].Name })
+
+	slices := make([]*discoveryv1.EndpointSlice, 0, len(sourceSlices))
+	for _, source := range sourceSlices {
+		sliceLabels := makeFunctionLabels(fni, function)
+		sliceLabels[discoveryv1.LabelServiceName] = name
+		sliceLabels[discoveryv1.LabelManagedBy] = controller.EndpointSliceManager
+
+		slices = append(slices, &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            controller.ShortName(name, source.Name),
+				Namespace:       fni.Namespace,
+				Labels:          sliceLabels,
+				OwnerReferences: controller.MakeOwnerRef(fni),
+			},
+			AddressType: source.AddressType,
+			Endpoints:   source.DeepCopy().Endpoints,
+			Ports:       source.DeepCopy().Ports,
+		})
+	}
+	return service, slices, nil
+}
+
+// applyEndpointSlice creates or updates an EndpointSlice owned by the
+// FunctionIngress
+func (h SyncHandler) applyEndpointSlice(ctx context.Context, fni, next *faasv1.FunctionIngress, desired *discoveryv1.EndpointSlice) error {
+	name := desired.Name
+	endpointSlices := h.kubeclientset.DiscoveryV1().EndpointSlices(fni.Namespace)
+
+	slice, err := h.endpointSliceLister.EndpointSlices(fni.Namespace).Get(name)
+	if err == nil && metav1.IsControlledBy(slice, fni) && slice.AddressType != desired.AddressType {
+		// The address type of an EndpointSlice can not be changed
+		klog.Infof("Replacing EndpointSlice for: %s", name)
+		if deleteErr := endpointSlices.Delete(ctx, name, metav1.DeleteOptions{}); deleteErr != nil && !errors.IsNotFound(deleteErr) {
+			return fmt.Errorf("cannot delete endpointslice: %s in %s: %w", name, fni.Namespace, deleteErr)
+		}
+		err = errors.NewNotFound(discoveryv1.Resource("endpointslices"), name)
+	}
+
+	if errors.IsNotFound(err) {
+		klog.Infof("Creating EndpointSlice for: %v", name)
+
+		if _, createErr := endpointSlices.Create(ctx, desired, metav1.CreateOptions{}); createErr != nil {
+			klog.Errorf("cannot create endpointslice: %v in %v, error: %v", name, fni.Namespace, createErr.Error())
+			return h.syncFailed(ctx, fni, next, fmt.Sprintf(controller.ErrObjectCreate, "EndpointSlice"),
+				fmt.Sprintf(controller.MessageObjectCreate, "EndpointSlice", name, createErr.Error()), createErr)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get endpointslice: %s in %s: %w", name, fni.Namespace, err)
+	}
+
+	if !metav1.IsControlledBy(slice, fni) {
+		msg := fmt.Sprintf(controller.MessageResourceExists, name)
+		klog.Errorf("%s in %s", msg, fni.Namespace)
+		return h.syncFailed(ctx, fni, next, controller.ErrResourceExists, msg, fmt.Errorf("%s", msg))
+	}
+
+	if equality.Semantic.DeepEqual(slice.Endpoints, desired.Endpoints) &&
+		equality.Semantic.DeepEqual(slice.Ports, desired.Ports) &&
+		equality.Semantic.DeepEqual(slice.Labels, desired.Labels) {
+		return nil
+	}
+
+	klog.Infof("Updating EndpointSlice for: %s", name)
+
+	updated := slice.DeepCopy()
+	updated.Labels = desired.Labels
+	updated.Endpoints = desired.Endpoints
+	updated.Ports = desired.Ports
+
+	if _, updateErr := endpointSlices.Update(ctx, updated, metav1.UpdateOptions{}); updateErr != nil {
+		klog.Errorf("error updating endpointslice: %v", updateErr)
+		return h.syncFailed(ctx, fni, next, fmt.Sprintf(controller.ErrObjectUpdate, "EndpointSlice"),
+			fmt.Sprintf(controller.MessageObjectUpdate, "EndpointSlice", name, updateErr.Error()), updateErr)
+	}
+	return nil
+}
+
+// deleteStaleFunctionServices removes the Services and EndpointSlices of the
+// FunctionIngress for functions which it no longer bypasses the gateway for,
+// or whose endpoints have gone away.
+func (h SyncHandler) deleteStaleFunctionServices(ctx context.Context, fni *faasv1.FunctionIngress, services []*corev1.Service, slices []*discoveryv1.EndpointSlice) error {
+	selector, err := functionSelector(fni)
+	if err != nil {
+		return err
+	}
+
+	keep := map[string]bool{}
+	for _, slice := range slices {
+		keep[slice.Name] = true
+	}
+	existingSlices, err := h.endpointSliceLister.EndpointSlices(fni.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, slice := range existingSlices {
+		if keep[slice.Name] || !metav1.IsControlledBy(slice, fni) {
+			continue
+		}
+
+		klog.Infof("Deleting EndpointSlice no longer used by: %s", fni.Name)
+		err := h.kubeclientset.DiscoveryV1().EndpointSlices(fni.Namespace).Delete(ctx, slice.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete endpointslice: %s in %s: %w", slice.Name, fni.Namespace, err)
+		}
+	}
+
+	keep = map[string]bool{}
+	for _, service := range services {
+		keep[service.Name] = true
+	}
+	existingServices, err := h.serviceLister.Services(fni.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, service := range existingServices {
+		if keep[service.Name] || !metav1.IsControlledBy(service, fni) {
+			continue
+		}
+
+		klog.Infof("Deleting Service no longer used by: %s", fni.Name)
+		err := h.kubeclientset.CoreV1().Services(fni.Namespace).Delete(ctx, service.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete service: %s in %s: %w", service.Name, fni.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// makeFunctionLabels labels the objects created for a function in another
+// namespace, so that they can be told apart from the gateway's Service
+func makeFunctionLabels(fni *faasv1.FunctionIngress, function controller.FunctionRef) map[string]string {
+	objectLabels := controller.MakeOwnerLabels(fni)
+	objectLabels[controller.FunctionLabel] = function.String()
+	return objectLabels
+}
+
+// functionSelector selects the objects created for the functions of the
+// FunctionIngress in other namespaces
+func functionSelector(fni *faasv1.FunctionIngress) (labels.Selector, error) {
+	selector := labels.SelectorFromSet(controller.MakeOwnerLabels(fni))
+	exists, err := labels.NewRequirement(controller.FunctionLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	return selector.Add(*exists), nil
+}
+
+// resolveBypass decides whether a FunctionIngress with bypassMode "auto"
+// bypasses the gateway, and returns the FunctionIngress to be rendered.
+// Requests are sent through the gateway as soon as a function has no ready
+// replicas, since only the gateway can wake it. The gateway is bypassed again
+// once every function has ready replicas and the gateway has been used for
+// the bypass hysteresis, so that the Ingress does not flap while a function
+// is scaled up and down.
+func (h SyncHandler) resolveBypass(fni, next *faasv1.FunctionIngress) *faasv1.FunctionIngress {
+	if fni.Spec.BypassMode != faasv1.BypassModeAuto {
+		controller.RemoveCondition(next, controller.ConditionGatewayBypassed)
+		return fni
+	}
+
+	current := meta.FindStatusCondition(next.Status.Conditions, controller.ConditionGatewayBypassed)
+	bypassed := current != nil && current.Status == metav1.ConditionTrue
+
+	reason, msg := h.functionsReady(fni)
+	bypass := reason == controller.ReasonFunctionReady
+	if bypass && !bypassed && current != nil {
+		if wait := h.bypassHysteresis() - time.Since(current.LastTransitionTime.Time); wait > 0 {
+			bypass = false
+			reason = controller.ReasonBypassPending
+			msg = fmt.Sprintf("Functions are ready, the gateway is bypassed once it has been used for %s", h.bypassHysteresis())
+			h.enqueueAfter(fni, wait)
+		}
+	}
+
+	status := metav1.ConditionFalse
+	if bypass {
+		status = metav1.ConditionTrue
+	}
+	if bypass != bypassed {
+		h.recorder.Event(fni, corev1.EventTypeNormal, reason, msg)
+	}
+	controller.SetCondition(next, controller.ConditionGatewayBypassed, status, reason, msg)
+
+	return controller.ResolveBypass(fni, bypass)
+}
+
+// functionsReady returns ReasonFunctionReady when every function of the
+// FunctionIngress has a ready replica, otherwise the reason and message for
+// using the gateway
+func (h SyncHandler) functionsReady(fni *faasv1.FunctionIngress) (string, string) {
+	for _, function := range controller.Functions(fni, h.config.Defaults) {
+		functionLister, ok := h.functionListerFor(function.Namespace)
+		if !ok {
+			return controller.ReasonFunctionNamespaceNotWatched,
+				fmt.Sprintf("Namespace %s is not watched, so requests are sent through the gateway", function.Namespace)
+		}
+
+		deployment, err := functionLister.deployments.Deployments(function.Namespace).Get(function.Name)
+		if err != nil {
+			return controller.ReasonFunctionNotFound,
+				fmt.Sprintf("Function %s was not found, so requests are sent through the gateway", function)
+		}
+		if deployment.Status.ReadyReplicas == 0 {
+			return controller.ReasonFunctionScaledToZero,
+				fmt.Sprintf("Function %s has no ready replicas, so requests are sent through the gateway", function)
+		}
+	}
+	return controller.ReasonFunctionReady, "Functions are ready, so the gateway is bypassed"
+}
+
+// enqueueAfter requeues the FunctionIngress once the duration has passed
+func (h SyncHandler) enqueueAfter(fni *faasv1.FunctionIngress, after time.Duration) {
+	key, err := cache.MetaNamespaceKeyFunc(fni)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	h.workqueue.AddAfter(key, after)
+}
+
+func (h SyncHandler) bypassHysteresis() time.Duration {
+	if h.config.BypassHysteresis <= 0 {
+		return controller.DefaultBypassHysteresis
+	}
+	return h.config.BypassHysteresis
+}
@@ -0,0 +1,274 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+)
+
+func newTestEndpointSlice(namespace, name, service string, addresses ...string) *discoveryv1.EndpointSlice {
+	portName, port, protocol := "http", int32(8080), corev1.ProtocolTCP
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports: []discoveryv1.EndpointPort{
+			{Name: &portName, Port: &port, Protocol: &protocol},
+		},
+	}
+	for _, address := range addresses {
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{Addresses: []string{address}})
+	}
+	return slice
+}
+
+func Test_handler_BypassToAnotherNamespaceMirrorsEndpointSlices(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.UID = "fni-uid"
+	fni.Spec.BypassGateway = true
+	fni.Spec.FunctionNamespace = "openfaas-fn"
+
+	h, kubeClient, _ := newTestHandler(t, fni)
+	deployment, service := newTestFunction("openfaas-fn", "nodeinfo")
+	service.Spec.Ports = []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 8080}}
+	withFunctions(t, &h, "openfaas-fn", deployment, service,
+		newTestEndpointSlice("openfaas-fn", "nodeinfo-abcde", "nodeinfo", "10.0.0.1", "10.0.0.2"))
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	name := "nodeinfo-nodeinfo-openfaas-fn-5f045a21"
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want ingress to be created, got: %s", err)
+	}
+	if got := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; got != name {
+		t.Errorf("want backend %s, got %s", name, got)
+	}
+
+	functionService, err := kubeClient.CoreV1().Services("openfaas").Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want service to be created, got: %s", err)
+	}
+	if functionService.Spec.Type != corev1.ServiceTypeClusterIP || len(functionService.Spec.Selector) > 0 {
+		t.Errorf("want a ClusterIP Service without a selector, got %v", functionService.Spec)
+	}
+	if !metav1.IsControlledBy(functionService, fni) {
+		t.Errorf("want service to be controlled by the FunctionIngress")
+	}
+
+	slices, _ := kubeClient.DiscoveryV1().EndpointSlices("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(slices.Items) != 1 {
+		t.Fatalf("want 1 mirrored EndpointSlice, got %d", len(slices.Items))
+	}
+	slice := slices.Items[0]
+	if slice.Labels[discoveryv1.LabelServiceName] != name || slice.Labels[discoveryv1.LabelManagedBy] != controller.EndpointSliceManager {
+		t.Errorf("want slice to be labelled for %s and managed by the operator, got %v", name, slice.Labels)
+	}
+	if len(slice.Endpoints) != 2 {
+		t.Errorf("want 2 endpoints to be mirrored, got %d", len(slice.Endpoints))
+	}
+}
+
+func Test_handler_BypassToUnwatchedNamespaceUsesExternalName(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.BypassGateway = true
+	fni.Spec.FunctionNamespace = "staging-fn"
+
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	service, err := kubeClient.CoreV1().Services("openfaas").Get(context.Background(), "nodeinfo-nodeinfo-staging-fn-74622923", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want service to be created, got: %s", err)
+	}
+	want := "nodeinfo.staging-fn.svc.cluster.local"
+	if service.Spec.Type != corev1.ServiceTypeExternalName || service.Spec.ExternalName != want {
+		t.Errorf("want ExternalName %s, got %s %s", want, service.Spec.Type, service.Spec.ExternalName)
+	}
+
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("want %s to be True, got %v", controller.ConditionReady, c)
+	}
+}
+
+func Test_handler_BypassDeletesStaleFunctionServices(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.UID = "fni-uid"
+	fni.Spec.BypassGateway = true
+
+	stale := makeFunctionLabels(fni, controller.FunctionRef{Name: "nodeinfo", Namespace: "openfaas-fn"})
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "nodeinfo-nodeinfo-openfaas-fn",
+			Namespace:       "openfaas",
+			Labels:          stale,
+			OwnerReferences: controller.MakeOwnerRef(fni),
+		},
+	}
+	slice := newTestEndpointSlice("openfaas", "nodeinfo-nodeinfo-openfaas-fn-abcde", "nodeinfo-nodeinfo-openfaas-fn")
+	for k, v := range stale {
+		slice.Labels[k] = v
+	}
+	slice.OwnerReferences = controller.MakeOwnerRef(fni)
+
+	// The function is now in the namespace of the FunctionIngress
+	h, kubeClient, _ := newTestHandler(t, fni, service, slice)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	services, _ := kubeClient.CoreV1().Services("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(services.Items) != 0 {
+		t.Errorf("want stale service to be deleted, got %d services", len(services.Items))
+	}
+	slices, _ := kubeClient.DiscoveryV1().EndpointSlices("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(slices.Items) != 0 {
+		t.Errorf("want stale EndpointSlice to be deleted, got %d", len(slices.Items))
+	}
+}
+
+func Test_handler_AutoBypassUsesGatewayWhileScaledToZero(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.BypassMode = faasv1.BypassModeAuto
+
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+	deployment, service := newTestFunction("openfaas-fn", "nodeinfo")
+	withFunctions(t, &h, "openfaas-fn", deployment, service)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want ingress to be created, got: %s", err)
+	}
+	if got := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; got != "gateway" {
+		t.Errorf("want backend gateway, got %s", got)
+	}
+	if got := ingress.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]; got != "/function/nodeinfo/$1" {
+		t.Errorf("want the path to be rewritten for the gateway, got %q", got)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionGatewayBypassed)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonFunctionScaledToZero {
+		t.Errorf("want %s to be False with %s, got %v", controller.ConditionGatewayBypassed, controller.ReasonFunctionScaledToZero, c)
+	}
+}
+
+func Test_handler_AutoBypassesGatewayWhenReady(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.BypassMode = faasv1.BypassModeAuto
+
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+	deployment, service := newTestFunction("openfaas-fn", "nodeinfo")
+	deployment.Status.ReadyReplicas = 1
+	withFunctions(t, &h, "openfaas-fn", deployment, service)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want ingress to be created, got: %s", err)
+	}
+	if got := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; got != "nodeinfo-nodeinfo-openfaas-fn-5f045a21" {
+		t.Errorf("want backend nodeinfo-nodeinfo-openfaas-fn-5f045a21, got %s", got)
+	}
+	if got, ok := ingress.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]; ok {
+		t.Errorf("want no rewrite when the gateway is bypassed, got %q", got)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionGatewayBypassed)
+	if c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("want %s to be True, got %v", controller.ConditionGatewayBypassed, c)
+	}
+}
+
+func Test_handler_AutoBypassWaitsForHysteresis(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.BypassMode = faasv1.BypassModeAuto
+	controller.SetCondition(fni, controller.ConditionGatewayBypassed, metav1.ConditionFalse, controller.ReasonFunctionScaledToZero, "")
+
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+	// The LastTransitionTime of a stored condition only keeps whole seconds
+	h.config.BypassHysteresis = 2 * time.Second
+	deployment, service := newTestFunction("openfaas-fn", "nodeinfo")
+	deployment.Status.ReadyReplicas = 1
+	withFunctions(t, &h, "openfaas-fn", deployment, service)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want ingress to be created, got: %s", err)
+	}
+	if got := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; got != "gateway" {
+		t.Errorf("want backend gateway until the hysteresis has passed, got %s", got)
+	}
+	c := getStatusCondition(t, faasClient, controller.ConditionGatewayBypassed)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonBypassPending {
+		t.Errorf("want %s to be False with %s, got %v", controller.ConditionGatewayBypassed, controller.ReasonBypassPending, c)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for h.workqueue.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if h.workqueue.Len() != 1 {
+		t.Errorf("want the FunctionIngress to be requeued after the hysteresis")
+	}
+}
+
+func Test_handler_AutoBypassRemovesGatewayAnnotations(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.BypassMode = faasv1.BypassModeAuto
+
+	// The Ingress rendered while the function was scaled to zero
+	gatewayMode := controller.ResolveBypass(fni, false)
+	live := makeIngress(gatewayMode, controller.ResolveIngressClass(gatewayMode, nil), testGateway)
+	if _, ok := live.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]; !ok {
+		t.Fatalf("want the gateway-mode Ingress to rewrite the path")
+	}
+	live.Annotations["example.com/owner"] = "team-a"
+
+	h, kubeClient, _ := newTestHandler(t, fni, live)
+	deployment, service := newTestFunction("openfaas-fn", "nodeinfo")
+	deployment.Status.ReadyReplicas = 1
+	withFunctions(t, &h, "openfaas-fn", deployment, service)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get ingress: %s", err)
+	}
+	if got := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; got == "gateway" {
+		t.Errorf("want the gateway to be bypassed, got backend %s", got)
+	}
+	if got, ok := ingress.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]; ok {
+		t.Errorf("want the rewrite-target to be removed, got %q", got)
+	}
+	if got := ingress.Annotations["example.com/owner"]; got != "team-a" {
+		t.Errorf("want annotations set by others to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// syncCertificate converges the cert-manager Certificate of a FunctionIngress
+// which sets tls.certificate, and deletes it once it is no longer wanted.
+// TLS is terminated by the Gateway for the gateway-api IngressType, so no
+// Certificate is created for it.
+func (h SyncHandler) syncCertificate(ctx context.Context, fni, next *faasv1.FunctionIngress, class string) error {
+	if !fni.Spec.ManagedCertificate() || class == controller.GatewayAPIClass {
+		return h.deleteOwnedObject(ctx, fni, controller.CertificateResource, h.certificateLister, fni.Name)
+	}
+
+	_, err := h.applyObject(ctx, fni, next, controller.CertificateResource, h.certificateLister, makeCertificate(fni))
+	return err
+}
+
+// makeCertificate renders the desired cert-manager Certificate for the
+// FunctionIngress, it writes to the same secret as the Ingress reads.
+func makeCertificate(fni *faasv1.FunctionIngress) *unstructured.Unstructured {
+	tls := fni.Spec.TLS
+
+	dnsNames := []interface{}{}
+	for _, host := range fni.Spec.Hosts() {
+		dnsNames = append(dnsNames, host)
+	}
+
+	issuerKind := "Issuer"
+	if tls.IssuerRef.Kind == "ClusterIssuer" {
+		issuerKind = "ClusterIssuer"
+	}
+
+	spec := map[string]interface{}{
+		"secretName": tlsSecretName(fni),
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]interface{}{
+			"group": controller.CertificateResource.Group,
+			"kind":  issuerKind,
+			"name":  tls.IssuerRef.Name,
+		},
+	}
+
+	options := tls.Certificate
+	if options.Duration != nil {
+		spec["duration"] = options.Duration.Duration.String()
+	}
+	if options.RenewBefore != nil {
+		spec["renewBefore"] = options.RenewBefore.Duration.String()
+	}
+	if options.PrivateKey != nil && len(options.PrivateKey.RotationPolicy) > 0 {
+		spec["privateKey"] = map[string]interface{}{
+			"rotationPolicy": options.PrivateKey.RotationPolicy,
+		}
+	}
+	if len(options.Usages) > 0 {
+		usages := []interface{}{}
+		for _, usage := range options.Usages {
+			usages = append(usages, usage)
+		}
+		spec["usages"] = usages
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetAPIVersion(controller.CertificateResource.GroupVersion().String())
+	certificate.SetKind("Certificate")
+	certificate.SetName(fni.Name)
+	certificate.SetNamespace(fni.Namespace)
+	certificate.SetOwnerReferences(controller.MakeOwnerRef(fni))
+	certificate.SetLabels(controller.MakeOwnerLabels(fni))
+	certificate.Object["spec"] = spec
+
+	return certificate
+}
+
+// mirrorCertificateReady copies the Ready condition of the Certificate to
+// CertificateReady, unless it is True, when the checks of the certificate in
+// the secret are kept instead.
+func (h SyncHandler) mirrorCertificateReady(fni *faasv1.FunctionIngress) {
+	if h.certificateLister == nil {
+		return
+	}
+
+	obj, err := h.certificateLister.ByNamespace(fni.Namespace).Get(fni.Name)
+	if errors.IsNotFound(err) {
+		controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+			controller.ReasonCertificatePending, fmt.Sprintf("Waiting for Certificate %s to be created", fni.Name))
+		return
+	} else if err != nil {
+		return
+	}
+
+	certificate, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	ready := certificateCondition(certificate, "Ready")
+	if ready == nil {
+		controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+			controller.ReasonCertificatePending, fmt.Sprintf("Waiting for Certificate %s to become ready", fni.Name))
+		return
+	}
+
+	if ready.Status == metav1.ConditionTrue {
+		return
+	}
+
+	reason := ready.Reason
+	if len(reason) == 0 {
+		reason = controller.ReasonCertificatePending
+	}
+	controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+		reason, fmt.Sprintf("Certificate %s is not ready: %s", fni.Name, ready.Message))
+}
+
+// certificateCondition returns a condition from the status of a
+// cert-manager Certificate, or nil when it has not been reported.
+func certificateCondition(certificate *unstructured.Unstructured, conditionType string) *metav1.Condition {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+
+		status, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		message, _ := condition["message"].(string)
+		return &metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionStatus(status),
+			Reason:  reason,
+			Message: message,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package v1
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newTestCertificateSecret returns a TLS secret with a self-signed
+// certificate for the hosts, which expires after validFor.
+func newTestCertificateSecret(t *testing.T, name string, hosts []string, validFor time.Duration) *corev1.Secret {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %s", err)
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openfaas"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		},
+	}
+}
+
+func newTestExistingSecretFunctionIngress() *faasv1.FunctionIngress {
+	fni := newTestFunctionIngress()
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{
+		Mode:       faasv1.TLSModeExistingSecret,
+		SecretName: "corporate-cert",
+	}
+	return fni
+}
+
+func Test_makeTLS_SecretName(t *testing.T) {
+	fni := newTestExistingSecretFunctionIngress()
+
+	tls := makeTLS(fni)
+	if len(tls) != 1 || tls[0].SecretName != "corporate-cert" {
+		t.Errorf("want TLS with secret corporate-cert, got %v", tls)
+	}
+}
+
+func Test_makeTLS_ModeNone(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{
+		Enabled: true,
+		Mode:    faasv1.TLSModeNone,
+	}
+
+	if tls := makeTLS(fni); len(tls) != 0 {
+		t.Errorf("want no TLS for mode %s, got %v", faasv1.TLSModeNone, tls)
+	}
+}
+
+func Test_makeIngress_ExistingSecretHasNoIssuer(t *testing.T) {
+	fni := newTestExistingSecretFunctionIngress()
+	fni.Spec.TLS.IssuerRef = faasv1.ObjectReference{Name: "letsencrypt"}
+
+	ingress := makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway)
+	for _, annotation := range []string{"cert-manager.io/issuer", "cert-manager.io/cluster-issuer"} {
+		if _, ok := ingress.Annotations[annotation]; ok {
+			t.Errorf("want no %s annotation for an existing secret", annotation)
+		}
+	}
+}
+
+func Test_handler_ExistingSecretMissing(t *testing.T) {
+	fni := newTestExistingSecretFunctionIngress()
+
+	h, _, faasClient := newTestHandler(t, fni)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c := getStatusCondition(t, faasClient, controller.ConditionTLSSecretMissing); c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("want %s to be True, got %v", controller.ConditionTLSSecretMissing, c)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonTLSSecretMissing {
+		t.Errorf("want %s to be False with reason %s, got %v", controller.ConditionReady, controller.ReasonTLSSecretMissing, c)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionCertificateExpiringSoon); c != nil {
+		t.Errorf("want no %s condition without a certificate, got %v", controller.ConditionCertificateExpiringSoon, c)
+	}
+}
+
+func Test_handler_ExistingSecretConditions(t *testing.T) {
+	cases := []struct {
+		name         string
+		hosts        []string
+		validFor     time.Duration
+		wantReady    metav1.ConditionStatus
+		wantReason   string
+		wantExpiring metav1.ConditionStatus
+	}{
+		{
+			name:         "valid certificate for the domain",
+			hosts:        []string{"nodeinfo.example.com"},
+			validFor:     90 * 24 * time.Hour,
+			wantReady:    metav1.ConditionTrue,
+			wantReason:   controller.ReasonCertificateIssued,
+			wantExpiring: metav1.ConditionFalse,
+		},
+		{
+			name:         "wildcard certificate covers the domain",
+			hosts:        []string{"*.example.com"},
+			validFor:     90 * 24 * time.Hour,
+			wantReady:    metav1.ConditionTrue,
+			wantReason:   controller.ReasonCertificateIssued,
+			wantExpiring: metav1.ConditionFalse,
+		},
+		{
+			name:         "certificate expiring soon is still ready",
+			hosts:        []string{"nodeinfo.example.com"},
+			validFor:     7 * 24 * time.Hour,
+			wantReady:    metav1.ConditionTrue,
+			wantReason:   controller.ReasonCertificateIssued,
+			wantExpiring: metav1.ConditionTrue,
+		},
+		{
+			name:         "expired certificate",
+			hosts:        []string{"nodeinfo.example.com"},
+			validFor:     -time.Minute,
+			wantReady:    metav1.ConditionFalse,
+			wantReason:   controller.ReasonCertificateExpired,
+			wantExpiring: metav1.ConditionTrue,
+		},
+		{
+			name:         "certificate for another domain",
+			hosts:        []string{"other.example.org"},
+			validFor:     90 * 24 * time.Hour,
+			wantReady:    metav1.ConditionFalse,
+			wantReason:   controller.ReasonCertificateHostMismatch,
+			wantExpiring: metav1.ConditionFalse,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := newTestExistingSecretFunctionIngress()
+			secret := newTestCertificateSecret(t, "corporate-cert", tc.hosts, tc.validFor)
+
+			h, _, faasClient := newTestHandler(t, fni, secret)
+			if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if c := getStatusCondition(t, faasClient, controller.ConditionTLSSecretMissing); c == nil || c.Status != metav1.ConditionFalse {
+				t.Errorf("want %s to be False, got %v", controller.ConditionTLSSecretMissing, c)
+			}
+			c := getStatusCondition(t, faasClient, controller.ConditionCertificateReady)
+			if c == nil || c.Status != tc.wantReady || c.Reason != tc.wantReason {
+				t.Errorf("want %s to be %s with reason %s, got %v", controller.ConditionCertificateReady, tc.wantReady, tc.wantReason, c)
+			}
+			if c := getStatusCondition(t, faasClient, controller.ConditionCertificateExpiringSoon); c == nil || c.Status != tc.wantExpiring {
+				t.Errorf("want %s to be %s, got %v", controller.ConditionCertificateExpiringSoon, tc.wantExpiring, c)
+			}
+		})
+	}
+}
+
+func Test_handler_InvalidCertificate(t *testing.T) {
+	fni := newTestExistingSecretFunctionIngress()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "corporate-cert", Namespace: "openfaas"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("not a certificate")},
+	}
+
+	h, _, faasClient := newTestHandler(t, fni, secret)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionCertificateReady)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonCertificateInvalid {
+		t.Errorf("want %s to be False with reason %s, got %v", controller.ConditionCertificateReady, controller.ReasonCertificateInvalid, c)
+	}
+}
+
+func newTestManagedCertificateFunctionIngress() *faasv1.FunctionIngress {
+	fni := newTestFunctionIngress()
+	fni.Spec.Domains = []faasv1.Domain{"www.nodeinfo.example.com"}
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{
+		Enabled:   true,
+		IssuerRef: faasv1.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+		Certificate: &faasv1.CertificateSpec{
+			Duration:    &metav1.Duration{Duration: 90 * 24 * time.Hour},
+			RenewBefore: &metav1.Duration{Duration: 15 * 24 * time.Hour},
+			PrivateKey:  &faasv1.CertificatePrivateKey{RotationPolicy: "Always"},
+			Usages:      []string{"digital signature", "key encipherment", "server auth"},
+		},
+	}
+	return fni
+}
+
+// withCertificateReady returns the Certificate with a Ready condition in its
+// status, as reported by cert-manager
+func withCertificateReady(certificate *unstructured.Unstructured, status, reason, message string) *unstructured.Unstructured {
+	certificate.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{
+				"type":    "Ready",
+				"status":  status,
+				"reason":  reason,
+				"message": message,
+			},
+		},
+	}
+	return certificate
+}
+
+func Test_makeCertificate(t *testing.T) {
+	fni := newTestManagedCertificateFunctionIngress()
+
+	certificate := makeCertificate(fni)
+	if !metav1.IsControlledBy(certificate, fni) {
+		t.Errorf("want the Certificate to be owned by the FunctionIngress")
+	}
+
+	spec := certificate.Object["spec"].(map[string]interface{})
+	want := map[string]interface{}{
+		"secretName": "nodeinfo.example.com-cert",
+		"dnsNames":   []interface{}{"nodeinfo.example.com", "www.nodeinfo.example.com"},
+		"issuerRef": map[string]interface{}{
+			"group": "cert-manager.io",
+			"kind":  "ClusterIssuer",
+			"name":  "letsencrypt",
+		},
+		"duration":    "2160h0m0s",
+		"renewBefore": "360h0m0s",
+		"privateKey": map[string]interface{}{
+			"rotationPolicy": "Always",
+		},
+		"usages": []interface{}{"digital signature", "key encipherment", "server auth"},
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Errorf("want spec %v, got %v", want, spec)
+	}
+}
+
+func Test_makeIngress_ManagedCertificateHasNoIssuer(t *testing.T) {
+	fni := newTestManagedCertificateFunctionIngress()
+
+	ingress := makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway)
+	if _, ok := ingress.Annotations["cert-manager.io/cluster-issuer"]; ok {
+		t.Errorf("want no issuer annotation when the operator creates the Certificate")
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "nodeinfo.example.com-cert" {
+		t.Errorf("want TLS with the secret of the Certificate, got %v", ingress.Spec.TLS)
+	}
+}
+
+func Test_handler_CreatesCertificate(t *testing.T) {
+	fni := newTestManagedCertificateFunctionIngress()
+
+	h, dynamicClient, faasClient := newTestDynamicHandler(t, nil, fni)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := dynamicClient.Resource(controller.CertificateResource).Namespace("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{}); err != nil {
+		t.Fatalf("want a Certificate, got: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionCertificateReady)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonCertificatePending {
+		t.Errorf("want %s to be pending, got %v", controller.ConditionCertificateReady, c)
+	}
+}
+
+func Test_handler_MirrorsCertificateReady(t *testing.T) {
+	cases := []struct {
+		name       string
+		status     string
+		reason     string
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "failed Certificate",
+			status:     "False",
+			reason:     "Failed",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Failed",
+		},
+		{
+			name:       "ready Certificate uses the checks of the secret",
+			status:     "True",
+			reason:     "Ready",
+			wantStatus: metav1.ConditionTrue,
+			wantReason: controller.ReasonCertificateIssued,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := newTestManagedCertificateFunctionIngress()
+			certificate := withCertificateReady(makeCertificate(fni), tc.status, tc.reason, "issuer says so")
+			secret := newTestCertificateSecret(t, "nodeinfo.example.com-cert", fni.Spec.Hosts(), 90*24*time.Hour)
+
+			h, _, faasClient := newTestDynamicHandler(t, []*unstructured.Unstructured{certificate}, fni, secret)
+			if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			c := getStatusCondition(t, faasClient, controller.ConditionCertificateReady)
+			if c == nil || c.Status != tc.wantStatus || c.Reason != tc.wantReason {
+				t.Errorf("want %s to be %s with reason %s, got %v", controller.ConditionCertificateReady, tc.wantStatus, tc.wantReason, c)
+			}
+		})
+	}
+}
+
+func Test_handler_DeletesCertificateWhenNotManaged(t *testing.T) {
+	fni := newTestManagedCertificateFunctionIngress()
+	certificate := makeCertificate(fni)
+	fni.Spec.TLS.Certificate = nil
+
+	h, dynamicClient, _ := newTestDynamicHandler(t, []*unstructured.Unstructured{certificate}, fni)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err := dynamicClient.Resource(controller.CertificateResource).Namespace("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("want the Certificate to be deleted, got: %v", err)
+	}
+}
+
+func Test_handler_CertificateUnsupported(t *testing.T) {
+	fni := newTestManagedCertificateFunctionIngress()
+
+	h, _, faasClient := newTestHandler(t, fni)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionDegraded)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonCertificateUnsupported {
+		t.Errorf("want Degraded with reason %s, got %v", controller.ReasonCertificateUnsupported, c)
+	}
+}
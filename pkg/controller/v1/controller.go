@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	pkgerrors "github.com/pkg/errors"
 
@@ -14,12 +16,19 @@ import (
 	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
 	"github.com/openfaas/ingress-operator/pkg/controller"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
 	networkingv1 "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -32,10 +41,52 @@ type SyncHandler struct {
 	// kubeclientset is a standard kubernetes clientset
 	kubeclientset kubernetes.Interface
 
+	// faasclientset is used to write the status of FunctionIngresses
+	faasclientset clientset.Interface
+
 	functionsLister listers.FunctionIngressLister
 
 	ingressLister networkingv1.IngressLister
 
+	// ingressClassLister is used to find the default IngressClass and the
+	// controller of each class
+	ingressClassLister networkingv1.IngressClassLister
+
+	// serviceLister is used to find the ExternalName Service for a gateway
+	// in another namespace
+	serviceLister corelisters.ServiceLister
+
+	// endpointSliceLister is used to find the EndpointSlices mirrored from
+	// functions in another namespace when the gateway is bypassed
+	endpointSliceLister discoverylisters.EndpointSliceLister
+
+	// secretLister is used to check whether the TLS certificate has been issued
+	secretLister corelisters.SecretLister
+
+	// dynamicclientset writes HTTPRoutes, which have no typed clientset
+	dynamicclientset dynamic.Interface
+
+	// httpRouteLister is nil when the HTTPRoute CRD is not installed
+	httpRouteLister cache.GenericLister
+
+	// ingressRouteLister and middlewareLister are nil when the Traefik CRDs
+	// are not installed
+	ingressRouteLister cache.GenericLister
+	middlewareLister   cache.GenericLister
+
+	// certificateLister is nil when the cert-manager CRDs are not installed
+	certificateLister cache.GenericLister
+
+	// functionListers are keyed by the function namespaces which are
+	// watched, see functionListerFor
+	functionListers map[string]functionLister
+
+	config controller.Config
+
+	// workqueue requeues a FunctionIngress which waits to bypass the
+	// gateway, see resolveBypass
+	workqueue workqueue.RateLimitingInterface
+
 	// recorder is an event recorder for recording Event resources to the
 	// Kubernetes API.
 	recorder record.EventRecorder
@@ -47,35 +98,215 @@ func NewController(
 	faasclientset clientset.Interface,
 	kubeInformerFactory kubeinformers.SharedInformerFactory,
 	functionIngressFactory informers.SharedInformerFactory,
+	dynamicclientset dynamic.Interface,
+	dynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory,
+	functionInformerFactories map[string]kubeinformers.SharedInformerFactory,
+	namespace string,
+	config controller.Config,
 ) controller.BaseController {
 
 	recorder := controller.EventRecorder(kubeclientset)
 	functionIngress := functionIngressFactory.Openfaas().V1().FunctionIngresses()
 	ingressInformer := kubeInformerFactory.Networking().V1().Ingresses()
 	ingressLister := ingressInformer.Lister()
+	secretInformer := kubeInformerFactory.Core().V1().Secrets()
+	ingressClassInformer := kubeInformerFactory.Networking().V1().IngressClasses()
+	serviceInformer := kubeInformerFactory.Core().V1().Services()
+	endpointSliceInformer := kubeInformerFactory.Discovery().V1().EndpointSlices()
+	functionListers, functionInformers := newFunctionListers(functionInformerFactories)
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), queueName(namespace))
 
 	syncer := SyncHandler{
-		kubeclientset:   kubeclientset,
-		functionsLister: functionIngress.Lister(),
-		ingressLister:   ingressLister,
-		recorder:        recorder,
+		kubeclientset:       kubeclientset,
+		faasclientset:       faasclientset,
+		functionsLister:     functionIngress.Lister(),
+		ingressLister:       ingressLister,
+		ingressClassLister:  ingressClassInformer.Lister(),
+		serviceLister:       serviceInformer.Lister(),
+		endpointSliceLister: endpointSliceInformer.Lister(),
+		secretLister:        secretInformer.Lister(),
+		functionListers:     functionListers,
+		workqueue:           queue,
+		recorder:            recorder,
+
+		dynamicclientset: dynamicclientset,
+		config:           config,
+	}
+
+	cachesSynced := []cache.InformerSynced{
+		ingressInformer.Informer().HasSynced,
+		secretInformer.Informer().HasSynced,
+		ingressClassInformer.Informer().HasSynced,
+		serviceInformer.Informer().HasSynced,
+		endpointSliceInformer.Informer().HasSynced,
+	}
+
+	dynamicInformers := []cache.SharedIndexInformer{}
+	if config.HTTPRouteEnabled {
+		httpRoutes := dynamicInformerFactory.ForResource(controller.HTTPRouteResource)
+		syncer.httpRouteLister = httpRoutes.Lister()
+		dynamicInformers = append(dynamicInformers, httpRoutes.Informer())
+	}
+	if config.TraefikCRDEnabled {
+		ingressRoutes := dynamicInformerFactory.ForResource(controller.IngressRouteResource)
+		syncer.ingressRouteLister = ingressRoutes.Lister()
+		middlewares := dynamicInformerFactory.ForResource(controller.MiddlewareResource)
+		syncer.middlewareLister = middlewares.Lister()
+		dynamicInformers = append(dynamicInformers, ingressRoutes.Informer(), middlewares.Informer())
+	}
+	if config.CertificateEnabled {
+		certificates := dynamicInformerFactory.ForResource(controller.CertificateResource)
+		syncer.certificateLister = certificates.Lister()
+		dynamicInformers = append(dynamicInformers, certificates.Informer())
+	}
+	for _, informer := range append(dynamicInformers, functionInformers.all()...) {
+		cachesSynced = append(cachesSynced, informer.HasSynced)
 	}
 
 	ctrl := controller.BaseController{
 		FunctionsLister: functionIngress.Lister(),
 		FunctionsSynced: functionIngress.Informer().HasSynced,
-		Workqueue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "FunctionIngresses"),
+		CachesSynced:    cachesSynced,
+		Workqueue:       queue,
 		SyncHandler:     syncer.handler,
+		Health:          controller.NewHealth(),
 	}
 	klog.Info("Setting up event handlers")
 	ctrl.SetupEventHandlers(functionIngress, kubeInformerFactory)
+	// Reconcile the owning FunctionIngress whenever its Ingress is edited or
+	// deleted, so that changes made outside the operator are reverted.
 	ingressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			oldIngress, ok := old.(*netv1.Ingress)
+			if !ok {
+				return
+			}
+			newIngress, ok := new.(*netv1.Ingress)
+			if !ok {
+				return
+			}
+			// Periodic resync will send update events for all known Ingresses.
+			if oldIngress.ResourceVersion == newIngress.ResourceVersion {
+				return
+			}
+			ctrl.HandleObject(new)
+		},
 		DeleteFunc: ctrl.HandleObject,
 	})
 
+	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			oldService, ok := old.(*corev1.Service)
+			if !ok {
+				return
+			}
+			newService, ok := new.(*corev1.Service)
+			if !ok || oldService.ResourceVersion == newService.ResourceVersion {
+				return
+			}
+			ctrl.HandleObject(new)
+		},
+		DeleteFunc: ctrl.HandleObject,
+	})
+
+	endpointSliceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			oldSlice, ok := old.(*discoveryv1.EndpointSlice)
+			if !ok {
+				return
+			}
+			newSlice, ok := new.(*discoveryv1.EndpointSlice)
+			if !ok || oldSlice.ResourceVersion == newSlice.ResourceVersion {
+				return
+			}
+			ctrl.HandleObject(new)
+		},
+		DeleteFunc: ctrl.HandleObject,
+	})
+
+	for _, informer := range dynamicInformers {
+		informer.AddEventHandler(unstructuredEventHandler(ctrl))
+	}
+
+	// The informers of the function namespaces are shared by the controller
+	// of each watched namespace, each one requeues its own FunctionIngresses.
+	for _, informer := range append(functionInformers.deployments, functionInformers.services...) {
+		informer.AddEventHandler(functionEventHandler(ctrl, functionIngress.Lister(), config.Defaults))
+	}
+	for _, informer := range functionInformers.endpointSlices {
+		informer.AddEventHandler(endpointSliceEventHandler(ctrl, functionIngress.Lister(), config.Defaults))
+	}
+
+	// TLS secrets are owned by cert-manager, or managed outside of the
+	// cluster, rather than by the FunctionIngress, so look up the
+	// FunctionIngress by its secret name instead.
+	enqueueForSecret := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		secret, ok := obj.(*corev1.Secret)
+		if !ok {
+			return
+		}
+		fnis, err := functionIngress.Lister().FunctionIngresses(secret.Namespace).List(labels.Everything())
+		if err != nil {
+			runtime.HandleError(err)
+			return
+		}
+		for _, fni := range fnis {
+			if fni.Spec.UseTLS() && tlsSecretName(fni) == secret.Name {
+				ctrl.EnqueueFunction(fni)
+			}
+		}
+	}
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueueForSecret,
+		UpdateFunc: func(old, new interface{}) {
+			enqueueForSecret(new)
+		},
+		DeleteFunc: enqueueForSecret,
+	})
+
+	// A new default IngressClass, or a change to the controller of a class,
+	// can change the class and annotations of any Ingress.
+	enqueueAll := func(obj interface{}) {
+		fnis, err := functionIngress.Lister().List(labels.Everything())
+		if err != nil {
+			runtime.HandleError(err)
+			return
+		}
+		for _, fni := range fnis {
+			ctrl.EnqueueFunction(fni)
+		}
+	}
+	ingressClassInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueueAll,
+		UpdateFunc: func(old, new interface{}) {
+			oldClass, ok := old.(*netv1.IngressClass)
+			if !ok {
+				return
+			}
+			newClass, ok := new.(*netv1.IngressClass)
+			if !ok || oldClass.ResourceVersion == newClass.ResourceVersion {
+				return
+			}
+			enqueueAll(new)
+		},
+		DeleteFunc: enqueueAll,
+	})
+
 	return ctrl
 }
 
+// queueName names the workqueue of the controller for a namespace, so that
+// the workqueue metrics of each controller are reported separately.
+func queueName(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return "FunctionIngresses"
+	}
+	return "FunctionIngresses-" + namespace
+}
+
 // handler compares the actual state with the desired, and attempts to
 // converge the two. It then updates the Status block of the fni resource
 // with the current status of the resource.
@@ -99,19 +330,95 @@ func (h SyncHandler) handler(ctx context.Context, key string) error {
 		return err
 	}
 
+	if fni.DeletionTimestamp != nil {
+		return h.finalize(ctx, fni)
+	}
+
+	// The finalizer is added before any object is created, so that nothing
+	// is left behind when the FunctionIngress is deleted
+	fni, err = h.ensureFinalizer(ctx, fni)
+	if err != nil {
+		return err
+	}
+
+	// The mutating webhook stores the defaults, they are applied here too
+	// for FunctionIngresses which were stored without it
+	fni = fni.DeepCopy()
+	controller.SetDefaults(fni, h.config.Defaults, h.ingressClassLister)
+
 	fniName := fni.ObjectMeta.Name
 	// klog.Infof("FunctionIngress name: %v", fniName)
 
+	// Conditions are set on a copy, so that the informer's cache is never
+	// mutated and the status is only written when it changes.
+	next := fni.DeepCopy()
+	next.Status.TLSMode = fni.Spec.TLSMode()
+
 	ingresses := h.ingressLister.Ingresses(namespace)
 	ingress, getIngressErr := ingresses.Get(fni.Name)
 	createIngress := errors.IsNotFound(getIngressErr)
-	if !createIngress && ingress == nil {
-		klog.Errorf("cannot get ingress: %s in %s, error: %s", fni.Name, namespace, getIngressErr.Error())
+	if !createIngress && getIngressErr != nil {
+		return pkgerrors.Wrapf(getIngressErr, "cannot get ingress: %s in %s", fni.Name, namespace)
 	}
 
-	if fni.Spec.BypassGateway && fni.Spec.FunctionNamespace != fni.Namespace {
-		klog.Errorf("gateway bypass can not have diffeent function ingress namespace and function namespace")
-		return nil
+	ingressClass := controller.ResolveIngressClass(fni, h.ingressClassLister)
+
+	// Validate also rejects these specs, they are checked first so that a
+	// class which can not match the paths as pathMatch asks has its own reason
+	if err := controller.ValidatePathMatch(fni, ingressClass.Dialect); err != nil {
+		msg := err.Error()
+		klog.Errorf("unsupported pathMatch for %s: %s", key, msg)
+		h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonPathMatchUnsupported, msg)
+		controller.SetDegraded(next, controller.ReasonPathMatchUnsupported, msg)
+		return h.updateStatus(ctx, fni, next)
+	}
+
+	// The validating webhook rejects the same specs, this catches those
+	// created before it was installed or while it was unavailable
+	if errs := controller.Validate(fni, ingressClass.Dialect); len(errs) > 0 {
+		msg := errs.ToAggregate().Error()
+		klog.Errorf("invalid spec for %s: %s", key, msg)
+		h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonInvalidSpec, msg)
+		controller.SetDegraded(next, controller.ReasonInvalidSpec, msg)
+		return h.updateStatus(ctx, fni, next)
+	}
+
+	if fni.Spec.ManagedCertificate() && h.certificateLister == nil {
+		msg := fmt.Sprintf("tls.certificate requires the %s CRD to be installed", controller.CertificateResource.GroupResource())
+		h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonCertificateUnsupported, msg)
+		controller.SetDegraded(next, controller.ReasonCertificateUnsupported, msg)
+		return h.updateStatus(ctx, fni, next)
+	}
+
+	h.setFunctionCondition(next)
+
+	gateway := controller.ResolveGateway(fni, h.config.Gateway)
+	if err := h.syncGatewayService(ctx, fni, next, gateway); err != nil {
+		return err
+	}
+	if err := h.syncFunctionServices(ctx, fni, next); err != nil {
+		return err
+	}
+
+	// The Services for both the gateway and the functions are kept for
+	// "auto", everything else is rendered as if it was true or false
+	fni = h.resolveBypass(fni, next)
+
+	class := controller.GetClass(fni.Spec.IngressType)
+	if err := h.syncCertificate(ctx, fni, next, class); err != nil {
+		return err
+	}
+
+	switch class {
+	case controller.GatewayAPIClass:
+		return h.syncHTTPRoute(ctx, fni, next, gateway)
+	case controller.TraefikCRDClass:
+		return h.syncIngressRoute(ctx, fni, next, gateway)
+	default:
+		// The FunctionIngress may have been switched from another IngressType
+		if err := h.deleteStaleObjects(ctx, fni, class); err != nil {
+			return err
+		}
 	}
 
 	// klog.Info("fni.Spec.UseTLS() ", fni.Spec.UseTLS())
@@ -120,65 +427,105 @@ func (h SyncHandler) handler(ctx context.Context, key string) error {
 	if createIngress {
 		klog.Infof("Creating Ingress for: %v", fniName)
 
-		rules := makeRules(fni)
-		tls := makeTLS(fni)
-
-		newIngress := netv1.Ingress{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:            name,
-				Namespace:       namespace,
-				Annotations:     controller.MakeAnnotations(fni),
-				OwnerReferences: controller.MakeOwnerRef(fni),
-			},
-			Spec: netv1.IngressSpec{
-				Rules: rules,
-				TLS:   tls,
-			},
-		}
+		newIngress := makeIngress(fni, ingressClass, gateway)
 
-		_, createErr := h.kubeclientset.NetworkingV1().Ingresses(namespace).Create(ctx, &newIngress, metav1.CreateOptions{})
+		_, createErr := h.kubeclientset.NetworkingV1().Ingresses(namespace).Create(ctx, newIngress, metav1.CreateOptions{})
 		if createErr != nil {
 			klog.Errorf("cannot create ingress: %v in %v, error: %v", name, namespace, createErr.Error())
+			return h.syncFailed(ctx, fni, next, controller.ErrIngressCreate,
+				fmt.Sprintf(controller.MessageIngressCreate, name, createErr.Error()), createErr)
 		}
 
+		controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+			controller.ReasonIngressCreated, fmt.Sprintf("Ingress %s created", name))
+		h.setCertificateCondition(next)
+		controller.SetReady(next)
+
 		h.recorder.Event(fni, corev1.EventTypeNormal, controller.SuccessSynced, controller.MessageResourceSynced)
-		return nil
+		return h.updateStatus(ctx, fni, next)
+	}
+
+	// Only update an Ingress which this FunctionIngress controls, unless it
+	// has no controller and the FunctionIngress opts into adopting it.
+	adopt := false
+	if !metav1.IsControlledBy(ingress, fni) {
+		if metav1.GetControllerOf(ingress) != nil || !controller.CanAdopt(fni) {
+			msg := fmt.Sprintf(controller.MessageResourceExists, ingress.Name)
+			klog.Errorf("%s in %s", msg, namespace)
+			return h.syncFailed(ctx, fni, next, controller.ErrResourceExists, msg, pkgerrors.New(msg))
+		}
+		adopt = true
 	}
 
 	old := faasv1.FunctionIngress{}
 
-	if val, ok := ingress.Annotations["com.openfaas.spec"]; ok && len(val) > 0 {
+	if val, ok := ingress.Annotations[controller.SpecAnnotation]; ok && len(val) > 0 {
 		unmarshalErr := json.Unmarshal([]byte(val), &old)
 		if unmarshalErr != nil {
 			return pkgerrors.Wrap(unmarshalErr, "unable to unmarshal from field com.openfaas.spec")
 		}
 	}
 
-	// Update the Deployment resource if the fni definition differs
-	if controller.IngressNeedsUpdate(&old, fni) {
+	// The spec annotation only tells us whether the FunctionIngress changed,
+	// so also compare the live Ingress to catch edits made outside the operator.
+	desired := makeIngress(fni, ingressClass, gateway)
+	stale := staleAnnotations(&old, desired, ingress)
+	drift := ingressDrift(desired, ingress, stale)
+
+	// Update the Ingress resource if the fni definition differs
+	if adopt || len(drift) > 0 || controller.IngressNeedsUpdate(&old, fni) {
 		klog.Infof("Updating FunctionIngress: %s", fniName)
 
-		if old.ObjectMeta.Name != fni.ObjectMeta.Name {
+		if len(old.ObjectMeta.Name) > 0 && old.ObjectMeta.Name != fni.ObjectMeta.Name {
 			return fmt.Errorf("cannot rename object")
 		}
 
 		updated := ingress.DeepCopy()
+		if adopt {
+			updated.OwnerReferences = append(updated.OwnerReferences, controller.MakeOwnerRef(fni)...)
+		}
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
 
-		rules := makeRules(fni)
-
-		annotations := controller.MakeAnnotations(fni)
-		for k, v := range annotations {
+		for k, v := range desired.Annotations {
 			updated.Annotations[k] = v
 		}
+		for _, k := range stale {
+			delete(updated.Annotations, k)
+		}
+		// The deprecated annotation can not be set with IngressClassName
+		if desired.Spec.IngressClassName != nil {
+			delete(updated.Annotations, controller.IngressClassAnnotation)
+		}
 
-		updated.Spec.Rules = rules
-		updated.Spec.TLS = makeTLS(fni)
+		updated.Spec.IngressClassName = desired.Spec.IngressClassName
+		updated.Spec.Rules = desired.Spec.Rules
+		updated.Spec.TLS = desired.Spec.TLS
 
 		_, updateErr := h.kubeclientset.NetworkingV1().Ingresses(namespace).Update(ctx, updated, metav1.UpdateOptions{})
 		if updateErr != nil {
 			klog.Errorf("error updating ingress: %v", updateErr)
-			return updateErr
+			return h.syncFailed(ctx, fni, next, controller.ErrIngressUpdate,
+				fmt.Sprintf(controller.MessageIngressUpdate, name, updateErr.Error()), updateErr)
 		}
+
+		if len(drift) > 0 && !adopt && !controller.IngressNeedsUpdate(&old, fni) {
+			h.recorder.Event(fni, corev1.EventTypeWarning, controller.DriftCorrected,
+				fmt.Sprintf(controller.MessageDriftCorrected, name, strings.Join(drift, ", ")))
+		}
+
+		if adopt {
+			h.recorder.Event(fni, corev1.EventTypeNormal, controller.SuccessAdopted, fmt.Sprintf(controller.MessageResourceAdopted, name))
+			controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+				controller.ReasonIngressAdopted, fmt.Sprintf("Ingress %s adopted", name))
+		} else {
+			controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+				controller.ReasonIngressUpdated, fmt.Sprintf("Ingress %s updated", name))
+		}
+	} else if !controller.IsConditionTrue(next, controller.ConditionIngressCreated) {
+		controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+			controller.ReasonIngressSynced, fmt.Sprintf("Ingress %s is up to date", name))
 	}
 
 	// If an error occurs during Get/Create, we'll requeue the item so we can
@@ -188,60 +535,270 @@ func (h SyncHandler) handler(ctx context.Context, key string) error {
 		return fmt.Errorf("transient error: %v", err)
 	}
 
+	h.setCertificateCondition(next)
+	controller.SetReady(next)
+
 	h.recorder.Event(fni, corev1.EventTypeNormal, controller.SuccessSynced, controller.MessageResourceSynced)
+	return h.updateStatus(ctx, fni, next)
+}
+
+// setCertificateCondition reports whether the TLS secret for the domain
+// holds a certificate which is valid for every host, either issued by
+// cert-manager or provided as an existing secret. The Ready condition of a
+// Certificate created by the operator takes precedence when it is not True.
+func (h SyncHandler) setCertificateCondition(fni *faasv1.FunctionIngress) {
+	h.setSecretConditions(fni)
+
+	if fni.Spec.ManagedCertificate() {
+		h.mirrorCertificateReady(fni)
+	}
+}
+
+// setSecretConditions checks the certificate in the TLS secret.
+func (h SyncHandler) setSecretConditions(fni *faasv1.FunctionIngress) {
+	mode := fni.Spec.TLSMode()
+	if mode != faasv1.TLSModeExistingSecret {
+		controller.RemoveCondition(fni, controller.ConditionTLSSecretMissing)
+	}
+	if mode == faasv1.TLSModeNone {
+		controller.RemoveCondition(fni, controller.ConditionCertificateReady)
+		controller.RemoveCondition(fni, controller.ConditionCertificateExpiringSoon)
+		return
+	}
+
+	secretName := tlsSecretName(fni)
+	secret, err := h.secretLister.Secrets(fni.Namespace).Get(secretName)
+	if err != nil || len(secret.Data[corev1.TLSCertKey]) == 0 {
+		controller.RemoveCondition(fni, controller.ConditionCertificateExpiringSoon)
+
+		if mode == faasv1.TLSModeExistingSecret {
+			msg := fmt.Sprintf("Secret %s with a certificate in %s was not found", secretName, corev1.TLSCertKey)
+			controller.SetCondition(fni, controller.ConditionTLSSecretMissing, metav1.ConditionTrue,
+				controller.ReasonTLSSecretMissing, msg)
+			controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+				controller.ReasonTLSSecretMissing, msg)
+			return
+		}
+
+		controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+			controller.ReasonCertificatePending, fmt.Sprintf("Waiting for certificate in secret %s", secretName))
+		return
+	}
+
+	if mode == faasv1.TLSModeExistingSecret {
+		controller.SetCondition(fni, controller.ConditionTLSSecretMissing, metav1.ConditionFalse,
+			controller.ReasonTLSSecretFound, fmt.Sprintf("Found secret %s", secretName))
+	}
+
+	cert, err := controller.ParseCertificate(secret)
+	if err != nil {
+		controller.RemoveCondition(fni, controller.ConditionCertificateExpiringSoon)
+		controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+			controller.ReasonCertificateInvalid, err.Error())
+		return
+	}
+
+	expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+	remaining := time.Until(cert.NotAfter)
+	if remaining <= 0 {
+		msg := fmt.Sprintf("Certificate in secret %s expired at %s", secretName, expiry)
+		controller.SetCondition(fni, controller.ConditionCertificateExpiringSoon, metav1.ConditionTrue,
+			controller.ReasonCertificateExpired, msg)
+		controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+			controller.ReasonCertificateExpired, msg)
+		return
+	}
+
+	if remaining < h.certificateExpiryThreshold() {
+		controller.SetCondition(fni, controller.ConditionCertificateExpiringSoon, metav1.ConditionTrue,
+			controller.ReasonCertificateExpiringSoon, fmt.Sprintf("Certificate in secret %s expires at %s", secretName, expiry))
+	} else {
+		controller.SetCondition(fni, controller.ConditionCertificateExpiringSoon, metav1.ConditionFalse,
+			controller.ReasonCertificateValid, fmt.Sprintf("Certificate in secret %s expires at %s", secretName, expiry))
+	}
+
+	if uncovered := controller.UncoveredHosts(cert, fni.Spec.Hosts()); len(uncovered) > 0 {
+		controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionFalse,
+			controller.ReasonCertificateHostMismatch, fmt.Sprintf("Certificate in secret %s is not valid for: %s",
+				secretName, strings.Join(uncovered, ", ")))
+		return
+	}
+
+	controller.SetCondition(fni, controller.ConditionCertificateReady, metav1.ConditionTrue,
+		controller.ReasonCertificateIssued, fmt.Sprintf("Certificate issued in secret %s", secretName))
+}
+
+func (h SyncHandler) certificateExpiryThreshold() time.Duration {
+	if h.config.CertificateExpiryThreshold <= 0 {
+		return controller.DefaultCertificateExpiryThreshold
+	}
+	return h.config.CertificateExpiryThreshold
+}
+
+// syncFailed records a Warning event and a Degraded condition for err, it
+// then returns err so that the key is requeued with backoff.
+func (h SyncHandler) syncFailed(ctx context.Context, fni, next *faasv1.FunctionIngress, reason, message string, err error) error {
+	h.recorder.Event(fni, corev1.EventTypeWarning, reason, message)
+
+	controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionFalse, reason, message)
+	controller.SetDegraded(next, reason, message)
+	if statusErr := h.updateStatus(ctx, fni, next); statusErr != nil {
+		klog.Errorf("error updating status for %s/%s: %v", fni.Namespace, fni.Name, statusErr)
+	}
+
+	return err
+}
+
+// updateStatus writes the status of next via the status subresource when
+// it differs from the status of the cached FunctionIngress.
+func (h SyncHandler) updateStatus(ctx context.Context, fni, next *faasv1.FunctionIngress) error {
+	if equality.Semantic.DeepEqual(fni.Status, next.Status) {
+		return nil
+	}
+
+	_, err := h.faasclientset.OpenfaasV1().FunctionIngresses(next.Namespace).UpdateStatus(ctx, next, metav1.UpdateOptions{})
+	if err != nil {
+		return pkgerrors.Wrap(err, "unable to update FunctionIngress status")
+	}
+
 	return nil
 }
 
-func makeRules(fni *faasv1.FunctionIngress) []netv1.IngressRule {
-	path := "/(.*)"
+// makeIngress renders the desired Ingress for the FunctionIngress. The
+// IngressClassName is left unset when the FunctionIngress overrides the
+// deprecated class annotation, as the API server rejects both being set.
+func makeIngress(fni *faasv1.FunctionIngress, ingressClass controller.IngressClass, gateway controller.Gateway) *netv1.Ingress {
+	annotations := controller.MakeAnnotations(fni, ingressClass.Dialect)
+
+	var ingressClassName *string
+	if _, ok := annotations[controller.IngressClassAnnotation]; !ok {
+		ingressClassName = &ingressClass.Name
+	}
+
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fni.Name,
+			Namespace:       fni.Namespace,
+			Annotations:     annotations,
+			Labels:          controller.MakeOwnerLabels(fni),
+			OwnerReferences: controller.MakeOwnerRef(fni),
+		},
+		Spec: netv1.IngressSpec{
+			IngressClassName: ingressClassName,
+			Rules:            makeRules(fni, ingressClass.Dialect, gateway),
+			TLS:              makeTLS(fni),
+		},
+	}
+}
 
-	if fni.Spec.BypassGateway {
-		path = "/"
+// staleAnnotations returns the annotations of the live Ingress which were
+// rendered for the previous spec but are no longer desired, such as the
+// rewrite-target of a FunctionIngress which now bypasses the gateway. The
+// previous spec is rendered for each dialect, as its IngressClass may have
+// changed since.
+func staleAnnotations(old *faasv1.FunctionIngress, desired, live *netv1.Ingress) []string {
+	if len(old.Name) == 0 {
+		return nil
 	}
 
-	if len(fni.Spec.Path) > 0 {
-		path = fni.Spec.Path
+	managed := map[string]bool{}
+	for _, dialect := range []string{"", "nginx", "skipper", "traefik"} {
+		for k := range controller.MakeAnnotations(old, dialect) {
+			managed[k] = true
+		}
 	}
 
-	if controller.GetClass(fni.Spec.IngressType) == "traefik" {
-		// We have to trim the regex and the trailing slash for Traefik,
-		// otherwise routing won't work
-		path = strings.TrimRight(path, "/(.*)")
-		if len(path) == 0 {
-			path = "/"
+	stale := []string{}
+	for k := range managed {
+		if _, desired := desired.Annotations[k]; desired {
+			continue
+		}
+		if _, ok := live.Annotations[k]; ok {
+			stale = append(stale, k)
 		}
 	}
+	sort.Strings(stale)
+	return stale
+}
+
+// ingressDrift returns the fields of the live Ingress which no longer match
+// the desired Ingress. Annotations added by other controllers are ignored,
+// as is the serialised spec which is compared by IngressNeedsUpdate, but the
+// stale annotations which the operator set are not.
+func ingressDrift(desired, live *netv1.Ingress, stale []string) []string {
+	drift := []string{}
 
-	serviceHost := "gateway"
-	if fni.Spec.BypassGateway {
-		serviceHost = fni.Spec.Function
+	if !equality.Semantic.DeepEqual(desired.Spec.IngressClassName, live.Spec.IngressClassName) {
+		drift = append(drift, "ingressClassName")
 	}
 
-	pathType := netv1.PathTypeImplementationSpecific
+	if !equality.Semantic.DeepEqual(desired.Spec.Rules, live.Spec.Rules) {
+		drift = append(drift, "rules")
+	}
 
-	return []netv1.IngressRule{
-		{
-			Host: fni.Spec.Domain,
+	if !equality.Semantic.DeepEqual(desired.Spec.TLS, live.Spec.TLS) {
+		drift = append(drift, "tls")
+	}
+
+	annotationsDrift := len(stale) > 0
+	for k, v := range desired.Annotations {
+		if k == controller.SpecAnnotation {
+			continue
+		}
+		if current, ok := live.Annotations[k]; !ok || current != v {
+			annotationsDrift = true
+			break
+		}
+	}
+	if annotationsDrift {
+		drift = append(drift, "annotations")
+	}
+
+	return drift
+}
+
+// ingressPathTypes are the pathTypes of an Ingress for each value of
+// pathMatch, how a regular expression is matched is up to the controller
+var ingressPathTypes = map[string]netv1.PathType{
+	faasv1.PathMatchPrefix: netv1.PathTypePrefix,
+	faasv1.PathMatchExact:  netv1.PathTypeExact,
+	faasv1.PathMatchRegex:  netv1.PathTypeImplementationSpecific,
+}
+
+func makeRules(fni *faasv1.FunctionIngress, dialect string, gateway controller.Gateway) []netv1.IngressRule {
+	paths := []netv1.HTTPIngressPath{}
+	for _, path := range controller.MakePaths(fni, dialect) {
+		pathType := ingressPathTypes[path.Type]
+		serviceHost, port := controller.Backend(fni, path.Route, gateway)
+
+		paths = append(paths, netv1.HTTPIngressPath{
+			Path:     path.Match,
+			PathType: &pathType,
+			Backend: netv1.IngressBackend{
+				Service: &netv1.IngressServiceBackend{
+					Name: serviceHost,
+					Port: netv1.ServiceBackendPort{
+						Number: port,
+					},
+				},
+			},
+		})
+	}
+
+	// Every host shares the same paths, an empty host matches all requests
+	rules := []netv1.IngressRule{}
+	for _, host := range hosts(fni) {
+		rules = append(rules, netv1.IngressRule{
+			Host: host,
 			IngressRuleValue: netv1.IngressRuleValue{
 				HTTP: &netv1.HTTPIngressRuleValue{
-					Paths: []netv1.HTTPIngressPath{
-						{
-							Path:     path,
-							PathType: &pathType,
-							Backend: netv1.IngressBackend{
-								Service: &netv1.IngressServiceBackend{
-									Name: serviceHost,
-									Port: netv1.ServiceBackendPort{
-										Number: controller.OpenfaasWorkloadPort,
-									},
-								},
-							},
-						},
-					},
+					Paths: paths,
 				},
 			},
-		},
+		})
 	}
+
+	return rules
 }
 
 func makeTLS(fni *faasv1.FunctionIngress) []netv1.IngressTLS {
@@ -249,12 +806,32 @@ func makeTLS(fni *faasv1.FunctionIngress) []netv1.IngressTLS {
 		return []netv1.IngressTLS{}
 	}
 
+	// A single entry means that cert-manager issues one certificate with
+	// every host as a SAN, rather than one per host
 	return []netv1.IngressTLS{
 		{
-			SecretName: fni.Spec.Domain + "-cert",
-			Hosts: []string{
-				fni.Spec.Domain,
-			},
+			SecretName: tlsSecretName(fni),
+			Hosts:      hosts(fni),
 		},
 	}
 }
+
+// hosts returns the hosts of the FunctionIngress, or a single empty host
+// when no domain is set, which is how an Ingress matches every request.
+func hosts(fni *faasv1.FunctionIngress) []string {
+	hosts := fni.Spec.Hosts()
+	if len(hosts) == 0 {
+		return []string{""}
+	}
+	return hosts
+}
+
+// tlsSecretName is the name of the secret with the certificate for the
+// domain. Unless it is set in the spec, it is named after the first host,
+// with a wildcard spelt out, since "*" is not valid in the name of a secret.
+func tlsSecretName(fni *faasv1.FunctionIngress) string {
+	if fni.Spec.TLS != nil && len(fni.Spec.TLS.SecretName) > 0 {
+		return fni.Spec.TLS.SecretName
+	}
+	return strings.Replace(hosts(fni)[0], "*", "wildcard", 1) + "-cert"
+}
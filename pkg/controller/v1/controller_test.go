@@ -1,13 +1,31 @@
 package v1
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	networkingv1 "k8s.io/client-go/listers/networking/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 
 	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	faasfake "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/fake"
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
 	"github.com/openfaas/ingress-operator/pkg/controller"
 )
 
@@ -21,7 +39,7 @@ func Test_makeRules_Nginx_RootPath_HasRegex(t *testing.T) {
 		},
 	}
 
-	rules := makeRules(&ingress)
+	rules := makeRules(&ingress, ingress.Spec.IngressType, testGateway)
 
 	if len(rules) == 0 {
 		t.Errorf("Ingress should give at least one rule")
@@ -56,7 +74,7 @@ func Test_makeRules_Nginx_RootPath_IsRootWithBypassMode(t *testing.T) {
 		},
 	}
 
-	rules := makeRules(&ingress)
+	rules := makeRules(&ingress, ingress.Spec.IngressType, testGateway)
 
 	if len(rules) == 0 {
 		t.Errorf("Ingress should give at least one rule")
@@ -88,7 +106,7 @@ func Test_makeRules_Nginx_PathOverride(t *testing.T) {
 		},
 	}
 
-	rules := makeRules(&ingress)
+	rules := makeRules(&ingress, ingress.Spec.IngressType, testGateway)
 
 	if len(rules) == 0 {
 		t.Errorf("Ingress should give at least one rule")
@@ -113,7 +131,7 @@ func Test_makeRules_Traefik_RootPath_TrimsRegex(t *testing.T) {
 		},
 	}
 
-	rules := makeRules(&ingress)
+	rules := makeRules(&ingress, ingress.Spec.IngressType, testGateway)
 
 	if len(rules) == 0 {
 		t.Errorf("Ingress should give at least one rule")
@@ -138,7 +156,7 @@ func Test_makeRules_Traefik_NestedPath_TrimsRegex_And_TrailingSlash(t *testing.T
 		},
 	}
 
-	rules := makeRules(&ingress)
+	rules := makeRules(&ingress, ingress.Spec.IngressType, testGateway)
 
 	if len(rules) == 0 {
 		t.Errorf("Ingress should give at least one rule")
@@ -152,6 +170,115 @@ func Test_makeRules_Traefik_NestedPath_TrimsRegex_And_TrailingSlash(t *testing.T
 	}
 }
 
+func Test_makeRules_Traefik_PathTypePrefix(t *testing.T) {
+	ingress := faasv1.FunctionIngress{
+		Spec: faasv1.FunctionIngressSpec{
+			IngressType: "traefik",
+			Path:        "/v1.0/users/(.*)",
+		},
+	}
+
+	path := makeRules(&ingress, ingress.Spec.IngressType, testGateway)[0].HTTP.Paths[0]
+	if path.Path != "/v1.0/users" || *path.PathType != netv1.PathTypePrefix {
+		t.Errorf("want Prefix /v1.0/users, got %s %s", *path.PathType, path.Path)
+	}
+}
+
+func Test_makeRules_Nginx_ExactPathWithBypass(t *testing.T) {
+	ingress := faasv1.FunctionIngress{
+		Spec: faasv1.FunctionIngressSpec{
+			IngressType:   "nginx",
+			Function:      "nodeinfo",
+			Path:          "/healthz",
+			PathMatch:     faasv1.PathMatchExact,
+			BypassGateway: true,
+		},
+	}
+
+	path := makeRules(&ingress, ingress.Spec.IngressType, testGateway)[0].HTTP.Paths[0]
+	if path.Path != "/healthz" || *path.PathType != netv1.PathTypeExact {
+		t.Errorf("want Exact /healthz, got %s %s", *path.PathType, path.Path)
+	}
+}
+
+func Test_makeRules_Domains_OneRulePerHost(t *testing.T) {
+	ingress := faasv1.FunctionIngress{
+		Spec: faasv1.FunctionIngressSpec{
+			IngressType: "nginx",
+			Domain:      "api.example.com",
+			Domains:     []faasv1.Domain{"www.api.example.com", "*.eu.api.example.com"},
+		},
+	}
+
+	rules := makeRules(&ingress, ingress.Spec.IngressType, testGateway)
+
+	wantHosts := []string{"api.example.com", "www.api.example.com", "*.eu.api.example.com"}
+	if len(rules) != len(wantHosts) {
+		t.Fatalf("want %d rules, but got %d", len(wantHosts), len(rules))
+	}
+
+	for i, rule := range rules {
+		if rule.Host != wantHosts[i] {
+			t.Errorf("want host %s, but got %s", wantHosts[i], rule.Host)
+		}
+		if gotPath := rule.HTTP.Paths[0].Path; gotPath != "/(.*)" {
+			t.Errorf("want path /(.*) for %s, but got %s", rule.Host, gotPath)
+		}
+	}
+}
+
+func Test_makeRules_Routes_OnePathPerRoute(t *testing.T) {
+	ingress := faasv1.FunctionIngress{
+		Spec: faasv1.FunctionIngressSpec{
+			IngressType: "nginx",
+			Domain:      "api.example.com",
+			Routes: []faasv1.FunctionRoute{
+				{Path: "/v1/users/(.*)", Function: "users"},
+				{Path: "/v1/orders/(.*)", Function: "orders"},
+			},
+		},
+	}
+
+	rules := makeRules(&ingress, ingress.Spec.IngressType, testGateway)
+	if len(rules) != 1 {
+		t.Fatalf("want 1 rule, but got %d", len(rules))
+	}
+
+	paths := rules[0].HTTP.Paths
+	wantPaths := []string{"/v1/users/(.*)", "/v1/orders/(.*)"}
+	if len(paths) != len(wantPaths) {
+		t.Fatalf("want %d paths, but got %d", len(wantPaths), len(paths))
+	}
+	for i, path := range paths {
+		if path.Path != wantPaths[i] {
+			t.Errorf("want path %s, but got %s", wantPaths[i], path.Path)
+		}
+		if path.Backend.Service.Name != "gateway" {
+			t.Errorf("want backend gateway, but got %s", path.Backend.Service.Name)
+		}
+	}
+}
+
+func Test_makeRules_Routes_BypassUsesEachFunction(t *testing.T) {
+	ingress := faasv1.FunctionIngress{
+		Spec: faasv1.FunctionIngressSpec{
+			IngressType:   "nginx",
+			BypassGateway: true,
+			Routes: []faasv1.FunctionRoute{
+				{Path: "/users", Function: "users"},
+				{Path: "/orders", Function: "orders"},
+			},
+		},
+	}
+
+	paths := makeRules(&ingress, ingress.Spec.IngressType, testGateway)[0].HTTP.Paths
+	for i, want := range []string{"users", "orders"} {
+		if got := paths[i].Backend.Service.Name; got != want {
+			t.Errorf("want backend %s, but got %s", want, got)
+		}
+	}
+}
+
 func Test_makTLS(t *testing.T) {
 
 	cases := []struct {
@@ -187,6 +314,47 @@ func Test_makTLS(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "tls enabled with domains creates a single TLS object covering every host",
+			fni: &faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					Domain:  "foo.example.com",
+					Domains: []faasv1.Domain{"www.foo.example.com", "*.eu.foo.example.com", "foo.example.com"},
+					TLS: &faasv1.FunctionIngressTLS{
+						Enabled: true,
+					},
+				},
+			},
+			expected: []netv1.IngressTLS{
+				{
+					SecretName: "foo.example.com-cert",
+					Hosts: []string{
+						"foo.example.com",
+						"www.foo.example.com",
+						"*.eu.foo.example.com",
+					},
+				},
+			},
+		},
+		{
+			name: "tls enabled with only a wildcard domain spells out the wildcard in the secret",
+			fni: &faasv1.FunctionIngress{
+				Spec: faasv1.FunctionIngressSpec{
+					Domains: []faasv1.Domain{"*.foo.example.com"},
+					TLS: &faasv1.FunctionIngressTLS{
+						Enabled: true,
+					},
+				},
+			},
+			expected: []netv1.IngressTLS{
+				{
+					SecretName: "wildcard.foo.example.com-cert",
+					Hosts: []string{
+						"*.foo.example.com",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -198,3 +366,679 @@ func Test_makTLS(t *testing.T) {
 		})
 	}
 }
+
+// newTestHandler creates a SyncHandler backed by fake clientsets, the
+// listers are populated with the given objects.
+func newTestHandler(t *testing.T, objects ...runtime.Object) (SyncHandler, *kubefake.Clientset, *faasfake.Clientset) {
+	t.Helper()
+
+	fniIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	ingressIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	ingressClassIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	serviceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	endpointSliceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	kubeObjects := []runtime.Object{}
+	faasObjects := []runtime.Object{}
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *faasv1.FunctionIngress:
+			fniIndexer.Add(o)
+			faasObjects = append(faasObjects, o)
+		case *netv1.Ingress:
+			ingressIndexer.Add(o)
+			kubeObjects = append(kubeObjects, o)
+		case *corev1.Secret:
+			secretIndexer.Add(o)
+			kubeObjects = append(kubeObjects, o)
+		case *netv1.IngressClass:
+			ingressClassIndexer.Add(o)
+			kubeObjects = append(kubeObjects, o)
+		case *corev1.Service:
+			serviceIndexer.Add(o)
+			kubeObjects = append(kubeObjects, o)
+		case *discoveryv1.EndpointSlice:
+			endpointSliceIndexer.Add(o)
+			kubeObjects = append(kubeObjects, o)
+		default:
+			t.Fatalf("unsupported test object %T", obj)
+		}
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(kubeObjects...)
+	faasClient := faasfake.NewSimpleClientset(faasObjects...)
+
+	h := SyncHandler{
+		kubeclientset:       kubeClient,
+		faasclientset:       faasClient,
+		functionsLister:     listers.NewFunctionIngressLister(fniIndexer),
+		ingressLister:       networkingv1.NewIngressLister(ingressIndexer),
+		ingressClassLister:  networkingv1.NewIngressClassLister(ingressClassIndexer),
+		serviceLister:       corelisters.NewServiceLister(serviceIndexer),
+		endpointSliceLister: discoverylisters.NewEndpointSliceLister(endpointSliceIndexer),
+		secretLister:        corelisters.NewSecretLister(secretIndexer),
+		workqueue:           workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(0, 0)),
+		recorder:            record.NewFakeRecorder(10),
+	}
+
+	return h, kubeClient, faasClient
+}
+
+// testGateway is the default gateway for a FunctionIngress in the openfaas
+// namespace
+var testGateway = controller.Gateway{Name: "gateway", Namespace: "openfaas", Port: 8080, Service: "gateway"}
+
+func newTestFunctionIngress() *faasv1.FunctionIngress {
+	return &faasv1.FunctionIngress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "nodeinfo",
+			Namespace:  "openfaas",
+			Generation: 2,
+		},
+		Spec: faasv1.FunctionIngressSpec{
+			Domain:   "nodeinfo.example.com",
+			Function: "nodeinfo",
+		},
+	}
+}
+
+func getStatusCondition(t *testing.T, faasClient *faasfake.Clientset, conditionType string) *metav1.Condition {
+	t.Helper()
+
+	return getStatusConditionIn(t, faasClient, "openfaas", conditionType)
+}
+
+func getStatusConditionIn(t *testing.T, faasClient *faasfake.Clientset, namespace, conditionType string) *metav1.Condition {
+	t.Helper()
+
+	got, err := faasClient.OpenfaasV1().FunctionIngresses(namespace).Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get FunctionIngress: %s", err)
+	}
+
+	return meta.FindStatusCondition(got.Status.Conditions, conditionType)
+}
+
+func Test_handler_SetsReadyConditionsOnCreate(t *testing.T) {
+	fni := newTestFunctionIngress()
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{}); err != nil {
+		t.Fatalf("want ingress to be created, got: %s", err)
+	}
+
+	for _, conditionType := range []string{controller.ConditionIngressCreated, controller.ConditionReady} {
+		c := getStatusCondition(t, faasClient, conditionType)
+		if c == nil {
+			t.Fatalf("want condition %s to be set", conditionType)
+		}
+		if c.Status != metav1.ConditionTrue {
+			t.Errorf("want condition %s to be True, got %s", conditionType, c.Status)
+		}
+		if c.ObservedGeneration != fni.Generation {
+			t.Errorf("want observedGeneration %d, got %d", fni.Generation, c.ObservedGeneration)
+		}
+	}
+
+	if c := getStatusCondition(t, faasClient, controller.ConditionCertificateReady); c != nil {
+		t.Errorf("want no %s condition when TLS is disabled, got %v", controller.ConditionCertificateReady, c)
+	}
+	if got := getStatusTLSMode(t, faasClient); got != faasv1.TLSModeNone {
+		t.Errorf("want tlsMode %q without tls, got %q", faasv1.TLSModeNone, got)
+	}
+}
+
+// getStatusTLSMode returns the tlsMode in the status of openfaas/nodeinfo
+func getStatusTLSMode(t *testing.T, faasClient *faasfake.Clientset) string {
+	t.Helper()
+
+	fni, err := faasClient.OpenfaasV1().FunctionIngresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get FunctionIngress: %s", err)
+	}
+	return fni.Status.TLSMode
+}
+
+func Test_handler_CertificatePendingUntilSecretIssued(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{
+		Enabled:   true,
+		IssuerRef: faasv1.ObjectReference{Name: "letsencrypt"},
+	}
+
+	h, _, faasClient := newTestHandler(t, fni)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionCertificateReady)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonCertificatePending {
+		t.Fatalf("want %s to be pending, got %v", controller.ConditionCertificateReady, c)
+	}
+	// Only tls.enabled is set, as for a FunctionIngress created before
+	// tls.mode was added
+	if got := getStatusTLSMode(t, faasClient); got != faasv1.TLSModeCertManager {
+		t.Errorf("want tlsMode %q from tls.enabled, got %q", faasv1.TLSModeCertManager, got)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionFalse {
+		t.Fatalf("want %s to be False while the certificate is pending, got %v", controller.ConditionReady, c)
+	}
+
+	secret := newTestCertificateSecret(t, "nodeinfo.example.com-cert", []string{"nodeinfo.example.com"}, 90*24*time.Hour)
+	h, _, faasClient = newTestHandler(t, fni, secret)
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c := getStatusCondition(t, faasClient, controller.ConditionCertificateReady); c == nil || c.Status != metav1.ConditionTrue {
+		t.Fatalf("want %s to be True, got %v", controller.ConditionCertificateReady, c)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionTrue {
+		t.Fatalf("want %s to be True, got %v", controller.ConditionReady, c)
+	}
+}
+
+// drainEvents returns the events recorded so far by a FakeRecorder
+func Test_handler_InvalidRoutesAreDegraded(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.Routes = []faasv1.FunctionRoute{
+		{Path: "/v1/(.*)", Function: "users"},
+		{Path: "/v1/(.*)", Function: "orders"},
+	}
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionDegraded)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonInvalidSpec {
+		t.Errorf("want Degraded True with reason %s, got %v", controller.ReasonInvalidSpec, c)
+	}
+
+	ingresses, _ := kubeClient.NetworkingV1().Ingresses("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(ingresses.Items) != 0 {
+		t.Errorf("want no Ingress for an invalid spec, got %d", len(ingresses.Items))
+	}
+}
+
+func Test_handler_UnsupportedPathMatchIsDegraded(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.IngressType = "traefik"
+	fni.Spec.IngressClassName = ""
+	fni.Spec.PathMatch = faasv1.PathMatchRegex
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionDegraded)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonPathMatchUnsupported {
+		t.Errorf("want Degraded True with reason %s, got %v", controller.ReasonPathMatchUnsupported, c)
+	}
+
+	ingresses, _ := kubeClient.NetworkingV1().Ingresses("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(ingresses.Items) != 0 {
+		t.Errorf("want no Ingress for an unsupported pathMatch, got %d", len(ingresses.Items))
+	}
+}
+
+func Test_handler_IngressClassNameFromDefaultIngressClass(t *testing.T) {
+	fni := newTestFunctionIngress()
+	ingressClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "public",
+			Annotations: map[string]string{
+				netv1.AnnotationIsDefaultIngressClass: "true",
+			},
+		},
+		Spec: netv1.IngressClassSpec{
+			Controller: "k8s.io/ingress-nginx",
+		},
+	}
+	h, kubeClient, _ := newTestHandler(t, fni, ingressClass)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "public" {
+		t.Errorf("want IngressClassName public, got %v", ingress.Spec.IngressClassName)
+	}
+	if _, ok := ingress.Annotations[controller.IngressClassAnnotation]; ok {
+		t.Errorf("want no %s annotation", controller.IngressClassAnnotation)
+	}
+	if got := ingress.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]; got != "/function/nodeinfo/$1" {
+		t.Errorf("want the nginx rewrite-target for the public class, got %q", got)
+	}
+}
+
+func Test_handler_ReplacesDeprecatedClassAnnotation(t *testing.T) {
+	fni := newTestFunctionIngress()
+
+	live := makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway)
+	live.Spec.IngressClassName = nil
+	live.Annotations[controller.IngressClassAnnotation] = "nginx"
+
+	h, kubeClient, _ := newTestHandler(t, fni, live)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx" {
+		t.Errorf("want IngressClassName nginx, got %v", ingress.Spec.IngressClassName)
+	}
+	if _, ok := ingress.Annotations[controller.IngressClassAnnotation]; ok {
+		t.Errorf("want the %s annotation to be removed", controller.IngressClassAnnotation)
+	}
+}
+
+func Test_makeIngress_ClassAnnotationOverride(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Annotations = map[string]string{
+		controller.IngressClassAnnotation: "nginx",
+	}
+
+	ingress := makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway)
+	if ingress.Spec.IngressClassName != nil {
+		t.Errorf("want no IngressClassName when the annotation is overridden, got %s", *ingress.Spec.IngressClassName)
+	}
+}
+
+func drainEvents(h SyncHandler) []string {
+	recorder := h.recorder.(*record.FakeRecorder)
+	events := []string{}
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func Test_handler_IngressCreateErrorIsReturned(t *testing.T) {
+	fni := newTestFunctionIngress()
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+
+	kubeClient.PrependReactor("create", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("admission webhook denied the request")
+	})
+
+	err := h.handler(context.Background(), "openfaas/nodeinfo")
+	if err == nil {
+		t.Fatalf("want create error to be returned so that the key is requeued")
+	}
+
+	events := drainEvents(h)
+	if len(events) != 1 {
+		t.Fatalf("want 1 event, got %d: %v", len(events), events)
+	}
+	wantPrefix := corev1.EventTypeWarning + " " + controller.ErrIngressCreate
+	if !strings.HasPrefix(events[0], wantPrefix) {
+		t.Errorf("want event with prefix %q, got %q", wantPrefix, events[0])
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionIngressCreated)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ErrIngressCreate {
+		t.Fatalf("want %s to be False with reason %s, got %v", controller.ConditionIngressCreated, controller.ErrIngressCreate, c)
+	}
+	if !strings.Contains(c.Message, "admission webhook denied the request") {
+		t.Errorf("want condition message to include the error, got %q", c.Message)
+	}
+
+	c = getStatusCondition(t, faasClient, controller.ConditionDegraded)
+	if c == nil || c.Status != metav1.ConditionTrue {
+		t.Fatalf("want %s to be True, got %v", controller.ConditionDegraded, c)
+	}
+}
+
+func Test_handler_IngressUpdateErrorIsReturned(t *testing.T) {
+	previous := newTestFunctionIngress()
+	previous.Spec.Domain = "old.example.com"
+
+	fni := newTestFunctionIngress()
+	ingress := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "nodeinfo",
+			Namespace:       "openfaas",
+			Annotations:     controller.MakeAnnotations(previous, "nginx"),
+			OwnerReferences: controller.MakeOwnerRef(fni),
+		},
+	}
+
+	h, kubeClient, faasClient := newTestHandler(t, fni, ingress)
+	kubeClient.PrependReactor("update", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("conflict")
+	})
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err == nil {
+		t.Fatalf("want update error to be returned so that the key is requeued")
+	}
+
+	for _, e := range drainEvents(h) {
+		if strings.Contains(e, controller.SuccessSynced) {
+			t.Errorf("want no %s event after a failure, got %q", controller.SuccessSynced, e)
+		}
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionIngressCreated)
+	if c == nil || c.Reason != controller.ErrIngressUpdate {
+		t.Fatalf("want %s with reason %s, got %v", controller.ConditionIngressCreated, controller.ErrIngressUpdate, c)
+	}
+}
+
+func Test_handler_RecoversAfterCreateError(t *testing.T) {
+	fni := newTestFunctionIngress()
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+
+	failures := 1
+	kubeClient.PrependReactor("create", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failures > 0 {
+			failures--
+			return true, nil, fmt.Errorf("etcd timeout")
+		}
+		return false, nil, nil
+	})
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err == nil {
+		t.Fatalf("want first sync to fail")
+	}
+
+	// The lister in the handler is static, so refresh it with the stored
+	// status to mimic the informer observing the status update.
+	stored, _ := faasClient.OpenfaasV1().FunctionIngresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(stored)
+	h.functionsLister = listers.NewFunctionIngressLister(indexer)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("want second sync to succeed, got: %s", err)
+	}
+
+	if c := getStatusCondition(t, faasClient, controller.ConditionDegraded); c == nil || c.Status != metav1.ConditionFalse {
+		t.Fatalf("want %s to be cleared, got %v", controller.ConditionDegraded, c)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionTrue {
+		t.Fatalf("want %s to be True, got %v", controller.ConditionReady, c)
+	}
+}
+
+func newUnmanagedIngress(owners ...metav1.OwnerReference) *netv1.Ingress {
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "nodeinfo",
+			Namespace:       "openfaas",
+			OwnerReferences: owners,
+		},
+		Spec: netv1.IngressSpec{
+			Rules: []netv1.IngressRule{{Host: "legacy.example.com"}},
+		},
+	}
+}
+
+func Test_handler_RefusesUnmanagedIngress(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.UID = "fni-uid"
+
+	h, kubeClient, faasClient := newTestHandler(t, fni, newUnmanagedIngress())
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err == nil {
+		t.Fatalf("want an error when the Ingress is not managed by the FunctionIngress")
+	}
+
+	got, _ := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if got.Spec.Rules[0].Host != "legacy.example.com" || len(got.OwnerReferences) != 0 {
+		t.Fatalf("want unmanaged Ingress to be left unchanged, got %v", got)
+	}
+
+	events := drainEvents(h)
+	wantPrefix := corev1.EventTypeWarning + " " + controller.ErrResourceExists
+	if len(events) != 1 || !strings.HasPrefix(events[0], wantPrefix) {
+		t.Fatalf("want one event with prefix %q, got %v", wantPrefix, events)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionIngressCreated)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ErrResourceExists {
+		t.Fatalf("want %s to be False with reason %s, got %v", controller.ConditionIngressCreated, controller.ErrResourceExists, c)
+	}
+}
+
+func Test_handler_AdoptsUnmanagedIngressWithAnnotation(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.UID = "fni-uid"
+	fni.Annotations = map[string]string{controller.AdoptAnnotation: "true"}
+
+	h, kubeClient, faasClient := newTestHandler(t, fni, newUnmanagedIngress())
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, _ := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if !metav1.IsControlledBy(got, fni) {
+		t.Fatalf("want Ingress to be controlled by the FunctionIngress, got owners %v", got.OwnerReferences)
+	}
+	if got.Spec.Rules[0].Host != fni.Spec.Domain {
+		t.Errorf("want host %s, got %s", fni.Spec.Domain, got.Spec.Rules[0].Host)
+	}
+	if _, ok := got.Annotations["com.openfaas.spec"]; !ok {
+		t.Errorf("want com.openfaas.spec annotation to be set on the adopted Ingress")
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionIngressCreated)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonIngressAdopted {
+		t.Fatalf("want %s to be True with reason %s, got %v", controller.ConditionIngressCreated, controller.ReasonIngressAdopted, c)
+	}
+}
+
+func Test_handler_DoesNotAdoptIngressWithAnotherController(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.UID = "fni-uid"
+	fni.Annotations = map[string]string{controller.AdoptAnnotation: "true"}
+
+	isController := true
+	other := metav1.OwnerReference{
+		APIVersion: "example.com/v1",
+		Kind:       "Route",
+		Name:       "nodeinfo",
+		UID:        "other-uid",
+		Controller: &isController,
+	}
+
+	h, kubeClient, _ := newTestHandler(t, fni, newUnmanagedIngress(other))
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err == nil {
+		t.Fatalf("want an error when the Ingress is controlled by another owner")
+	}
+
+	got, _ := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if len(got.OwnerReferences) != 1 || got.OwnerReferences[0].UID != "other-uid" {
+		t.Fatalf("want owner references to be left unchanged, got %v", got.OwnerReferences)
+	}
+}
+
+func Test_handler_RestoresDriftedIngress(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.UID = "fni-uid"
+	// The Ingress was rendered from a spec with the defaults applied
+	controller.SetDefaults(fni, controller.Defaults{}, nil)
+
+	live := makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway)
+	live.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "hand-edited"
+	delete(live.Annotations, "nginx.ingress.kubernetes.io/rewrite-target")
+	live.Annotations["example.com/added-by-hand"] = "true"
+
+	h, kubeClient, _ := newTestHandler(t, fni, live)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, _ := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if name := got.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; name != "gateway" {
+		t.Errorf("want backend to be restored to gateway, got %s", name)
+	}
+	if _, ok := got.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]; !ok {
+		t.Errorf("want rewrite-target annotation to be restored")
+	}
+	if _, ok := got.Annotations["example.com/added-by-hand"]; !ok {
+		t.Errorf("want unrelated annotations to be kept")
+	}
+
+	events := drainEvents(h)
+	wantPrefix := corev1.EventTypeWarning + " " + controller.DriftCorrected
+	found := false
+	for _, e := range events {
+		if strings.HasPrefix(e, wantPrefix) {
+			found = true
+			if !strings.Contains(e, "rules") || !strings.Contains(e, "annotations") || strings.Contains(e, "tls") {
+				t.Errorf("want event to list rules and annotations as drifted, got %q", e)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("want an event with prefix %q, got %v", wantPrefix, events)
+	}
+}
+
+func Test_handler_NoUpdateWithoutDrift(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.UID = "fni-uid"
+	// The Ingress was rendered from a spec with the defaults applied
+	controller.SetDefaults(fni, controller.Defaults{}, nil)
+
+	h, kubeClient, _ := newTestHandler(t, fni, makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway))
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() == "update" && action.GetResource().Resource == "ingresses" {
+			t.Fatalf("want no Ingress update when nothing drifted")
+		}
+	}
+}
+
+func Test_ingressDrift(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{Enabled: true}
+
+	cases := []struct {
+		name   string
+		modify func(live *netv1.Ingress)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(live *netv1.Ingress) {},
+			want:   []string{},
+		},
+		{
+			name: "removed tls",
+			modify: func(live *netv1.Ingress) {
+				live.Spec.TLS = nil
+			},
+			want: []string{"tls"},
+		},
+		{
+			name: "changed host",
+			modify: func(live *netv1.Ingress) {
+				live.Spec.Rules[0].Host = "other.example.com"
+			},
+			want: []string{"rules"},
+		},
+		{
+			name: "spec annotation is ignored",
+			modify: func(live *netv1.Ingress) {
+				live.Annotations[controller.SpecAnnotation] = "{}"
+			},
+			want: []string{},
+		},
+		{
+			name: "changed annotation",
+			modify: func(live *netv1.Ingress) {
+				live.Annotations["cert-manager.io/issuer"] = "other"
+			},
+			want: []string{"annotations"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			live := makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway)
+			tc.modify(live)
+
+			got := ingressDrift(makeIngress(fni, controller.ResolveIngressClass(fni, nil), testGateway), live, nil)
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf("want drift %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_handler_AppliesOperatorDefaults(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{Enabled: true}
+	ingressClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-internal"},
+		Spec:       netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+
+	h, kubeClient, _ := newTestHandler(t, fni, ingressClass)
+	h.config.Defaults = controller.Defaults{
+		IngressClassName:  "nginx-internal",
+		FunctionNamespace: "openfaas-fn",
+		IssuerRef:         faasv1.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+	}
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx-internal" {
+		t.Errorf("want ingressClassName nginx-internal, got %v", ingress.Spec.IngressClassName)
+	}
+	if got := ingress.Annotations["cert-manager.io/cluster-issuer"]; got != "letsencrypt" {
+		t.Errorf("want the default issuer annotation, got %q", got)
+	}
+	if got, want := ingress.Annotations["nginx.ingress.kubernetes.io/rewrite-target"], "/function/nodeinfo.openfaas-fn/$1"; got != want {
+		t.Errorf("want rewrite-target %s, got %s", want, got)
+	}
+}
+
+func Test_queueName_IsUniquePerNamespace(t *testing.T) {
+	cases := map[string]string{
+		metav1.NamespaceAll: "FunctionIngresses",
+		"openfaas":          "FunctionIngresses-openfaas",
+		"staging":           "FunctionIngresses-staging",
+	}
+	for namespace, want := range cases {
+		if got := queueName(namespace); got != want {
+			t.Errorf("namespace %q: want queue name %q, got %q", namespace, want, got)
+		}
+	}
+}
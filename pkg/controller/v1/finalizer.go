@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	klog "k8s.io/klog"
+)
+
+// ensureFinalizer adds the Finalizer before any object is created for the
+// FunctionIngress, and returns the patched FunctionIngress so that the
+// status is written against its new resourceVersion.
+func (h SyncHandler) ensureFinalizer(ctx context.Context, fni *faasv1.FunctionIngress) (*faasv1.FunctionIngress, error) {
+	if controller.HasFinalizer(fni) {
+		return fni, nil
+	}
+
+	finalizers := append(append([]string{}, fni.Finalizers...), controller.Finalizer)
+	patched, err := h.patchFinalizers(ctx, fni, finalizers)
+	if err != nil {
+		return nil, fmt.Errorf("cannot add finalizer to %s/%s: %w", fni.Namespace, fni.Name, err)
+	}
+	return patched, nil
+}
+
+// finalize deletes the objects tracked with the OwnerLabel which the
+// FunctionIngress does not control through an owner reference, then removes
+// the Finalizer. Controlled objects are left to the garbage collector, so
+// that the propagation policy of the delete is honoured. A failure is
+// recorded and returned, so that the key is retried with backoff.
+func (h SyncHandler) finalize(ctx context.Context, fni *faasv1.FunctionIngress) error {
+	if !controller.HasFinalizer(fni) {
+		return nil
+	}
+
+	if err := h.deleteTrackedObjects(ctx, fni); err != nil {
+		msg := fmt.Sprintf("Unable to clean up FunctionIngress: %s", err)
+		klog.Errorf("%s/%s: %s", fni.Namespace, fni.Name, msg)
+		h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonCleanupFailed, msg)
+
+		next := fni.DeepCopy()
+		controller.SetDegraded(next, controller.ReasonCleanupFailed, msg)
+		if statusErr := h.updateStatus(ctx, fni, next); statusErr != nil {
+			klog.Errorf("error updating status for %s/%s: %v", fni.Namespace, fni.Name, statusErr)
+		}
+		return err
+	}
+
+	finalizers := []string{}
+	for _, finalizer := range fni.Finalizers {
+		if finalizer != controller.Finalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	if _, err := h.patchFinalizers(ctx, fni, finalizers); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("cannot remove finalizer from %s/%s: %w", fni.Namespace, fni.Name, err)
+	}
+
+	klog.Infof("Cleaned up FunctionIngress: %s/%s", fni.Namespace, fni.Name)
+	h.recorder.Event(fni, corev1.EventTypeNormal, controller.ReasonCleanedUp, "Deleted the objects tracked by the FunctionIngress")
+	return nil
+}
+
+// patchFinalizers replaces the finalizers of the FunctionIngress. The
+// resourceVersion makes the patch fail with a conflict, rather than drop a
+// finalizer which another controller added in the meantime.
+func (h SyncHandler) patchFinalizers(ctx context.Context, fni *faasv1.FunctionIngress, finalizers []string) (*faasv1.FunctionIngress, error) {
+	metadata := map[string]interface{}{"finalizers": finalizers}
+	if len(fni.ResourceVersion) > 0 {
+		metadata["resourceVersion"] = fni.ResourceVersion
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.faasclientset.OpenfaasV1().FunctionIngresses(fni.Namespace).
+		Patch(ctx, fni.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// deleteTrackedObjects deletes every object with the OwnerLabel of the
+// FunctionIngress, in each namespace the operator watches, which it does not
+// control. Every object is attempted before the errors are returned.
+func (h SyncHandler) deleteTrackedObjects(ctx context.Context, fni *faasv1.FunctionIngress) error {
+	opts := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(controller.MakeOwnerLabels(fni)).String()}
+
+	errs := []error{}
+	for _, namespace := range h.cleanupNamespaces(fni) {
+		ingresses := h.kubeclientset.NetworkingV1().Ingresses(namespace)
+		ingressList, err := ingresses.List(ctx, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot list ingresses in %q: %w", namespace, err))
+		} else {
+			for i := range ingressList.Items {
+				errs = append(errs, deleteTracked(ctx, fni, &ingressList.Items[i], "Ingress", ingresses.Delete))
+			}
+		}
+
+		services := h.kubeclientset.CoreV1().Services(namespace)
+		serviceList, err := services.List(ctx, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot list services in %q: %w", namespace, err))
+		} else {
+			for i := range serviceList.Items {
+				errs = append(errs, deleteTracked(ctx, fni, &serviceList.Items[i], "Service", services.Delete))
+			}
+		}
+
+		for _, resource := range h.trackedResources() {
+			client := h.dynamicclientset.Resource(resource).Namespace(namespace)
+			list, err := client.List(ctx, opts)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("cannot list %s in %q: %w", resource.Resource, namespace, err))
+				continue
+			}
+			deleteFn := func(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+				return client.Delete(ctx, name, opts)
+			}
+			for i := range list.Items {
+				errs = append(errs, deleteTracked(ctx, fni, &list.Items[i], list.Items[i].GetKind(), deleteFn))
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteTracked deletes obj unless the FunctionIngress controls it, or it is
+// already being deleted.
+func deleteTracked(ctx context.Context, fni *faasv1.FunctionIngress, obj metav1.Object, kind string,
+	deleteFn func(context.Context, string, metav1.DeleteOptions) error) error {
+	if metav1.IsControlledBy(obj, fni) || obj.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	klog.Infof("Deleting %s %s/%s tracked by: %s", kind, obj.GetNamespace(), obj.GetName(), fni.Name)
+	if err := deleteFn(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// trackedResources are the resources served as unstructured data which the
+// operator may have created, a nil lister means that it is not served.
+func (h SyncHandler) trackedResources() []schema.GroupVersionResource {
+	resources := []schema.GroupVersionResource{}
+	if h.httpRouteLister != nil {
+		resources = append(resources, controller.HTTPRouteResource)
+	}
+	if h.ingressRouteLister != nil {
+		resources = append(resources, controller.IngressRouteResource)
+	}
+	if h.middlewareLister != nil {
+		resources = append(resources, controller.MiddlewareResource)
+	}
+	if h.certificateLister != nil {
+		resources = append(resources, controller.CertificateResource)
+	}
+	return resources
+}
+
+func (h SyncHandler) cleanupNamespaces(fni *faasv1.FunctionIngress) []string {
+	if len(h.config.Namespaces) == 0 {
+		return []string{fni.Namespace}
+	}
+	return h.config.Namespaces
+}
@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+)
+
+func newTestDeletedFunctionIngress() *faasv1.FunctionIngress {
+	fni := newTestFunctionIngress()
+	fni.UID = "7b1f6a52-9d1c-4c4e-8f5a-0e3b2a1c9d8e"
+	fni.Finalizers = []string{"example.com/other", controller.Finalizer}
+	now := metav1.Now()
+	fni.DeletionTimestamp = &now
+	return fni
+}
+
+func newTrackedService(fni *faasv1.FunctionIngress, namespace, name string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    controller.MakeOwnerLabels(fni),
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "gateway.openfaas.svc.cluster.local"},
+	}
+}
+
+func Test_handler_AddsFinalizer(t *testing.T) {
+	fni := newTestFunctionIngress()
+	h, _, faasClient := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := faasClient.OpenfaasV1().FunctionIngresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get FunctionIngress: %s", err)
+	}
+	if !controller.HasFinalizer(got) {
+		t.Errorf("want finalizer %s, got %v", controller.Finalizer, got.Finalizers)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("want %s to be True after the finalizer is added, got %v", controller.ConditionReady, c)
+	}
+}
+
+func Test_handler_FinalizerDeletesTrackedObjects(t *testing.T) {
+	fni := newTestDeletedFunctionIngress()
+	tracked := newTrackedService(fni, "openfaas-fn", "nodeinfo-gateway")
+	owned := newTrackedService(fni, "openfaas", "nodeinfo-owned")
+	owned.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(fni, faasv1.SchemeGroupVersion.WithKind("FunctionIngress"))}
+	other := newTrackedService(fni, "openfaas-fn", "other")
+	other.Labels = map[string]string{controller.OwnerLabel: "another-uid"}
+
+	h, kubeClient, faasClient := newTestHandler(t, fni, tracked, owned, other)
+	h.config.Namespaces = []string{"openfaas", "openfaas-fn"}
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	services := kubeClient.CoreV1()
+	if _, err := services.Services("openfaas-fn").Get(context.Background(), tracked.Name, metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("want tracked service in another namespace to be deleted, got: %v", err)
+	}
+	if _, err := services.Services("openfaas").Get(context.Background(), owned.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("want owned service to be left to the garbage collector, got: %s", err)
+	}
+	if _, err := services.Services("openfaas-fn").Get(context.Background(), other.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("want service of another FunctionIngress to be kept, got: %s", err)
+	}
+
+	got, err := faasClient.OpenfaasV1().FunctionIngresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get FunctionIngress: %s", err)
+	}
+	if controller.HasFinalizer(got) {
+		t.Errorf("want finalizer to be removed, got %v", got.Finalizers)
+	}
+	if len(got.Finalizers) != 1 || got.Finalizers[0] != "example.com/other" {
+		t.Errorf("want other finalizers to be kept, got %v", got.Finalizers)
+	}
+
+	if _, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("want no ingress to be created for a deleted FunctionIngress, got: %v", err)
+	}
+}
+
+func Test_handler_FinalizerDeletesTrackedDynamicObjects(t *testing.T) {
+	fni := newTestDeletedFunctionIngress()
+	route := makeIngressRoute(fni, testGateway)
+	route.SetNamespace("openfaas-fn")
+	route.SetOwnerReferences(nil)
+
+	h, dynamicClient, _ := newTestDynamicHandler(t, []*unstructured.Unstructured{route}, fni)
+	h.config.Namespaces = []string{"openfaas-fn"}
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err := dynamicClient.Resource(controller.IngressRouteResource).Namespace("openfaas-fn").Get(context.Background(), route.GetName(), metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("want tracked IngressRoute to be deleted, got: %v", err)
+	}
+}
+
+func Test_handler_FinalizerKeptWhenCleanupFails(t *testing.T) {
+	fni := newTestDeletedFunctionIngress()
+	tracked := newTrackedService(fni, "openfaas", "nodeinfo-gateway")
+
+	h, kubeClient, faasClient := newTestHandler(t, fni, tracked)
+	kubeClient.PrependReactor("delete", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("forbidden")
+	})
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err == nil {
+		t.Fatalf("want error to be returned so that the key is requeued")
+	}
+
+	events := drainEvents(h)
+	wantPrefix := corev1.EventTypeWarning + " " + controller.ReasonCleanupFailed
+	if len(events) != 1 || !strings.HasPrefix(events[0], wantPrefix) {
+		t.Errorf("want 1 event with prefix %q, got %v", wantPrefix, events)
+	}
+
+	got, err := faasClient.OpenfaasV1().FunctionIngresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get FunctionIngress: %s", err)
+	}
+	if !controller.HasFinalizer(got) {
+		t.Errorf("want finalizer to be kept until the cleanup succeeds, got %v", got.Finalizers)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionDegraded); c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonCleanupFailed {
+		t.Errorf("want %s to be True with reason %s, got %v", controller.ConditionDegraded, controller.ReasonCleanupFailed, c)
+	}
+}
@@ -0,0 +1,226 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// functionLister finds the Deployments, Services and EndpointSlices of
+// functions in one of the operator's -function-namespaces
+type functionLister struct {
+	deployments    appslisters.DeploymentLister
+	services       corelisters.ServiceLister
+	endpointSlices discoverylisters.EndpointSliceLister
+}
+
+// functionListerFor returns the lister which watches the namespace, the
+// listers are keyed by namespace, or by "" when every namespace is watched.
+func (h SyncHandler) functionListerFor(namespace string) (functionLister, bool) {
+	if lister, ok := h.functionListers[namespace]; ok {
+		return lister, true
+	}
+	lister, ok := h.functionListers[metav1.NamespaceAll]
+	return lister, ok
+}
+
+// setFunctionCondition reports whether each function of the FunctionIngress
+// has a Deployment and a Service. A Warning event is recorded when a
+// function goes missing, rather than on every sync.
+func (h SyncHandler) setFunctionCondition(fni *faasv1.FunctionIngress) {
+	missing := []string{}
+	unwatched := []string{}
+	for _, function := range controller.Functions(fni, h.config.Defaults) {
+		lister, ok := h.functionListerFor(function.Namespace)
+		if !ok {
+			unwatched = append(unwatched, function.Namespace)
+			continue
+		}
+
+		if _, err := lister.deployments.Deployments(function.Namespace).Get(function.Name); errors.IsNotFound(err) {
+			missing = append(missing, function.String())
+			continue
+		}
+		if _, err := lister.services.Services(function.Namespace).Get(function.Name); errors.IsNotFound(err) {
+			missing = append(missing, function.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		msg := fmt.Sprintf("Function %s was not found", strings.Join(missing, ", "))
+		if len(missing) > 1 {
+			msg = fmt.Sprintf("Functions %s were not found", strings.Join(missing, ", "))
+		}
+
+		if c := meta.FindStatusCondition(fni.Status.Conditions, controller.ConditionFunctionAvailable); c == nil || c.Message != msg {
+			h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonFunctionNotFound, msg)
+		}
+		controller.SetCondition(fni, controller.ConditionFunctionAvailable, metav1.ConditionFalse,
+			controller.ReasonFunctionNotFound, msg)
+		return
+	}
+
+	if len(unwatched) > 0 {
+		controller.SetCondition(fni, controller.ConditionFunctionAvailable, metav1.ConditionUnknown,
+			controller.ReasonFunctionNamespaceNotWatched,
+			fmt.Sprintf("Namespace %s is not one of the operator's function namespaces", strings.Join(unwatched, ", ")))
+		return
+	}
+
+	controller.SetCondition(fni, controller.ConditionFunctionAvailable, metav1.ConditionTrue,
+		controller.ReasonFunctionFound, "Every function has a Deployment and a Service")
+}
+
+// functionInformers are the informers of the function namespaces, whose
+// events are handled by each controller
+type functionInformers struct {
+	deployments    []cache.SharedIndexInformer
+	services       []cache.SharedIndexInformer
+	endpointSlices []cache.SharedIndexInformer
+}
+
+func (i functionInformers) all() []cache.SharedIndexInformer {
+	all := append([]cache.SharedIndexInformer{}, i.deployments...)
+	all = append(all, i.services...)
+	return append(all, i.endpointSlices...)
+}
+
+// newFunctionListers creates the listers for the Deployments, Services and
+// EndpointSlices in each function namespace, it returns the informers so
+// that their caches are waited on and their events handled.
+func newFunctionListers(factories map[string]kubeinformers.SharedInformerFactory) (map[string]functionLister, functionInformers) {
+	functionListers := map[string]functionLister{}
+	informers := functionInformers{}
+	for namespace, factory := range factories {
+		deployments := factory.Apps().V1().Deployments()
+		services := factory.Core().V1().Services()
+		endpointSlices := factory.Discovery().V1().EndpointSlices()
+		functionListers[namespace] = functionLister{
+			deployments:    deployments.Lister(),
+			services:       services.Lister(),
+			endpointSlices: endpointSlices.Lister(),
+		}
+		informers.deployments = append(informers.deployments, deployments.Informer())
+		informers.services = append(informers.services, services.Informer())
+		informers.endpointSlices = append(informers.endpointSlices, endpointSlices.Informer())
+	}
+	return functionListers, informers
+}
+
+// functionEventHandler requeues the FunctionIngresses which serve a function
+// when its Deployment or Service is created or deleted, or when its
+// Deployment is scaled to or from zero ready replicas. Neither is owned by
+// the FunctionIngress, so they are matched by name and namespace.
+func functionEventHandler(ctrl controller.BaseController, fniLister listers.FunctionIngressLister, defaults controller.Defaults) cache.ResourceEventHandler {
+	enqueue := func(obj interface{}) {
+		object, ok := functionObject(obj)
+		if !ok {
+			return
+		}
+
+		function := controller.FunctionRef{Name: object.GetName(), Namespace: object.GetNamespace()}
+		for _, fni := range functionIngressesFor(fniLister, defaults, function) {
+			ctrl.EnqueueFunction(fni)
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			oldDeployment, ok := old.(*appsv1.Deployment)
+			if !ok {
+				return
+			}
+			newDeployment, ok := new.(*appsv1.Deployment)
+			if !ok {
+				return
+			}
+			// bypassMode "auto" follows whether the function has a ready replica
+			if (oldDeployment.Status.ReadyReplicas > 0) != (newDeployment.Status.ReadyReplicas > 0) {
+				enqueue(new)
+			}
+		},
+		DeleteFunc: enqueue,
+	}
+}
+
+// endpointSliceEventHandler requeues the FunctionIngresses which mirror the
+// EndpointSlices of a function into their own namespace, whenever the
+// endpoints of the function change.
+func endpointSliceEventHandler(ctrl controller.BaseController, fniLister listers.FunctionIngressLister, defaults controller.Defaults) cache.ResourceEventHandler {
+	enqueue := func(obj interface{}) {
+		object, ok := functionObject(obj)
+		if !ok {
+			return
+		}
+		name, ok := object.GetLabels()[discoveryv1.LabelServiceName]
+		if !ok {
+			return
+		}
+
+		function := controller.FunctionRef{Name: name, Namespace: object.GetNamespace()}
+		for _, fni := range functionIngressesFor(fniLister, defaults, function) {
+			if controller.BypassCrossNamespace(fni, function) {
+				ctrl.EnqueueFunction(fni)
+			}
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			oldSlice, ok := old.(*discoveryv1.EndpointSlice)
+			if !ok {
+				return
+			}
+			newSlice, ok := new.(*discoveryv1.EndpointSlice)
+			if !ok || oldSlice.ResourceVersion == newSlice.ResourceVersion {
+				return
+			}
+			enqueue(new)
+		},
+		DeleteFunc: enqueue,
+	}
+}
+
+// functionObject returns the object of an event, or of its tombstone
+func functionObject(obj interface{}) (metav1.Object, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, ok := obj.(metav1.Object)
+	return object, ok
+}
+
+// functionIngressesFor returns the FunctionIngresses which serve the function
+func functionIngressesFor(fniLister listers.FunctionIngressLister, defaults controller.Defaults, function controller.FunctionRef) []*faasv1.FunctionIngress {
+	fnis, err := fniLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return nil
+	}
+
+	serving := []*faasv1.FunctionIngress{}
+	for _, fni := range fnis {
+		if controller.ServesFunction(fni, defaults, function) {
+			serving = append(serving, fni)
+		}
+	}
+	return serving
+}
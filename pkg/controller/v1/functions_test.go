@@ -0,0 +1,198 @@
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+)
+
+// withFunctions watches the namespace for functions, the listers are
+// populated with the given Deployments, Services and EndpointSlices.
+func withFunctions(t *testing.T, h *SyncHandler, namespace string, objects ...runtime.Object) {
+	t.Helper()
+
+	deploymentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	serviceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	endpointSliceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			deploymentIndexer.Add(o)
+		case *corev1.Service:
+			serviceIndexer.Add(o)
+		case *discoveryv1.EndpointSlice:
+			endpointSliceIndexer.Add(o)
+		default:
+			t.Fatalf("unsupported test object %T", obj)
+		}
+	}
+
+	if h.functionListers == nil {
+		h.functionListers = map[string]functionLister{}
+	}
+	h.functionListers[namespace] = functionLister{
+		deployments:    appslisters.NewDeploymentLister(deploymentIndexer),
+		services:       corelisters.NewServiceLister(serviceIndexer),
+		endpointSlices: discoverylisters.NewEndpointSliceLister(endpointSliceIndexer),
+	}
+}
+
+func newTestFunction(namespace, name string) (*appsv1.Deployment, *corev1.Service) {
+	meta := metav1.ObjectMeta{Name: name, Namespace: namespace}
+	return &appsv1.Deployment{ObjectMeta: meta}, &corev1.Service{ObjectMeta: meta}
+}
+
+func Test_handler_FunctionAvailable(t *testing.T) {
+	fni := newTestFunctionIngress()
+	h, _, faasClient := newTestHandler(t, fni)
+	deployment, service := newTestFunction("openfaas-fn", "nodeinfo")
+	withFunctions(t, &h, "openfaas-fn", deployment, service)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionFunctionAvailable)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonFunctionFound {
+		t.Errorf("want %s to be True, got %v", controller.ConditionFunctionAvailable, c)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("want %s to be True, got %v", controller.ConditionReady, c)
+	}
+}
+
+func Test_handler_FunctionNotFound(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.Routes = []faasv1.FunctionRoute{
+		{Path: "/v1/(.*)"},
+		{Path: "/v2/(.*)", Function: "figlet"},
+	}
+	h, kubeClient, faasClient := newTestHandler(t, fni)
+	deployment, _ := newTestFunction("openfaas-fn", "nodeinfo")
+	withFunctions(t, &h, "openfaas-fn", deployment)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionFunctionAvailable)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonFunctionNotFound {
+		t.Fatalf("want %s to be False, got %v", controller.ConditionFunctionAvailable, c)
+	}
+	want := "Functions nodeinfo.openfaas-fn, figlet.openfaas-fn were not found"
+	if c.Message != want {
+		t.Errorf("want message %q, got %q", want, c.Message)
+	}
+
+	c = getStatusCondition(t, faasClient, controller.ConditionReady)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != controller.ReasonFunctionNotFound {
+		t.Errorf("want %s to be False with reason %s, got %v", controller.ConditionReady, controller.ReasonFunctionNotFound, c)
+	}
+
+	// The Ingress is still created, so that it serves the function once deployed
+	if _, err := kubeClient.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{}); err != nil {
+		t.Errorf("want ingress to be created, got: %s", err)
+	}
+
+	events := drainEvents(h)
+	wantPrefix := corev1.EventTypeWarning + " " + controller.ReasonFunctionNotFound
+	found := false
+	for _, event := range events {
+		found = found || strings.HasPrefix(event, wantPrefix)
+	}
+	if !found {
+		t.Errorf("want event with prefix %q, got %v", wantPrefix, events)
+	}
+}
+
+func Test_handler_FunctionNamespaceNotWatched(t *testing.T) {
+	fni := newTestFunctionIngress()
+	fni.Spec.FunctionNamespace = "staging-fn"
+	h, _, faasClient := newTestHandler(t, fni)
+	withFunctions(t, &h, "openfaas-fn")
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionFunctionAvailable)
+	if c == nil || c.Status != metav1.ConditionUnknown || c.Reason != controller.ReasonFunctionNamespaceNotWatched {
+		t.Errorf("want %s to be Unknown, got %v", controller.ConditionFunctionAvailable, c)
+	}
+	if c := getStatusCondition(t, faasClient, controller.ConditionReady); c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("want %s to be True, got %v", controller.ConditionReady, c)
+	}
+}
+
+func Test_functionEventHandler_EnqueuesServingFunctionIngresses(t *testing.T) {
+	serving := newTestFunctionIngress()
+	other := newTestFunctionIngress()
+	other.Name = "figlet"
+	other.Spec.Function = "figlet"
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(serving)
+	indexer.Add(other)
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(0, 0))
+	defer queue.ShutDown()
+	ctrl := controller.BaseController{Workqueue: queue}
+
+	handler := functionEventHandler(ctrl, listers.NewFunctionIngressLister(indexer), controller.Defaults{})
+	deployment, _ := newTestFunction("openfaas-fn", "nodeinfo")
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "openfaas-fn/nodeinfo", Obj: deployment})
+
+	if queue.Len() != 1 {
+		t.Fatalf("want 1 queued FunctionIngress, got %d", queue.Len())
+	}
+	key, _ := queue.Get()
+	if key != "openfaas/nodeinfo" {
+		t.Errorf("want openfaas/nodeinfo to be queued, got %v", key)
+	}
+}
+
+func Test_endpointSliceEventHandler_EnqueuesBypassingFunctionIngresses(t *testing.T) {
+	bypass := newTestFunctionIngress()
+	bypass.Spec.BypassGateway = true
+	bypass.Spec.FunctionNamespace = "openfaas-fn"
+	gateway := newTestFunctionIngress()
+	gateway.Name = "nodeinfo-gateway"
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(bypass)
+	indexer.Add(gateway)
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(0, 0))
+	defer queue.ShutDown()
+	ctrl := controller.BaseController{Workqueue: queue}
+
+	handler := endpointSliceEventHandler(ctrl, listers.NewFunctionIngressLister(indexer), controller.Defaults{})
+	old := newTestEndpointSlice("openfaas-fn", "nodeinfo-abcde", "nodeinfo", "10.0.0.1")
+	old.ResourceVersion = "1"
+	updated := newTestEndpointSlice("openfaas-fn", "nodeinfo-abcde", "nodeinfo", "10.0.0.2")
+	updated.ResourceVersion = "2"
+	handler.OnUpdate(old, updated)
+
+	if queue.Len() != 1 {
+		t.Fatalf("want 1 queued FunctionIngress, got %d", queue.Len())
+	}
+	key, _ := queue.Get()
+	if key != "openfaas/nodeinfo" {
+		t.Errorf("want openfaas/nodeinfo to be queued, got %v", key)
+	}
+}
@@ -0,0 +1,173 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// syncHTTPRoute converges the Gateway API HTTPRoute for a FunctionIngress
+// with the gateway-api IngressType, it replaces the Ingress record.
+func (h SyncHandler) syncHTTPRoute(ctx context.Context, fni, next *faasv1.FunctionIngress, gateway controller.Gateway) error {
+	name := fni.Name
+
+	if !h.config.HTTPRouteEnabled || h.httpRouteLister == nil {
+		msg := fmt.Sprintf("The %s ingressType requires the %s CRD to be installed", controller.GatewayAPIClass, controller.HTTPRouteResource.GroupResource())
+		h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonHTTPRouteUnsupported, msg)
+		controller.SetDegraded(next, controller.ReasonHTTPRouteUnsupported, msg)
+		return h.updateStatus(ctx, fni, next)
+	}
+
+	desired, err := makeHTTPRoute(fni, h.config.DefaultParentRef, gateway)
+	if err != nil {
+		h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonInvalidSpec, err.Error())
+		controller.SetDegraded(next, controller.ReasonInvalidSpec, err.Error())
+		return h.updateStatus(ctx, fni, next)
+	}
+
+	result, err := h.applyObject(ctx, fni, next, controller.HTTPRouteResource, h.httpRouteLister, desired)
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case applyCreated:
+		controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+			controller.ReasonHTTPRouteCreated, fmt.Sprintf("HTTPRoute %s created", name))
+	case applyUpdated:
+		controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+			controller.ReasonHTTPRouteUpdated, fmt.Sprintf("HTTPRoute %s updated", name))
+	default:
+		if !controller.IsConditionTrue(next, controller.ConditionIngressCreated) {
+			controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+				controller.ReasonHTTPRouteSynced, fmt.Sprintf("HTTPRoute %s is up to date", name))
+		}
+	}
+
+	// The FunctionIngress may have been switched from another IngressType
+	if err := h.deleteStaleObjects(ctx, fni, controller.GatewayAPIClass); err != nil {
+		return err
+	}
+
+	// TLS is terminated by the listener on the Gateway, not by the HTTPRoute
+	controller.RemoveCondition(next, controller.ConditionCertificateReady)
+	controller.SetReady(next)
+
+	h.recorder.Event(fni, corev1.EventTypeNormal, controller.SuccessSynced, controller.MessageResourceSynced)
+	return h.updateStatus(ctx, fni, next)
+}
+
+// httpRouteMatchTypes are the path match types of an HTTPRoute for each
+// value of pathMatch
+var httpRouteMatchTypes = map[string]string{
+	faasv1.PathMatchPrefix: "PathPrefix",
+	faasv1.PathMatchExact:  "Exact",
+	faasv1.PathMatchRegex:  "RegularExpression",
+}
+
+// makeHTTPRoute renders the desired HTTPRoute for the FunctionIngress. The
+// defaulted fields of the HTTPRoute are set explicitly, so that the spec can
+// be compared with the live object.
+func makeHTTPRoute(fni *faasv1.FunctionIngress, defaultParent *faasv1.ParentReference, gateway controller.Gateway) (*unstructured.Unstructured, error) {
+	parent := fni.Spec.ParentRef
+	if parent == nil {
+		parent = defaultParent
+	}
+	if parent == nil || len(parent.Name) == 0 {
+		return nil, fmt.Errorf("a parentRef is required for the %s ingressType", controller.GatewayAPIClass)
+	}
+
+	parentNamespace := parent.Namespace
+	if len(parentNamespace) == 0 {
+		parentNamespace = fni.Namespace
+	}
+
+	parentRef := map[string]interface{}{
+		"group":     controller.HTTPRouteResource.Group,
+		"kind":      "Gateway",
+		"name":      parent.Name,
+		"namespace": parentNamespace,
+	}
+	if len(parent.SectionName) > 0 {
+		parentRef["sectionName"] = parent.SectionName
+	}
+
+	rules := []interface{}{}
+	for _, path := range controller.MakePaths(fni, controller.GatewayAPIClass) {
+		serviceHost, port := controller.Backend(fni, path.Route, gateway)
+
+		rule := map[string]interface{}{
+			"matches": []interface{}{
+				map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":  httpRouteMatchTypes[path.Type],
+						"value": path.Match,
+					},
+				},
+			},
+			"backendRefs": []interface{}{
+				map[string]interface{}{
+					"group":  "",
+					"kind":   "Service",
+					"name":   serviceHost,
+					"port":   int64(port),
+					"weight": int64(1),
+				},
+			},
+		}
+
+		// The URLRewrite filter replaces the rewrite annotations used by
+		// IngressControllers, an exact path is replaced as a whole
+		if !fni.Spec.BypassesGateway() {
+			rewrite := map[string]interface{}{
+				"type":               "ReplacePrefixMatch",
+				"replacePrefixMatch": path.Rewrite,
+			}
+			if path.Type == faasv1.PathMatchExact {
+				rewrite = map[string]interface{}{
+					"type":            "ReplaceFullPath",
+					"replaceFullPath": path.Rewrite,
+				}
+			}
+			rule["filters"] = []interface{}{
+				map[string]interface{}{
+					"type": "URLRewrite",
+					"urlRewrite": map[string]interface{}{
+						"path": rewrite,
+					},
+				},
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetAPIVersion(controller.HTTPRouteResource.GroupVersion().String())
+	route.SetKind("HTTPRoute")
+	route.SetName(fni.Name)
+	route.SetNamespace(fni.Namespace)
+	route.SetOwnerReferences(controller.MakeOwnerRef(fni))
+	route.SetLabels(controller.MakeOwnerLabels(fni))
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules":      rules,
+	}
+
+	// Omitting the hostnames matches every request, as for an Ingress
+	if hosts := fni.Spec.Hosts(); len(hosts) > 0 {
+		hostnames := []interface{}{}
+		for _, host := range hosts {
+			hostnames = append(hostnames, host)
+		}
+		spec["hostnames"] = hostnames
+	}
+	route.Object["spec"] = spec
+
+	return route, nil
+}
@@ -0,0 +1,328 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	faasfake "github.com/openfaas/ingress-operator/pkg/client/clientset/versioned/fake"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+)
+
+// newTestDynamicHandler returns a handler for which the HTTPRoute and
+// Traefik CRDs are installed, objects are added to the listers and to the
+// dynamic fake clientset.
+func newTestDynamicHandler(t *testing.T, dynamicObjects []*unstructured.Unstructured, objects ...runtime.Object) (SyncHandler, *dynamicfake.FakeDynamicClient, *faasfake.Clientset) {
+	t.Helper()
+
+	h, _, faasClient := newTestHandler(t, objects...)
+
+	indexers := map[string]cache.Indexer{}
+	for _, kind := range []string{"HTTPRoute", "IngressRoute", "Middleware", "Certificate"} {
+		indexers[kind] = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+
+	clientObjects := []runtime.Object{}
+	for _, obj := range dynamicObjects {
+		indexer, ok := indexers[obj.GetKind()]
+		if !ok {
+			t.Fatalf("unsupported test object kind %s", obj.GetKind())
+		}
+		indexer.Add(obj)
+		clientObjects = append(clientObjects, obj)
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			controller.HTTPRouteResource:    "HTTPRouteList",
+			controller.IngressRouteResource: "IngressRouteList",
+			controller.MiddlewareResource:   "MiddlewareList",
+			controller.CertificateResource:  "CertificateList",
+		}, clientObjects...)
+
+	h.dynamicclientset = dynamicClient
+	h.httpRouteLister = cache.NewGenericLister(indexers["HTTPRoute"], controller.HTTPRouteResource.GroupResource())
+	h.ingressRouteLister = cache.NewGenericLister(indexers["IngressRoute"], controller.IngressRouteResource.GroupResource())
+	h.middlewareLister = cache.NewGenericLister(indexers["Middleware"], controller.MiddlewareResource.GroupResource())
+	h.certificateLister = cache.NewGenericLister(indexers["Certificate"], controller.CertificateResource.GroupResource())
+	h.config = controller.Config{HTTPRouteEnabled: true, TraefikCRDEnabled: true, CertificateEnabled: true}
+
+	return h, dynamicClient, faasClient
+}
+
+func newTestGatewayAPIFunctionIngress() *faasv1.FunctionIngress {
+	fni := newTestFunctionIngress()
+	fni.Spec.IngressType = controller.GatewayAPIClass
+	fni.Spec.ParentRef = &faasv1.ParentReference{Name: "public", Namespace: "gateway-system"}
+	return fni
+}
+
+func Test_makeHTTPRoute(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	fni.Spec.Path = "/v1/profiles/view/(.*)"
+
+	route, err := makeHTTPRoute(fni, nil, testGateway)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != fni.Spec.Domain {
+		t.Errorf("want hostnames [%s], got %v", fni.Spec.Domain, hostnames)
+	}
+
+	parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	parent := parents[0].(map[string]interface{})
+	if parent["name"] != "public" || parent["namespace"] != "gateway-system" {
+		t.Errorf("want parent gateway-system/public, got %v", parent)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	rule := rules[0].(map[string]interface{})
+
+	match := rule["matches"].([]interface{})[0].(map[string]interface{})
+	if got, _, _ := unstructured.NestedString(match, "path", "value"); got != "/v1/profiles/view" {
+		t.Errorf("want path prefix /v1/profiles/view, got %s", got)
+	}
+
+	backend := rule["backendRefs"].([]interface{})[0].(map[string]interface{})
+	if backend["name"] != "gateway" || backend["port"] != int64(controller.OpenfaasWorkloadPort) {
+		t.Errorf("want backend gateway:%d, got %v", controller.OpenfaasWorkloadPort, backend)
+	}
+
+	filter := rule["filters"].([]interface{})[0].(map[string]interface{})
+	if got, _, _ := unstructured.NestedString(filter, "urlRewrite", "path", "replacePrefixMatch"); got != "/function/nodeinfo" {
+		t.Errorf("want rewrite to /function/nodeinfo, got %s", got)
+	}
+}
+
+func Test_makeHTTPRoute_Domains(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	fni.Spec.Domains = []faasv1.Domain{"www.nodeinfo.example.com", "*.eu.nodeinfo.example.com"}
+
+	route, err := makeHTTPRoute(fni, nil, testGateway)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"nodeinfo.example.com", "www.nodeinfo.example.com", "*.eu.nodeinfo.example.com"}
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if !reflect.DeepEqual(want, hostnames) {
+		t.Errorf("want hostnames %v, got %v", want, hostnames)
+	}
+}
+
+func Test_makeHTTPRoute_Routes(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	fni.Spec.Routes = []faasv1.FunctionRoute{
+		{Path: "/v1/users/(.*)", Function: "users"},
+		{Path: "/v1/orders/(.*)", Function: "orders", Rewrite: "/function/orders/v1"},
+	}
+
+	route, err := makeHTTPRoute(fni, nil, testGateway)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if len(rules) != 2 {
+		t.Fatalf("want 2 rules, got %d", len(rules))
+	}
+
+	want := []struct{ prefix, rewrite string }{
+		{prefix: "/v1/users", rewrite: "/function/users"},
+		{prefix: "/v1/orders", rewrite: "/function/orders/v1"},
+	}
+	for i, w := range want {
+		rule := rules[i].(map[string]interface{})
+		match := rule["matches"].([]interface{})[0].(map[string]interface{})
+		if got, _, _ := unstructured.NestedString(match, "path", "value"); got != w.prefix {
+			t.Errorf("want path prefix %s, got %s", w.prefix, got)
+		}
+		filter := rule["filters"].([]interface{})[0].(map[string]interface{})
+		if got, _, _ := unstructured.NestedString(filter, "urlRewrite", "path", "replacePrefixMatch"); got != w.rewrite {
+			t.Errorf("want rewrite to %s, got %s", w.rewrite, got)
+		}
+	}
+}
+
+func Test_makeHTTPRoute_ExactReplacesFullPath(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	fni.Spec.Path = "/healthz"
+	fni.Spec.PathMatch = faasv1.PathMatchExact
+
+	route, err := makeHTTPRoute(fni, nil, testGateway)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	rule := rules[0].(map[string]interface{})
+	match := rule["matches"].([]interface{})[0].(map[string]interface{})
+	if got, _, _ := unstructured.NestedString(match, "path", "type"); got != "Exact" {
+		t.Errorf("want an Exact match, got %s", got)
+	}
+	filter := rule["filters"].([]interface{})[0].(map[string]interface{})
+	if got, _, _ := unstructured.NestedString(filter, "urlRewrite", "path", "replaceFullPath"); got != "/function/nodeinfo" {
+		t.Errorf("want the full path to be replaced with /function/nodeinfo, got %q", got)
+	}
+}
+
+func Test_makeHTTPRoute_BypassHasNoRewrite(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	fni.Spec.BypassGateway = true
+
+	route, err := makeHTTPRoute(fni, nil, testGateway)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	rule := rules[0].(map[string]interface{})
+
+	if _, ok := rule["filters"]; ok {
+		t.Errorf("want no filters when bypassing the gateway, got %v", rule["filters"])
+	}
+
+	backend := rule["backendRefs"].([]interface{})[0].(map[string]interface{})
+	if backend["name"] != fni.Spec.Function {
+		t.Errorf("want backend %s, got %v", fni.Spec.Function, backend["name"])
+	}
+}
+
+func Test_makeHTTPRoute_DefaultParent(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	fni.Spec.ParentRef = nil
+
+	if _, err := makeHTTPRoute(fni, nil, testGateway); err == nil {
+		t.Fatalf("want error without a parentRef")
+	}
+
+	route, err := makeHTTPRoute(fni, &faasv1.ParentReference{Name: "default"}, testGateway)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	parent := parents[0].(map[string]interface{})
+	if parent["name"] != "default" || parent["namespace"] != fni.Namespace {
+		t.Errorf("want parent %s/default, got %v", fni.Namespace, parent)
+	}
+}
+
+func Test_handler_CreatesHTTPRoute(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	h, dynamicClient, faasClient := newTestDynamicHandler(t, nil, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err := dynamicClient.Resource(controller.HTTPRouteResource).Namespace("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want HTTPRoute to be created, got %s", err)
+	}
+
+	_, err = h.kubeclientset.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("want no Ingress for the %s ingressType", controller.GatewayAPIClass)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionReady)
+	if c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("want Ready True, got %v", c)
+	}
+}
+
+func Test_handler_ReplacesIngressWithHTTPRoute(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	ingress := makeIngress(newTestFunctionIngress(), controller.IngressClass{Name: "nginx", Dialect: "nginx"}, testGateway)
+	h, _, _ := newTestDynamicHandler(t, nil, fni, ingress)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingresses, _ := h.kubeclientset.NetworkingV1().Ingresses("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(ingresses.Items) != 0 {
+		t.Errorf("want the owned Ingress to be deleted, got %d", len(ingresses.Items))
+	}
+}
+
+func Test_handler_KeepsUnmanagedIngressForHTTPRoute(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	h, _, _ := newTestDynamicHandler(t, nil, fni, newUnmanagedIngress())
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ingresses, _ := h.kubeclientset.NetworkingV1().Ingresses("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(ingresses.Items) != 1 {
+		t.Errorf("want the unmanaged Ingress to be kept, got %d", len(ingresses.Items))
+	}
+}
+
+func Test_handler_UpdatesDriftedHTTPRoute(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	live, _ := makeHTTPRoute(fni, nil, testGateway)
+	unstructured.SetNestedStringSlice(live.Object, []string{"other.example.com"}, "spec", "hostnames")
+
+	h, dynamicClient, _ := newTestDynamicHandler(t, []*unstructured.Unstructured{live}, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := dynamicClient.Resource(controller.HTTPRouteResource).Namespace("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	hostnames, _, _ := unstructured.NestedStringSlice(got.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != fni.Spec.Domain {
+		t.Errorf("want hostnames [%s], got %v", fni.Spec.Domain, hostnames)
+	}
+}
+
+func Test_handler_HTTPRouteUnsupportedIsDegraded(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	h, _, faasClient := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionDegraded)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonHTTPRouteUnsupported {
+		t.Errorf("want Degraded True with reason %s, got %v", controller.ReasonHTTPRouteUnsupported, c)
+	}
+}
+
+func Test_handler_IngressTypeDeletesOwnedHTTPRoute(t *testing.T) {
+	fni := newTestGatewayAPIFunctionIngress()
+	route, _ := makeHTTPRoute(fni, nil, testGateway)
+
+	fni.Spec.IngressType = "nginx"
+	h, dynamicClient, _ := newTestDynamicHandler(t, []*unstructured.Unstructured{route}, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	routes, _ := dynamicClient.Resource(controller.HTTPRouteResource).Namespace("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(routes.Items) != 0 {
+		t.Errorf("want the owned HTTPRoute to be deleted, got %d", len(routes.Items))
+	}
+
+	if _, err := h.kubeclientset.NetworkingV1().Ingresses("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{}); err != nil {
+		t.Errorf("want Ingress to be created, got %s", err)
+	}
+}
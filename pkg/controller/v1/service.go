@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	klog "k8s.io/klog"
+)
+
+// syncGatewayService converges the ExternalName Service which points at a
+// gateway in another namespace to the FunctionIngress. The Service is
+// deleted once it is no longer needed, i.e. when the gateway is bypassed.
+func (h SyncHandler) syncGatewayService(ctx context.Context, fni, next *faasv1.FunctionIngress, gateway controller.Gateway) error {
+	if !gateway.CrossNamespace() || fni.Spec.BypassesGateway() {
+		return h.deleteGatewayService(ctx, fni)
+	}
+
+	return h.applyService(ctx, fni, next, makeGatewayService(fni, gateway, h.clusterDomain()))
+}
+
+// applyService creates or updates a Service owned by the FunctionIngress
+func (h SyncHandler) applyService(ctx context.Context, fni, next *faasv1.FunctionIngress, desired *corev1.Service) error {
+	name := desired.Name
+	services := h.kubeclientset.CoreV1().Services(fni.Namespace)
+
+	service, err := h.serviceLister.Services(fni.Namespace).Get(name)
+	if errors.IsNotFound(err) {
+		klog.Infof("Creating Service for: %v", name)
+
+		if _, createErr := services.Create(ctx, desired, metav1.CreateOptions{}); createErr != nil {
+			klog.Errorf("cannot create service: %v in %v, error: %v", name, fni.Namespace, createErr.Error())
+			return h.syncFailed(ctx, fni, next, fmt.Sprintf(controller.ErrObjectCreate, "Service"),
+				fmt.Sprintf(controller.MessageObjectCreate, "Service", name, createErr.Error()), createErr)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get service: %s in %s: %w", name, fni.Namespace, err)
+	}
+
+	if !metav1.IsControlledBy(service, fni) {
+		msg := fmt.Sprintf(controller.MessageResourceExists, name)
+		klog.Errorf("%s in %s", msg, fni.Namespace)
+		return h.syncFailed(ctx, fni, next, controller.ErrResourceExists, msg, fmt.Errorf("%s", msg))
+	}
+
+	if service.Spec.Type == desired.Spec.Type &&
+		service.Spec.ExternalName == desired.Spec.ExternalName &&
+		equality.Semantic.DeepEqual(service.Spec.Ports, desired.Spec.Ports) {
+		return nil
+	}
+
+	klog.Infof("Updating Service for: %s", name)
+
+	updated := service.DeepCopy()
+	updated.Spec.Type = desired.Spec.Type
+	updated.Spec.ExternalName = desired.Spec.ExternalName
+	updated.Spec.Ports = desired.Spec.Ports
+	if desired.Spec.Type == corev1.ServiceTypeExternalName {
+		// an ExternalName Service can not keep the IPs it had as a ClusterIP
+		updated.Spec.ClusterIP = ""
+		updated.Spec.ClusterIPs = nil
+	}
+
+	if _, updateErr := services.Update(ctx, updated, metav1.UpdateOptions{}); updateErr != nil {
+		klog.Errorf("error updating service: %v", updateErr)
+		return h.syncFailed(ctx, fni, next, fmt.Sprintf(controller.ErrObjectUpdate, "Service"),
+			fmt.Sprintf(controller.MessageObjectUpdate, "Service", name, updateErr.Error()), updateErr)
+	}
+	return nil
+}
+
+// deleteGatewayService removes the ExternalName Service of the
+// FunctionIngress, if it has one.
+func (h SyncHandler) deleteGatewayService(ctx context.Context, fni *faasv1.FunctionIngress) error {
+	name := controller.GatewayServiceName(fni)
+
+	service, err := h.serviceLister.Services(fni.Namespace).Get(name)
+	if errors.IsNotFound(err) || (err == nil && !metav1.IsControlledBy(service, fni)) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	klog.Infof("Deleting Service no longer used by: %s", fni.Name)
+	err = h.kubeclientset.CoreV1().Services(fni.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete service: %s in %s: %w", name, fni.Namespace, err)
+	}
+	return nil
+}
+
+// makeGatewayService renders an ExternalName Service in the namespace of the
+// FunctionIngress for the gateway in another namespace.
+func makeGatewayService(fni *faasv1.FunctionIngress, gateway controller.Gateway, clusterDomain string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            controller.GatewayServiceName(fni),
+			Namespace:       fni.Namespace,
+			Labels:          controller.MakeOwnerLabels(fni),
+			OwnerReferences: controller.MakeOwnerRef(fni),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: gateway.ExternalName(clusterDomain),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       gateway.Port,
+					TargetPort: intstr.FromInt32(gateway.Port),
+				},
+			},
+		},
+	}
+}
+
+func (h SyncHandler) clusterDomain() string {
+	if len(h.config.ClusterDomain) == 0 {
+		return "cluster.local"
+	}
+	return h.config.ClusterDomain
+}
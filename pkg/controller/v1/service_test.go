@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestCrossNamespaceFunctionIngress() *faasv1.FunctionIngress {
+	fni := newTestFunctionIngress()
+	fni.Namespace = "team-a"
+	fni.Spec.Gateway = &faasv1.GatewayReference{
+		Name:      "openfaas-gateway",
+		Namespace: "openfaas",
+		Port:      8081,
+	}
+	return fni
+}
+
+func Test_makeRules_GatewayPort(t *testing.T) {
+	fni := newTestFunctionIngress()
+	gateway := controller.ResolveGateway(fni, faasv1.GatewayReference{Name: "openfaas-gateway", Port: 80})
+
+	backend := makeRules(fni, "nginx", gateway)[0].HTTP.Paths[0].Backend.Service
+	if backend.Name != "openfaas-gateway" {
+		t.Errorf("want backend openfaas-gateway, got %s", backend.Name)
+	}
+	if backend.Port.Number != 80 {
+		t.Errorf("want port 80, got %d", backend.Port.Number)
+	}
+}
+
+func Test_handler_CreatesExternalNameServiceForCrossNamespaceGateway(t *testing.T) {
+	fni := newTestCrossNamespaceFunctionIngress()
+	h, kubeClient, _ := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "team-a/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	service, err := kubeClient.CoreV1().Services("team-a").Get(context.Background(), "nodeinfo-gateway", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want an ExternalName Service, got: %s", err)
+	}
+	if service.Spec.Type != corev1.ServiceTypeExternalName {
+		t.Errorf("want type %s, got %s", corev1.ServiceTypeExternalName, service.Spec.Type)
+	}
+	if want := "openfaas-gateway.openfaas.svc.cluster.local"; service.Spec.ExternalName != want {
+		t.Errorf("want externalName %s, got %s", want, service.Spec.ExternalName)
+	}
+	if !metav1.IsControlledBy(service, fni) {
+		t.Errorf("want the Service to be owned by the FunctionIngress")
+	}
+
+	ingress, err := kubeClient.NetworkingV1().Ingresses("team-a").Get(context.Background(), "nodeinfo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+	if backend.Name != "nodeinfo-gateway" || backend.Port.Number != 8081 {
+		t.Errorf("want backend nodeinfo-gateway:8081, got %s:%d", backend.Name, backend.Port.Number)
+	}
+}
+
+func Test_handler_CreatesExternalNameServiceForGatewayNamedLikeIt(t *testing.T) {
+	fni := newTestCrossNamespaceFunctionIngress()
+	fni.Spec.Gateway.Name = controller.GatewayServiceName(fni)
+	h, kubeClient, _ := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "team-a/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	service, err := kubeClient.CoreV1().Services("team-a").Get(context.Background(), "nodeinfo-gateway", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want an ExternalName Service, got: %s", err)
+	}
+	if want := "nodeinfo-gateway.openfaas.svc.cluster.local"; service.Spec.ExternalName != want {
+		t.Errorf("want externalName %s, got %s", want, service.Spec.ExternalName)
+	}
+}
+
+func Test_handler_UpdatesExternalNameService(t *testing.T) {
+	fni := newTestCrossNamespaceFunctionIngress()
+	gateway := controller.ResolveGateway(fni, faasv1.GatewayReference{})
+	live := makeGatewayService(fni, gateway, "cluster.local")
+	live.Spec.ExternalName = "gateway.elsewhere.svc.cluster.local"
+
+	h, kubeClient, _ := newTestHandler(t, fni, live)
+
+	if err := h.handler(context.Background(), "team-a/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	service, err := kubeClient.CoreV1().Services("team-a").Get(context.Background(), "nodeinfo-gateway", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := "openfaas-gateway.openfaas.svc.cluster.local"; service.Spec.ExternalName != want {
+		t.Errorf("want externalName %s, got %s", want, service.Spec.ExternalName)
+	}
+}
+
+func Test_handler_DeletesExternalNameServiceWhenSameNamespace(t *testing.T) {
+	fni := newTestCrossNamespaceFunctionIngress()
+	gateway := controller.ResolveGateway(fni, faasv1.GatewayReference{})
+	live := makeGatewayService(fni, gateway, "cluster.local")
+
+	fni.Spec.Gateway = nil
+	h, kubeClient, _ := newTestHandler(t, fni, live)
+
+	if err := h.handler(context.Background(), "team-a/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err := kubeClient.CoreV1().Services("team-a").Get(context.Background(), "nodeinfo-gateway", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("want the ExternalName Service to be deleted, got: %v", err)
+	}
+}
+
+func Test_handler_DoesNotReplaceUnmanagedService(t *testing.T) {
+	fni := newTestCrossNamespaceFunctionIngress()
+	existing := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo-gateway", Namespace: "team-a"},
+	}
+	h, _, faasClient := newTestHandler(t, fni, existing)
+
+	if err := h.handler(context.Background(), "team-a/nodeinfo"); err == nil {
+		t.Fatalf("want an error for an unmanaged Service")
+	}
+
+	c := getStatusConditionIn(t, faasClient, "team-a", controller.ConditionDegraded)
+	if c == nil || c.Reason != controller.ErrResourceExists {
+		t.Errorf("want Degraded with reason %s, got %v", controller.ErrResourceExists, c)
+	}
+}
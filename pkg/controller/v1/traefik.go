@@ -0,0 +1,221 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// syncIngressRoute converges the Traefik IngressRoute and Middleware for a
+// FunctionIngress with the traefik-crd IngressType, they replace the Ingress
+// record and the rewrite annotations that Traefik v2 and above ignore.
+func (h SyncHandler) syncIngressRoute(ctx context.Context, fni, next *faasv1.FunctionIngress, gateway controller.Gateway) error {
+	name := fni.Name
+
+	if !h.config.TraefikCRDEnabled || h.ingressRouteLister == nil || h.middlewareLister == nil {
+		msg := fmt.Sprintf("The %s ingressType requires the %s and %s CRDs to be installed", controller.TraefikCRDClass,
+			controller.IngressRouteResource.GroupResource(), controller.MiddlewareResource.GroupResource())
+		h.recorder.Event(fni, corev1.EventTypeWarning, controller.ReasonIngressRouteUnsupported, msg)
+		controller.SetDegraded(next, controller.ReasonIngressRouteUnsupported, msg)
+		return h.updateStatus(ctx, fni, next)
+	}
+
+	// The Middlewares are applied first, so that the IngressRoute never
+	// references a Middleware which does not exist yet.
+	updated := false
+	keep := map[string]bool{}
+	for _, middleware := range makeMiddlewares(fni) {
+		result, err := h.applyObject(ctx, fni, next, controller.MiddlewareResource, h.middlewareLister, middleware)
+		if err != nil {
+			return err
+		}
+		updated = updated || result != applyUnchanged
+		keep[middleware.GetName()] = true
+	}
+
+	result, err := h.applyObject(ctx, fni, next, controller.IngressRouteResource, h.ingressRouteLister, makeIngressRoute(fni, gateway))
+	if err != nil {
+		return err
+	}
+
+	// Middlewares are no longer needed for removed routes, or once the
+	// gateway is bypassed
+	if err := h.deleteOwnedObjectsExcept(ctx, fni, controller.MiddlewareResource, h.middlewareLister, keep); err != nil {
+		return err
+	}
+
+	switch {
+	case result == applyCreated:
+		controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+			controller.ReasonIngressRouteCreated, fmt.Sprintf("IngressRoute %s created", name))
+	case result == applyUpdated || updated:
+		controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+			controller.ReasonIngressRouteUpdated, fmt.Sprintf("IngressRoute %s updated", name))
+	case !controller.IsConditionTrue(next, controller.ConditionIngressCreated):
+		controller.SetCondition(next, controller.ConditionIngressCreated, metav1.ConditionTrue,
+			controller.ReasonIngressRouteSynced, fmt.Sprintf("IngressRoute %s is up to date", name))
+	}
+
+	// The FunctionIngress may have been switched from another IngressType
+	if err := h.deleteStaleObjects(ctx, fni, controller.TraefikCRDClass); err != nil {
+		return err
+	}
+
+	h.setCertificateCondition(next)
+	controller.SetReady(next)
+
+	h.recorder.Event(fni, corev1.EventTypeNormal, controller.SuccessSynced, controller.MessageResourceSynced)
+	return h.updateStatus(ctx, fni, next)
+}
+
+// makeIngressRoute renders the desired Traefik IngressRoute for the
+// FunctionIngress.
+func makeIngressRoute(fni *faasv1.FunctionIngress, gateway controller.Gateway) *unstructured.Unstructured {
+	routes := []interface{}{}
+	for i, path := range controller.MakePaths(fni, controller.TraefikCRDClass) {
+		serviceHost, port := controller.Backend(fni, path.Route, gateway)
+
+		route := map[string]interface{}{
+			"kind":  "Rule",
+			"match": ingressRouteMatch(fni, path),
+			"services": []interface{}{
+				map[string]interface{}{
+					"name": serviceHost,
+					"port": int64(port),
+				},
+			},
+		}
+
+		if !fni.Spec.BypassesGateway() {
+			route["middlewares"] = []interface{}{
+				map[string]interface{}{
+					"name": middlewareName(fni, i),
+				},
+			}
+		}
+
+		routes = append(routes, route)
+	}
+
+	spec := map[string]interface{}{
+		"routes": routes,
+	}
+
+	// cert-manager does not issue certificates for IngressRoutes, so the
+	// secret has to be populated by a Certificate
+	if fni.Spec.UseTLS() {
+		spec["tls"] = map[string]interface{}{
+			"secretName": tlsSecretName(fni),
+		}
+	}
+
+	ingressRoute := &unstructured.Unstructured{}
+	ingressRoute.SetAPIVersion(controller.IngressRouteResource.GroupVersion().String())
+	ingressRoute.SetKind("IngressRoute")
+	ingressRoute.SetName(fni.Name)
+	ingressRoute.SetNamespace(fni.Namespace)
+	ingressRoute.SetOwnerReferences(controller.MakeOwnerRef(fni))
+	ingressRoute.SetLabels(controller.MakeOwnerLabels(fni))
+	ingressRoute.Object["spec"] = spec
+
+	return ingressRoute
+}
+
+// ingressRouteMatch returns the rule for the hosts and a path, such as
+// "Host(`a.example.com`) && PathPrefix(`/`)". A wildcard host is matched with
+// HostRegexp, and a regular expression path with PathRegexp, which both take
+// a regular expression from Traefik v3 onwards.
+func ingressRouteMatch(fni *faasv1.FunctionIngress, path controller.RoutePath) string {
+	matchers := []string{}
+	for _, host := range fni.Spec.Hosts() {
+		if suffix, ok := strings.CutPrefix(host, "*."); ok {
+			matchers = append(matchers, fmt.Sprintf("HostRegexp(`^[^.]+\\.%s$`)", regexp.QuoteMeta(suffix)))
+			continue
+		}
+		matchers = append(matchers, fmt.Sprintf("Host(`%s`)", host))
+	}
+
+	var pathMatch string
+	switch path.Type {
+	case faasv1.PathMatchExact:
+		pathMatch = fmt.Sprintf("Path(`%s`)", path.Match)
+	case faasv1.PathMatchRegex:
+		pathMatch = fmt.Sprintf("PathRegexp(`^%s`)", path.Match)
+	default:
+		pathMatch = fmt.Sprintf("PathPrefix(`%s`)", path.Match)
+	}
+	switch len(matchers) {
+	case 0:
+		return pathMatch
+	case 1:
+		return matchers[0] + " && " + pathMatch
+	default:
+		return "(" + strings.Join(matchers, " || ") + ") && " + pathMatch
+	}
+}
+
+// makeMiddlewares renders a Middleware for each route which rewrites its
+// path to the function on the gateway, there are none when the gateway is
+// bypassed. The root prefix only needs an AddPrefix and an exact path a
+// ReplacePath, any other path is replaced with a ReplacePathRegex so that
+// the matched prefix itself is not sent to the function.
+func makeMiddlewares(fni *faasv1.FunctionIngress) []*unstructured.Unstructured {
+	middlewares := []*unstructured.Unstructured{}
+	if fni.Spec.BypassesGateway() {
+		return middlewares
+	}
+
+	for i, path := range controller.MakePaths(fni, controller.TraefikCRDClass) {
+		var spec map[string]interface{}
+		switch {
+		case path.Type == faasv1.PathMatchPrefix && path.Match == "/":
+			spec = map[string]interface{}{
+				"addPrefix": map[string]interface{}{
+					"prefix": path.Rewrite,
+				},
+			}
+		case path.Type == faasv1.PathMatchExact:
+			spec = map[string]interface{}{
+				"replacePath": map[string]interface{}{
+					"path": path.Rewrite,
+				},
+			}
+		default:
+			spec = map[string]interface{}{
+				"replacePathRegex": map[string]interface{}{
+					"regex":       "^" + path.Regex,
+					"replacement": path.Replacement,
+				},
+			}
+		}
+
+		middleware := &unstructured.Unstructured{}
+		middleware.SetAPIVersion(controller.MiddlewareResource.GroupVersion().String())
+		middleware.SetKind("Middleware")
+		middleware.SetName(middlewareName(fni, i))
+		middleware.SetNamespace(fni.Namespace)
+		middleware.SetOwnerReferences(controller.MakeOwnerRef(fni))
+		middleware.SetLabels(controller.MakeOwnerLabels(fni))
+		middleware.Object["spec"] = spec
+
+		middlewares = append(middlewares, middleware)
+	}
+
+	return middlewares
+}
+
+// middlewareName is the name of the Middleware which rewrites the path of
+// the route at index i, the first keeps the name used before routes existed.
+func middlewareName(fni *faasv1.FunctionIngress, i int) string {
+	if i == 0 {
+		return fni.Name + "-rewrite"
+	}
+	return fmt.Sprintf("%s-rewrite-%d", fni.Name, i)
+}
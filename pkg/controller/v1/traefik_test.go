@@ -0,0 +1,362 @@
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+)
+
+func newTestTraefikFunctionIngress() *faasv1.FunctionIngress {
+	fni := newTestFunctionIngress()
+	fni.Spec.IngressType = controller.TraefikCRDClass
+	return fni
+}
+
+func Test_makeIngressRoute(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	fni.Spec.Path = "/v1/profiles/view/(.*)"
+
+	ingressRoute := makeIngressRoute(fni, testGateway)
+
+	routes, _, _ := unstructured.NestedSlice(ingressRoute.Object, "spec", "routes")
+	route := routes[0].(map[string]interface{})
+
+	wantMatch := "Host(`nodeinfo.example.com`) && PathPrefix(`/v1/profiles/view`)"
+	if route["match"] != wantMatch {
+		t.Errorf("want match %s, got %v", wantMatch, route["match"])
+	}
+
+	service := route["services"].([]interface{})[0].(map[string]interface{})
+	if service["name"] != "gateway" || service["port"] != int64(controller.OpenfaasWorkloadPort) {
+		t.Errorf("want service gateway:%d, got %v", controller.OpenfaasWorkloadPort, service)
+	}
+
+	middleware := route["middlewares"].([]interface{})[0].(map[string]interface{})
+	if middleware["name"] != "nodeinfo-rewrite" {
+		t.Errorf("want middleware nodeinfo-rewrite, got %v", middleware["name"])
+	}
+
+	if _, ok, _ := unstructured.NestedMap(ingressRoute.Object, "spec", "tls"); ok {
+		t.Errorf("want no tls when TLS is disabled")
+	}
+}
+
+func Test_ingressRouteMatch(t *testing.T) {
+	cases := []struct {
+		name    string
+		domain  string
+		domains []faasv1.Domain
+		want    string
+	}{
+		{
+			name: "no domain matches the path only",
+			want: "PathPrefix(`/`)",
+		},
+		{
+			name:   "single domain",
+			domain: "api.example.com",
+			want:   "Host(`api.example.com`) && PathPrefix(`/`)",
+		},
+		{
+			name:    "domains and a wildcard",
+			domain:  "api.example.com",
+			domains: []faasv1.Domain{"www.api.example.com", "*.eu.api.example.com"},
+			want:    "(Host(`api.example.com`) || Host(`www.api.example.com`) || HostRegexp(`^[^.]+\\.eu\\.api\\.example\\.com$`)) && PathPrefix(`/`)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := newTestTraefikFunctionIngress()
+			fni.Spec.Domain = tc.domain
+			fni.Spec.Domains = tc.domains
+
+			if got := ingressRouteMatch(fni, controller.MakePaths(fni, controller.TraefikCRDClass)[0]); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_makeIngressRoute_PathMatch(t *testing.T) {
+	cases := []struct {
+		name           string
+		pathMatch      string
+		path           string
+		wantMatch      string
+		wantMiddleware map[string]interface{}
+	}{
+		{
+			name:      "exact",
+			pathMatch: faasv1.PathMatchExact,
+			path:      "/healthz",
+			wantMatch: "Host(`nodeinfo.example.com`) && Path(`/healthz`)",
+			wantMiddleware: map[string]interface{}{
+				"replacePath": map[string]interface{}{"path": "/function/nodeinfo"},
+			},
+		},
+		{
+			name:      "regex",
+			pathMatch: faasv1.PathMatchRegex,
+			path:      "/v[0-9]+/(.*)",
+			wantMatch: "Host(`nodeinfo.example.com`) && PathRegexp(`^/v[0-9]+/(.*)`)",
+			wantMiddleware: map[string]interface{}{
+				"replacePathRegex": map[string]interface{}{
+					"regex":       "^/v[0-9]+/(.*)",
+					"replacement": "/function/nodeinfo/$1",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := newTestTraefikFunctionIngress()
+			fni.Spec.Path = tc.path
+			fni.Spec.PathMatch = tc.pathMatch
+
+			routes, _, _ := unstructured.NestedSlice(makeIngressRoute(fni, testGateway).Object, "spec", "routes")
+			if got := routes[0].(map[string]interface{})["match"]; got != tc.wantMatch {
+				t.Errorf("want match %s, got %v", tc.wantMatch, got)
+			}
+
+			if got := makeMiddlewares(fni)[0].Object["spec"]; !reflect.DeepEqual(got, tc.wantMiddleware) {
+				t.Errorf("want %v, got %v", tc.wantMiddleware, got)
+			}
+		})
+	}
+}
+
+func Test_makeIngressRoute_TLS(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{
+		Enabled: true,
+		IssuerRef: faasv1.ObjectReference{
+			Name: "letsencrypt-prod",
+		},
+	}
+
+	ingressRoute := makeIngressRoute(fni, testGateway)
+
+	got, _, _ := unstructured.NestedString(ingressRoute.Object, "spec", "tls", "secretName")
+	if got != tlsSecretName(fni) {
+		t.Errorf("want tls secret %s, got %s", tlsSecretName(fni), got)
+	}
+}
+
+func Test_makeIngressRoute_Bypass(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	fni.Spec.BypassGateway = true
+
+	routes, _, _ := unstructured.NestedSlice(makeIngressRoute(fni, testGateway).Object, "spec", "routes")
+	route := routes[0].(map[string]interface{})
+
+	if _, ok := route["middlewares"]; ok {
+		t.Errorf("want no middlewares when bypassing the gateway")
+	}
+
+	service := route["services"].([]interface{})[0].(map[string]interface{})
+	if service["name"] != fni.Spec.Function {
+		t.Errorf("want service %s, got %v", fni.Spec.Function, service["name"])
+	}
+
+	if len(makeMiddlewares(fni)) != 0 {
+		t.Errorf("want no Middleware when bypassing the gateway")
+	}
+}
+
+func Test_makeMiddleware(t *testing.T) {
+	cases := []struct {
+		name              string
+		path              string
+		functionNamespace string
+		want              map[string]interface{}
+	}{
+		{
+			name: "root path adds a prefix",
+			want: map[string]interface{}{
+				"addPrefix": map[string]interface{}{"prefix": "/function/nodeinfo"},
+			},
+		},
+		{
+			name:              "root path with function namespace",
+			path:              "/",
+			functionNamespace: "staging-fn",
+			want: map[string]interface{}{
+				"addPrefix": map[string]interface{}{"prefix": "/function/nodeinfo.staging-fn"},
+			},
+		},
+		{
+			name: "nested path replaces the prefix",
+			path: "/v1/profiles/view/(.*)",
+			want: map[string]interface{}{
+				"replacePathRegex": map[string]interface{}{
+					"regex":       "^/v1/profiles/view(?:/|$)(.*)",
+					"replacement": "/function/nodeinfo/$1",
+				},
+			},
+		},
+		{
+			name: "literal characters are escaped",
+			path: "/v1.2",
+			want: map[string]interface{}{
+				"replacePathRegex": map[string]interface{}{
+					"regex":       `^/v1\.2(?:/|$)(.*)`,
+					"replacement": "/function/nodeinfo/$1",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := newTestTraefikFunctionIngress()
+			fni.Spec.Path = tc.path
+			fni.Spec.FunctionNamespace = tc.functionNamespace
+
+			got, _, _ := unstructured.NestedMap(makeMiddlewares(fni)[0].Object, "spec")
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_makeMiddlewares_Routes(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	fni.Spec.Routes = []faasv1.FunctionRoute{
+		{Path: "/v1/users/(.*)", Function: "users"},
+		{Path: "/v1/orders/(.*)", Function: "orders"},
+	}
+
+	middlewares := makeMiddlewares(fni)
+	if len(middlewares) != 2 {
+		t.Fatalf("want 2 Middlewares, got %d", len(middlewares))
+	}
+
+	for i, want := range []string{"nodeinfo-rewrite", "nodeinfo-rewrite-1"} {
+		if got := middlewares[i].GetName(); got != want {
+			t.Errorf("want Middleware %s, got %s", want, got)
+		}
+	}
+
+	routes, _, _ := unstructured.NestedSlice(makeIngressRoute(fni, testGateway).Object, "spec", "routes")
+	route := routes[1].(map[string]interface{})
+	if got := route["middlewares"].([]interface{})[0].(map[string]interface{})["name"]; got != "nodeinfo-rewrite-1" {
+		t.Errorf("want the second route to use nodeinfo-rewrite-1, got %v", got)
+	}
+	if got := route["services"].([]interface{})[0].(map[string]interface{})["name"]; got != "gateway" {
+		t.Errorf("want the second route to use the gateway, got %v", got)
+	}
+}
+
+func Test_handler_DeletesMiddlewareOfRemovedRoute(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	fni.Spec.Routes = []faasv1.FunctionRoute{
+		{Path: "/v1/users/(.*)", Function: "users"},
+		{Path: "/v1/orders/(.*)", Function: "orders"},
+	}
+	existing := makeMiddlewares(fni)
+	fni.Spec.Routes = fni.Spec.Routes[:1]
+
+	h, dynamicClient, _ := newTestDynamicHandler(t, existing, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	middlewares, _ := dynamicClient.Resource(controller.MiddlewareResource).Namespace("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(middlewares.Items) != 1 || middlewares.Items[0].GetName() != "nodeinfo-rewrite" {
+		t.Errorf("want only nodeinfo-rewrite to remain, got %d Middlewares", len(middlewares.Items))
+	}
+}
+
+func Test_handler_CreatesIngressRouteAndMiddleware(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	ingress := makeIngress(newTestFunctionIngress(), controller.IngressClass{Name: "nginx", Dialect: "nginx"}, testGateway)
+	h, dynamicClient, faasClient := newTestDynamicHandler(t, nil, fni, ingress)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := dynamicClient.Resource(controller.IngressRouteResource).Namespace("openfaas").Get(context.Background(), "nodeinfo", metav1.GetOptions{}); err != nil {
+		t.Fatalf("want IngressRoute to be created, got %s", err)
+	}
+	if _, err := dynamicClient.Resource(controller.MiddlewareResource).Namespace("openfaas").Get(context.Background(), "nodeinfo-rewrite", metav1.GetOptions{}); err != nil {
+		t.Fatalf("want Middleware to be created, got %s", err)
+	}
+
+	ingresses, _ := h.kubeclientset.NetworkingV1().Ingresses("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(ingresses.Items) != 0 {
+		t.Errorf("want the owned Ingress to be deleted, got %d", len(ingresses.Items))
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionIngressCreated)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonIngressRouteCreated {
+		t.Errorf("want IngressCreated True with reason %s, got %v", controller.ReasonIngressRouteCreated, c)
+	}
+}
+
+func Test_handler_UpdatesDriftedMiddleware(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	middleware := makeMiddlewares(fni)[0]
+	unstructured.SetNestedField(middleware.Object, "/function/other", "spec", "addPrefix", "prefix")
+
+	h, dynamicClient, faasClient := newTestDynamicHandler(t, []*unstructured.Unstructured{makeIngressRoute(fni, testGateway), middleware}, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := dynamicClient.Resource(controller.MiddlewareResource).Namespace("openfaas").Get(context.Background(), "nodeinfo-rewrite", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if prefix, _, _ := unstructured.NestedString(got.Object, "spec", "addPrefix", "prefix"); prefix != "/function/nodeinfo" {
+		t.Errorf("want prefix /function/nodeinfo, got %s", prefix)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionIngressCreated)
+	if c == nil || c.Reason != controller.ReasonIngressRouteUpdated {
+		t.Errorf("want IngressCreated with reason %s, got %v", controller.ReasonIngressRouteUpdated, c)
+	}
+}
+
+func Test_handler_BypassDeletesMiddleware(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	middleware := makeMiddlewares(fni)[0]
+	fni.Spec.BypassGateway = true
+	fni.Spec.FunctionNamespace = fni.Namespace
+
+	h, dynamicClient, _ := newTestDynamicHandler(t, []*unstructured.Unstructured{middleware}, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	middlewares, _ := dynamicClient.Resource(controller.MiddlewareResource).Namespace("openfaas").List(context.Background(), metav1.ListOptions{})
+	if len(middlewares.Items) != 0 {
+		t.Errorf("want the Middleware to be deleted, got %d", len(middlewares.Items))
+	}
+}
+
+func Test_handler_TraefikCRDUnsupportedIsDegraded(t *testing.T) {
+	fni := newTestTraefikFunctionIngress()
+	h, _, faasClient := newTestHandler(t, fni)
+
+	if err := h.handler(context.Background(), "openfaas/nodeinfo"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c := getStatusCondition(t, faasClient, controller.ConditionDegraded)
+	if c == nil || c.Status != metav1.ConditionTrue || c.Reason != controller.ReasonIngressRouteUnsupported {
+		t.Errorf("want Degraded True with reason %s, got %v", controller.ReasonIngressRouteUnsupported, c)
+	}
+}
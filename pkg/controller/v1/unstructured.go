@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog"
+)
+
+// applyResult describes what applyObject had to do to converge an object
+type applyResult int
+
+const (
+	applyUnchanged applyResult = iota
+	applyCreated
+	applyUpdated
+)
+
+// applyObject creates or updates an unstructured object, such as an
+// HTTPRoute, which is rendered instead of an Ingress. Only the spec is
+// compared and written. Failures are recorded with syncFailed, so the caller
+// only has to return the error.
+func (h SyncHandler) applyObject(ctx context.Context, fni, next *faasv1.FunctionIngress, resource schema.GroupVersionResource, lister cache.GenericLister, desired *unstructured.Unstructured) (applyResult, error) {
+	kind := desired.GetKind()
+	name := desired.GetName()
+	namespace := desired.GetNamespace()
+	client := h.dynamicclientset.Resource(resource).Namespace(namespace)
+
+	obj, err := lister.ByNamespace(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		klog.Infof("Creating %s for: %v", kind, name)
+
+		if _, createErr := client.Create(ctx, desired, metav1.CreateOptions{}); createErr != nil {
+			klog.Errorf("cannot create %s: %v in %v, error: %v", kind, name, namespace, createErr.Error())
+			return applyUnchanged, h.syncFailed(ctx, fni, next, fmt.Sprintf(controller.ErrObjectCreate, kind),
+				fmt.Sprintf(controller.MessageObjectCreate, kind, name, createErr.Error()), createErr)
+		}
+		return applyCreated, nil
+	} else if err != nil {
+		return applyUnchanged, fmt.Errorf("cannot get %s: %s in %s: %w", kind, name, namespace, err)
+	}
+
+	live, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return applyUnchanged, fmt.Errorf("unexpected type %T for %s: %s in %s", obj, kind, name, namespace)
+	}
+
+	adopt := false
+	if !metav1.IsControlledBy(live, fni) {
+		if metav1.GetControllerOf(live) != nil || !controller.CanAdopt(fni) {
+			msg := fmt.Sprintf(controller.MessageResourceExists, name)
+			klog.Errorf("%s in %s", msg, namespace)
+			return applyUnchanged, h.syncFailed(ctx, fni, next, controller.ErrResourceExists, msg, fmt.Errorf("%s", msg))
+		}
+		adopt = true
+	}
+
+	if !adopt && equality.Semantic.DeepEqual(desired.Object["spec"], live.Object["spec"]) {
+		return applyUnchanged, nil
+	}
+
+	klog.Infof("Updating %s for: %s", kind, name)
+
+	updated := live.DeepCopy()
+	updated.Object["spec"] = desired.Object["spec"]
+	if adopt {
+		updated.SetOwnerReferences(append(updated.GetOwnerReferences(), controller.MakeOwnerRef(fni)...))
+	}
+
+	if _, updateErr := client.Update(ctx, updated, metav1.UpdateOptions{}); updateErr != nil {
+		klog.Errorf("error updating %s: %v", kind, updateErr)
+		return applyUnchanged, h.syncFailed(ctx, fni, next, fmt.Sprintf(controller.ErrObjectUpdate, kind),
+			fmt.Sprintf(controller.MessageObjectUpdate, kind, name, updateErr.Error()), updateErr)
+	}
+	return applyUpdated, nil
+}
+
+// deleteOwnedObject removes an unstructured object controlled by the
+// FunctionIngress, for instance when the IngressType has changed. A nil
+// lister means that the resource is not served, so there is nothing to delete.
+func (h SyncHandler) deleteOwnedObject(ctx context.Context, fni *faasv1.FunctionIngress, resource schema.GroupVersionResource, lister cache.GenericLister, name string) error {
+	if lister == nil {
+		return nil
+	}
+
+	obj, err := lister.ByNamespace(fni.Namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if object, ok := obj.(metav1.Object); !ok || !metav1.IsControlledBy(object, fni) {
+		return nil
+	}
+
+	klog.Infof("Deleting %s no longer used by: %s", resource.Resource, fni.Name)
+	err = h.dynamicclientset.Resource(resource).Namespace(fni.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete %s: %s in %s: %w", resource.Resource, name, fni.Namespace, err)
+	}
+	return nil
+}
+
+// deleteOwnedObjectsExcept removes every object of the resource which is
+// controlled by the FunctionIngress, other than those named in keep.
+func (h SyncHandler) deleteOwnedObjectsExcept(ctx context.Context, fni *faasv1.FunctionIngress, resource schema.GroupVersionResource, lister cache.GenericLister, keep map[string]bool) error {
+	if lister == nil {
+		return nil
+	}
+
+	objs, err := lister.ByNamespace(fni.Namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		object, ok := obj.(metav1.Object)
+		if !ok || keep[object.GetName()] || !metav1.IsControlledBy(object, fni) {
+			continue
+		}
+		if err := h.deleteOwnedObject(ctx, fni, resource, lister, object.GetName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteOwnedIngress removes the Ingress for a FunctionIngress which now
+// uses an IngressType that renders other objects.
+func (h SyncHandler) deleteOwnedIngress(ctx context.Context, fni *faasv1.FunctionIngress) error {
+	ingress, err := h.ingressLister.Ingresses(fni.Namespace).Get(fni.Name)
+	if errors.IsNotFound(err) || (err == nil && !metav1.IsControlledBy(ingress, fni)) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	klog.Infof("Deleting Ingress no longer used by: %s", fni.Name)
+	err = h.kubeclientset.NetworkingV1().Ingresses(fni.Namespace).Delete(ctx, fni.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete ingress: %s in %s: %w", fni.Name, fni.Namespace, err)
+	}
+	return nil
+}
+
+// deleteStaleObjects removes the objects rendered for every IngressType
+// other than class, so that changing the IngressType leaves nothing behind.
+func (h SyncHandler) deleteStaleObjects(ctx context.Context, fni *faasv1.FunctionIngress, class string) error {
+	if class == controller.GatewayAPIClass || class == controller.TraefikCRDClass {
+		if err := h.deleteOwnedIngress(ctx, fni); err != nil {
+			return err
+		}
+	}
+
+	if class != controller.GatewayAPIClass {
+		if err := h.deleteOwnedObject(ctx, fni, controller.HTTPRouteResource, h.httpRouteLister, fni.Name); err != nil {
+			return err
+		}
+	}
+
+	if class != controller.TraefikCRDClass {
+		if err := h.deleteOwnedObject(ctx, fni, controller.IngressRouteResource, h.ingressRouteLister, fni.Name); err != nil {
+			return err
+		}
+		if err := h.deleteOwnedObjectsExcept(ctx, fni, controller.MiddlewareResource, h.middlewareLister, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unstructuredEventHandler reconciles the owning FunctionIngress whenever an
+// unstructured object is edited or deleted, in the same way as for Ingresses.
+func unstructuredEventHandler(ctrl controller.BaseController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			oldObj, ok := old.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			newObj, ok := new.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			// Periodic resync will send update events for all known objects.
+			if oldObj.GetResourceVersion() == newObj.GetResourceVersion() {
+				return
+			}
+			ctrl.HandleObject(new)
+		},
+		DeleteFunc: ctrl.HandleObject,
+	}
+}
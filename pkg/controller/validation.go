@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// prefixOnlyDialects match the path of a route as a plain prefix when
+// pathMatch is not set, so a regular expression can only be used as a
+// trailing "(.*)".
+var prefixOnlyDialects = map[string]bool{
+	"traefik":       true,
+	GatewayAPIClass: true,
+	TraefikCRDClass: true,
+}
+
+// regexChars are the characters which make a path a regular expression,
+// "." is left out since it is also common in literal paths such as "/v1.0".
+const regexChars = `*+?()[]{}|^$\`
+
+// Validate returns the errors which stop the FunctionIngress from being
+// rendered for its IngressType and the dialect of its IngressClass, see
+// ResolveIngressClass. It is used by the controller and the validating
+// webhook, so that both reject the same specs.
+func Validate(fni *faasv1.FunctionIngress, dialect string) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if len(fni.Spec.Domain) == 0 && len(fni.Spec.Domains) == 0 {
+		errs = append(errs, field.Required(spec.Child("domain"), "domain or domains is required"))
+	}
+	errs = append(errs, validateDomain(spec.Child("domain"), fni.Spec.Domain)...)
+	for i, domain := range fni.Spec.Domains {
+		errs = append(errs, validateDomain(spec.Child("domains").Index(i), string(domain))...)
+	}
+
+	if fni.Spec.BypassGateway && len(fni.Spec.BypassMode) > 0 {
+		errs = append(errs, field.Invalid(spec.Child("bypassMode"), fni.Spec.BypassMode, "bypassMode can not be set with bypassGateway"))
+	}
+
+	dialect = ClassDialect(fni, dialect)
+	if err := ValidatePathMatch(fni, dialect); err != nil {
+		return append(errs, field.Invalid(spec.Child("pathMatch"), fni.Spec.PathMatch, err.Error()))
+	}
+
+	for i, route := range MakeRoutes(fni) {
+		path := spec.Child("path")
+		if len(fni.Spec.Routes) > 0 {
+			path = spec.Child("routes").Index(i).Child("path")
+		}
+		errs = append(errs, validatePath(path, route.Path, dialect, fni.Spec.PathMatch)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if err := ValidateRoutes(fni, dialect); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("routes"), len(fni.Spec.Routes), err.Error()))
+	}
+	return errs
+}
+
+// validateDomain allows an empty domain, a DNS-1123 subdomain, or a wildcard
+// for a single label such as "*.example.com".
+func validateDomain(path *field.Path, domain string) field.ErrorList {
+	if len(domain) == 0 {
+		return nil
+	}
+
+	var msgs []string
+	if strings.HasPrefix(domain, "*.") {
+		msgs = validation.IsWildcardDNS1123Subdomain(domain)
+	} else {
+		msgs = validation.IsDNS1123Subdomain(domain)
+	}
+
+	errs := field.ErrorList{}
+	for _, msg := range msgs {
+		errs = append(errs, field.Invalid(path, domain, msg))
+	}
+	return errs
+}
+
+// validatePath checks that the path of a route can be matched by the dialect
+// with the pathMatch of the FunctionIngress.
+func validatePath(path *field.Path, value, dialect, pathMatch string) field.ErrorList {
+	if !strings.HasPrefix(value, "/") {
+		return field.ErrorList{field.Invalid(path, value, "must start with /")}
+	}
+
+	switch pathMatch {
+	case faasv1.PathMatchPrefix, faasv1.PathMatchExact:
+		if strings.ContainsAny(value, regexChars) {
+			return field.ErrorList{field.Invalid(path, value,
+				fmt.Sprintf("is matched literally with pathMatch %s, a regular expression needs pathMatch %s", pathMatch, faasv1.PathMatchRegex))}
+		}
+		return nil
+	case faasv1.PathMatchRegex:
+		// The dialects which can not match a regular expression are
+		// rejected by ValidatePathMatch
+	default:
+		if prefixOnlyDialects[dialect] {
+			prefix := strings.TrimSuffix(value, "(.*)")
+			if strings.ContainsAny(prefix, regexChars) {
+				return field.ErrorList{field.Invalid(path, value,
+					fmt.Sprintf("%s only matches a path prefix, a regular expression can only be used as a trailing (.*)", dialect))}
+			}
+			return nil
+		}
+	}
+
+	if _, err := regexp.Compile("^" + value); err != nil {
+		return field.ErrorList{field.Invalid(path, value, fmt.Sprintf("invalid regular expression: %s", err))}
+	}
+	return nil
+}
+
+// Conflicts returns an error for each domain and path of the FunctionIngress
+// which is already served by one of the others. Paths are compared by their
+// prefix, so "/v1/(.*)" and "/v1" claim the same requests. Nested prefixes
+// such as "/" and "/v1" do not conflict, as the longest prefix is matched.
+func Conflicts(fni *faasv1.FunctionIngress, others []*faasv1.FunctionIngress) field.ErrorList {
+	errs := field.ErrorList{}
+
+	claimed := map[string]*faasv1.FunctionIngress{}
+	for _, other := range others {
+		if other.Namespace == fni.Namespace && other.Name == fni.Name {
+			continue
+		}
+		for _, key := range claims(other) {
+			claimed[key] = other
+		}
+	}
+
+	for _, key := range claims(fni) {
+		if other, ok := claimed[key]; ok {
+			host, path, _ := strings.Cut(key, " ")
+			errs = append(errs, field.Duplicate(field.NewPath("spec", "domain"),
+				fmt.Sprintf("%s%s is already used by FunctionIngress %s/%s", host, path, other.Namespace, other.Name)))
+		}
+	}
+	return errs
+}
+
+// claims returns a key for each host and path prefix of the FunctionIngress.
+func claims(fni *faasv1.FunctionIngress) []string {
+	hosts := fni.Spec.Hosts()
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+
+	keys := []string{}
+	for _, host := range hosts {
+		for _, route := range MakeRoutes(fni) {
+			prefix := strings.TrimSuffix(strings.TrimSuffix(route.Path, "(.*)"), "/")
+			keys = append(keys, strings.ToLower(host)+" "+prefix+"/")
+		}
+	}
+	return keys
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    faasv1.FunctionIngressSpec
+		dialect string
+		wantErr string
+	}{
+		{
+			name:    "domain and function",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo"},
+			dialect: "nginx",
+		},
+		{
+			name:    "wildcard domain",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Domains: []faasv1.Domain{"*.eu.example.com"}, Function: "nodeinfo"},
+			dialect: "nginx",
+		},
+		{
+			name:    "domain with a scheme",
+			spec:    faasv1.FunctionIngressSpec{Domain: "https://nodeinfo.example.com", Function: "nodeinfo"},
+			dialect: "nginx",
+			wantErr: "spec.domain",
+		},
+		{
+			name:    "bypassMode with bypassGateway",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", BypassGateway: true, BypassMode: faasv1.BypassModeAuto},
+			dialect: "nginx",
+			wantErr: "spec.bypassMode",
+		},
+		{
+			name:    "wildcard in the middle of a domain",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Domains: []faasv1.Domain{"api.*.example.com"}, Function: "nodeinfo"},
+			dialect: "nginx",
+			wantErr: "spec.domains[0]",
+		},
+		{
+			name:    "path without a leading slash",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "v1/(.*)"},
+			dialect: "nginx",
+			wantErr: "must start with /",
+		},
+		{
+			name:    "invalid regular expression",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "/v1/(.*"},
+			dialect: "nginx",
+			wantErr: "invalid regular expression",
+		},
+		{
+			name:    "regular expression with nginx",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "/v(1|2)/(.*)"},
+			dialect: "nginx",
+		},
+		{
+			name:    "trailing capture group with traefik",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "/v1/(.*)"},
+			dialect: "traefik",
+		},
+		{
+			name:    "regular expression with traefik",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "/v(1|2)/(.*)"},
+			dialect: "traefik",
+			wantErr: "traefik only matches a path prefix",
+		},
+		{
+			name:    "regular expression with gateway-api",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", IngressType: GatewayAPIClass, Path: "/v[0-9]+/(.*)"},
+			dialect: "nginx",
+			wantErr: "gateway-api only matches a path prefix",
+		},
+		{
+			name:    "regular expression with pathMatch Prefix",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "/v1/(.*)", PathMatch: faasv1.PathMatchPrefix},
+			dialect: "nginx",
+			wantErr: "is matched literally with pathMatch Prefix",
+		},
+		{
+			name:    "pathMatch Regex with traefik-crd",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", IngressType: TraefikCRDClass, Path: "/v[0-9]+/(.*)", PathMatch: faasv1.PathMatchRegex},
+			dialect: "nginx",
+		},
+		{
+			name:    "pathMatch Regex with traefik",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", Path: "/v1/(.*)", PathMatch: faasv1.PathMatchRegex},
+			dialect: "traefik",
+			wantErr: "spec.pathMatch",
+		},
+		{
+			name:    "bypass to another namespace",
+			spec:    faasv1.FunctionIngressSpec{Domain: "nodeinfo.example.com", Function: "nodeinfo", FunctionNamespace: "openfaas-fn", BypassGateway: true},
+			dialect: "nginx",
+		},
+		{
+			name: "invalid routes",
+			spec: faasv1.FunctionIngressSpec{
+				Domain: "nodeinfo.example.com",
+				Routes: []faasv1.FunctionRoute{{Path: "/v1/(.*)"}},
+			},
+			dialect: "nginx",
+			wantErr: "route 0 has no function",
+		},
+		{
+			name:    "no domain",
+			spec:    faasv1.FunctionIngressSpec{Function: "nodeinfo"},
+			dialect: "nginx",
+			wantErr: "domain or domains is required",
+		},
+		{
+			name:    "domains without domain",
+			spec:    faasv1.FunctionIngressSpec{Domains: []faasv1.Domain{"nodeinfo.example.com"}, Function: "nodeinfo"},
+			dialect: "nginx",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := &faasv1.FunctionIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+				Spec:       tc.spec,
+			}
+
+			errs := Validate(fni, tc.dialect)
+			if len(tc.wantErr) == 0 {
+				if len(errs) > 0 {
+					t.Fatalf("want no errors, got %s", errs.ToAggregate())
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs.ToAggregate().Error(), tc.wantErr) {
+				t.Fatalf("want an error containing %q, got %v", tc.wantErr, errs.ToAggregate())
+			}
+		})
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	newFunctionIngress := func(namespace, name string, spec faasv1.FunctionIngressSpec) *faasv1.FunctionIngress {
+		return &faasv1.FunctionIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       spec,
+		}
+	}
+
+	existing := []*faasv1.FunctionIngress{
+		newFunctionIngress("openfaas", "nodeinfo", faasv1.FunctionIngressSpec{
+			Domain:   "api.example.com",
+			Function: "nodeinfo",
+			Path:     "/v1/(.*)",
+		}),
+	}
+
+	cases := []struct {
+		name     string
+		fni      *faasv1.FunctionIngress
+		conflict bool
+	}{
+		{
+			name: "same domain and path",
+			fni: newFunctionIngress("team-a", "other", faasv1.FunctionIngressSpec{
+				Domain: "API.example.com", Function: "other", Path: "/v1/(.*)",
+			}),
+			conflict: true,
+		},
+		{
+			name: "same prefix without the capture group",
+			fni: newFunctionIngress("openfaas", "other", faasv1.FunctionIngressSpec{
+				Domain: "api.example.com", Function: "other", Path: "/v1",
+			}),
+			conflict: true,
+		},
+		{
+			name: "same domain in a route",
+			fni: newFunctionIngress("openfaas", "other", faasv1.FunctionIngressSpec{
+				Domain:  "www.example.com",
+				Domains: []faasv1.Domain{"api.example.com"},
+				Routes: []faasv1.FunctionRoute{
+					{Path: "/v2/(.*)", Function: "v2"},
+					{Path: "/v1/(.*)", Function: "v1"},
+				},
+			}),
+			conflict: true,
+		},
+		{
+			name: "same domain with another path",
+			fni: newFunctionIngress("openfaas", "other", faasv1.FunctionIngressSpec{
+				Domain: "api.example.com", Function: "other", Path: "/v2/(.*)",
+			}),
+		},
+		{
+			name: "another domain with the same path",
+			fni: newFunctionIngress("openfaas", "other", faasv1.FunctionIngressSpec{
+				Domain: "www.example.com", Function: "other", Path: "/v1/(.*)",
+			}),
+		},
+		{
+			name: "a longer prefix of the same path",
+			fni: newFunctionIngress("openfaas", "other", faasv1.FunctionIngressSpec{
+				Domain: "api.example.com", Function: "other", Path: "/v1/users/(.*)",
+			}),
+		},
+		{
+			name: "a shorter prefix of the same path",
+			fni: newFunctionIngress("openfaas", "other", faasv1.FunctionIngressSpec{
+				Domain: "api.example.com", Function: "other", Path: "/(.*)",
+			}),
+		},
+		{
+			name: "an update of the same FunctionIngress",
+			fni: newFunctionIngress("openfaas", "nodeinfo", faasv1.FunctionIngressSpec{
+				Domain: "api.example.com", Function: "nodeinfo-v2", Path: "/v1/(.*)",
+			}),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := Conflicts(tc.fni, existing)
+			if tc.conflict && len(errs) == 0 {
+				t.Fatalf("want a conflict")
+			}
+			if !tc.conflict && len(errs) > 0 {
+				t.Fatalf("want no conflict, got %s", errs.ToAggregate())
+			}
+			if tc.conflict && !strings.Contains(errs.ToAggregate().Error(), "openfaas/nodeinfo") {
+				t.Errorf("want the conflicting FunctionIngress in the error, got %s", errs.ToAggregate())
+			}
+		})
+	}
+}
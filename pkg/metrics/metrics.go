@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const namespace = "ingress_operator"
+
+const (
+	// ResultSuccess is the result label for a reconcile which succeeded
+	ResultSuccess = "success"
+	// ResultError is the result label for a reconcile which returned an
+	// error and was requeued
+	ResultError = "error"
+)
+
+// Registry holds all of the operator's metrics, it is separate from the
+// default registry so that only the metrics below are exported.
+var Registry = prometheus.NewRegistry()
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_total",
+		Help:      "Total number of FunctionIngress reconciliations by result.",
+	}, []string{"result"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to reconcile a FunctionIngress by result.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		reconcileTotal,
+		reconcileDuration,
+	)
+
+	// The provider must be set before any queue is created, the first call
+	// to SetProvider wins.
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+// ObserveReconcile records the result and duration of a single reconcile
+func ObserveReconcile(result string, duration time.Duration) {
+	reconcileTotal.WithLabelValues(result).Inc()
+	reconcileDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// Handler returns the HTTP handler which exposes the metrics in Registry
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
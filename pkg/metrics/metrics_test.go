@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unable to scrape metrics: %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	return string(body)
+}
+
+func Test_Handler_ExportsWorkqueueMetrics(t *testing.T) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "MetricsTest")
+	defer queue.ShutDown()
+
+	queue.Add("openfaas/nodeinfo")
+	queue.AddRateLimited("openfaas/figlet")
+
+	body := scrape(t)
+	for _, want := range []string{
+		`ingress_operator_workqueue_depth{name="MetricsTest"} 1`,
+		`ingress_operator_workqueue_adds_total{name="MetricsTest"} 1`,
+		`ingress_operator_workqueue_retries_total{name="MetricsTest"} 1`,
+		`ingress_operator_workqueue_queue_duration_seconds_bucket{name="MetricsTest"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want metrics to contain %q", want)
+		}
+	}
+}
+
+func Test_Handler_ExportsReconcileMetrics(t *testing.T) {
+	ObserveReconcile(ResultSuccess, 10*time.Millisecond)
+	ObserveReconcile(ResultError, 20*time.Millisecond)
+
+	body := scrape(t)
+	for _, want := range []string{
+		`ingress_operator_reconcile_total{result="success"}`,
+		`ingress_operator_reconcile_total{result="error"}`,
+		`ingress_operator_reconcile_duration_seconds_count{result="success"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want metrics to contain %q", want)
+		}
+	}
+}
@@ -3,10 +3,13 @@ package signals
 import (
 	"os"
 	"os/signal"
+	"sync"
 )
 
 var onlyOneSignalHandler = make(chan struct{})
 
+var requestShutdown func()
+
 // SetupSignalHandler registered for SIGTERM and SIGINT. A stop channel is returned
 // which is closed on one of these signals. If a second signal is caught, the program
 // is terminated with exit code 1.
@@ -14,14 +17,31 @@ func SetupSignalHandler() (stopCh <-chan struct{}) {
 	close(onlyOneSignalHandler) // panics when called twice
 
 	stop := make(chan struct{})
+	var once sync.Once
+	requestShutdown = func() {
+		once.Do(func() { close(stop) })
+	}
+
 	c := make(chan os.Signal, 2)
 	signal.Notify(c, shutdownSignals...)
 	go func() {
 		<-c
-		close(stop)
+		requestShutdown()
 		<-c
 		os.Exit(1) // second signal. Exit directly.
 	}()
 
 	return stop
 }
+
+// RequestShutdown closes the stop channel returned by SetupSignalHandler as
+// if a shutdown signal had been received, i.e. when leadership is lost. It
+// returns false if SetupSignalHandler has not been called.
+func RequestShutdown() bool {
+	if requestShutdown == nil {
+		return false
+	}
+
+	requestShutdown()
+	return true
+}
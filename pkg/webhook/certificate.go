@@ -0,0 +1,428 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	klog "k8s.io/klog"
+)
+
+const (
+	// certificateValidity is how long the serving certificate is valid for
+	certificateValidity = 365 * 24 * time.Hour
+
+	// caValidity is how long the generated CA is valid for, it is reused
+	// when the serving certificate is renewed
+	caValidity = 10 * 365 * 24 * time.Hour
+
+	// certificateRenewBefore is how long before it expires that the serving
+	// certificate is replaced
+	certificateRenewBefore = 30 * 24 * time.Hour
+
+	// caCertKey is the key of the CA bundle in the secret, which is also
+	// written to the caBundle of the webhook configuration
+	caCertKey = "ca.crt"
+
+	// caKeyKey is the key of the private key of the CA in the secret
+	caKeyKey = "ca.key"
+)
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// CertificateConfig describes where the serving certificate of the webhook
+// is kept, and the Service that the API server calls it through.
+type CertificateConfig struct {
+	// Namespace of the Service and the secret, such as "openfaas"
+	Namespace string
+
+	// Service that the API server sends admission requests to
+	Service string
+
+	// SecretName of the TLS secret which holds the certificate, so that
+	// every replica serves the same one
+	SecretName string
+
+	// ClusterDomain such as "cluster.local"
+	ClusterDomain string
+
+	// CustomResourceDefinition such as "functioningresses.openfaas.com",
+	// whose conversion webhook is called through the Service
+	CustomResourceDefinition string
+}
+
+// DNSNames are the names of the Service which the certificate is valid for
+func (c CertificateConfig) DNSNames() []string {
+	names := []string{
+		c.Service,
+		fmt.Sprintf("%s.%s", c.Service, c.Namespace),
+		fmt.Sprintf("%s.%s.svc", c.Service, c.Namespace),
+	}
+	if len(c.ClusterDomain) > 0 {
+		names = append(names, fmt.Sprintf("%s.%s.svc.%s", c.Service, c.Namespace, c.ClusterDomain))
+	}
+	return names
+}
+
+// Certificates generates and renews the self-signed serving certificate of
+// the webhook. It is kept in a secret, so that replicas share it, and its CA
+// is written to the caBundle of the webhook configurations and of the
+// conversion webhook of the CustomResourceDefinition.
+type Certificates struct {
+	kubeClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	config        CertificateConfig
+
+	// configurations are the names of the ValidatingWebhookConfigurations
+	// and MutatingWebhookConfigurations which call the webhook
+	configurations []string
+
+	lock sync.RWMutex
+	cert *tls.Certificate
+}
+
+// NewCertificates returns Certificates which patch the caBundle of the
+// named ValidatingWebhookConfigurations and MutatingWebhookConfigurations,
+// and of the CustomResourceDefinition of the config.
+func NewCertificates(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, config CertificateConfig, configurations ...string) *Certificates {
+	return &Certificates{
+		kubeClient:     kubeClient,
+		dynamicClient:  dynamicClient,
+		config:         config,
+		configurations: configurations,
+	}
+}
+
+// GetCertificate is used as tls.Config.GetCertificate, so that a renewed
+// certificate is served without a restart.
+func (c *Certificates) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.cert == nil {
+		return nil, fmt.Errorf("the webhook certificate has not been loaded")
+	}
+	return c.cert, nil
+}
+
+// Ensure loads the certificate from the secret, or generates a new one when
+// it is missing, expires within certificateRenewBefore or is not valid for
+// the Service. The CA bundle is then written to the webhook configurations
+// and the CustomResourceDefinition. The CA is kept when the certificate is
+// renewed, see generateCertificate, as every replica serves the webhooks and
+// loads the renewed certificate only on its next check.
+func (c *Certificates) Ensure(ctx context.Context) error {
+	secrets := c.kubeClient.CoreV1().Secrets(c.config.Namespace)
+
+	secret, err := secrets.Get(ctx, c.config.SecretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.config.SecretName,
+				Namespace: c.config.Namespace,
+			},
+			Type: corev1.SecretTypeTLS,
+		}
+	} else if err != nil {
+		return fmt.Errorf("cannot get secret %s: %w", c.config.SecretName, err)
+	}
+
+	if !c.valid(secret) {
+		klog.Infof("Generating webhook certificate for %s", c.config.DNSNames()[2])
+		data, err := generateCertificate(secret.Data, c.config.DNSNames(), time.Now())
+		if err != nil {
+			return err
+		}
+		secret.Data = data
+
+		if len(secret.ResourceVersion) == 0 {
+			secret, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		} else {
+			secret, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		}
+		// Another replica wrote a certificate first, use that one instead
+		if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+			secret, err = secrets.Get(ctx, c.config.SecretName, metav1.GetOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("cannot write secret %s: %w", c.config.SecretName, err)
+		}
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return fmt.Errorf("secret %s has an invalid key pair: %w", c.config.SecretName, err)
+	}
+
+	c.lock.Lock()
+	c.cert = &cert
+	c.lock.Unlock()
+
+	if err := c.patchCABundle(ctx, secret.Data[caCertKey]); err != nil {
+		return err
+	}
+	return c.patchConversionCABundle(ctx, secret.Data[caCertKey])
+}
+
+// valid is true when the secret has a certificate for the Service which
+// does not expire within certificateRenewBefore.
+func (c *Certificates) valid(secret *corev1.Secret) bool {
+	if len(secret.Data[caCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return false
+	}
+
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+
+	if time.Now().Add(certificateRenewBefore).After(cert.NotAfter) {
+		return false
+	}
+	for _, name := range c.config.DNSNames() {
+		if cert.VerifyHostname(name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// patchCABundle writes the CA to every webhook of the configurations, so
+// that the API server trusts the serving certificate. A configuration which
+// does not exist is skipped, as only one of the webhooks may be installed.
+func (c *Certificates) patchCABundle(ctx context.Context, caBundle []byte) error {
+	validating := c.kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	mutating := c.kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations()
+
+	for _, name := range c.configurations {
+		configuration, err := validating.Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			changed := false
+			for i := range configuration.Webhooks {
+				changed = setCABundle(&configuration.Webhooks[i].ClientConfig, caBundle) || changed
+			}
+			if changed {
+				if _, err := validating.Update(ctx, configuration, metav1.UpdateOptions{}); err != nil {
+					return fmt.Errorf("cannot update the caBundle of ValidatingWebhookConfiguration %s: %w", name, err)
+				}
+				klog.Infof("Updated the caBundle of ValidatingWebhookConfiguration %s", name)
+			}
+		} else if !errors.IsNotFound(err) {
+			return fmt.Errorf("cannot get ValidatingWebhookConfiguration %s: %w", name, err)
+		}
+
+		mutatingConfiguration, err := mutating.Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			changed := false
+			for i := range mutatingConfiguration.Webhooks {
+				changed = setCABundle(&mutatingConfiguration.Webhooks[i].ClientConfig, caBundle) || changed
+			}
+			if changed {
+				if _, err := mutating.Update(ctx, mutatingConfiguration, metav1.UpdateOptions{}); err != nil {
+					return fmt.Errorf("cannot update the caBundle of MutatingWebhookConfiguration %s: %w", name, err)
+				}
+				klog.Infof("Updated the caBundle of MutatingWebhookConfiguration %s", name)
+			}
+		} else if !errors.IsNotFound(err) {
+			return fmt.Errorf("cannot get MutatingWebhookConfiguration %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// patchConversionCABundle writes the CA to the conversion webhook of the
+// CustomResourceDefinition. It is skipped when the CustomResourceDefinition
+// does not use a conversion webhook, as then only v1 is served.
+func (c *Certificates) patchConversionCABundle(ctx context.Context, caBundle []byte) error {
+	name := c.config.CustomResourceDefinition
+	if len(name) == 0 {
+		return nil
+	}
+
+	crds := c.dynamicClient.Resource(crdGVR)
+	crd, err := crds.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get CustomResourceDefinition %s: %w", name, err)
+	}
+
+	strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+	if strategy != "Webhook" {
+		return nil
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(caBundle)
+	current, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	if current == encoded {
+		return nil
+	}
+
+	if err := unstructured.SetNestedField(crd.Object, encoded, "spec", "conversion", "webhook", "clientConfig", "caBundle"); err != nil {
+		return err
+	}
+	if _, err := crds.Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("cannot update the caBundle of CustomResourceDefinition %s: %w", name, err)
+	}
+	klog.Infof("Updated the caBundle of CustomResourceDefinition %s", name)
+	return nil
+}
+
+// setCABundle returns true when the caBundle of the client config changed
+func setCABundle(clientConfig *admissionregistrationv1.WebhookClientConfig, caBundle []byte) bool {
+	if bytes.Equal(clientConfig.CABundle, caBundle) {
+		return false
+	}
+	clientConfig.CABundle = caBundle
+	return true
+}
+
+// generateCertificate returns the data of a TLS secret with a serving
+// certificate for dnsNames. The CA of the previous data is reused, so that a
+// replica which still serves the previous certificate stays trusted until it
+// loads the new one. A CA is only generated when there is none, or it
+// expires within certificateRenewBefore, and then the previous CA is kept in
+// the bundle until it expires.
+func generateCertificate(previous map[string][]byte, dnsNames []string, now time.Time) (map[string][]byte, error) {
+	ca, caKey := loadCA(previous, now)
+	if ca == nil {
+		var err error
+		if ca, caKey, err = generateCA(now); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate serving key: %w", err)
+	}
+
+	notAfter := now.Add(certificateValidity)
+	if notAfter.After(ca.NotAfter) {
+		notAfter = ca.NotAfter
+	}
+	serving := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano() + 1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	servingDER, err := x509.CreateCertificate(rand.Reader, serving, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create serving certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode serving key: %w", err)
+	}
+	caKeyDER, err := x509.MarshalECPrivateKey(caKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode CA key: %w", err)
+	}
+
+	return map[string][]byte{
+		caCertKey:               caBundle(ca, previous[caCertKey], now),
+		caKeyKey:                pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: caKeyDER}),
+		corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: servingDER}),
+		corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// generateCA returns a new self-signed CA, valid for caValidity
+func generateCA(now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate CA key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: "ingress-operator-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create CA certificate: %w", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse CA certificate: %w", err)
+	}
+	return ca, caKey, nil
+}
+
+// loadCA returns the CA which signs the serving certificate, the first of
+// the bundle, with its key. It is nil when the data has no CA key, such as
+// a secret written by an earlier version, or the CA expires within
+// certificateRenewBefore.
+func loadCA(data map[string][]byte, now time.Time) (*x509.Certificate, *ecdsa.PrivateKey) {
+	certBlock, _ := pem.Decode(data[caCertKey])
+	keyBlock, _ := pem.Decode(data[caKeyKey])
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil
+	}
+
+	ca, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil || !ca.IsCA {
+		return nil, nil
+	}
+	caKey, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil || !caKey.PublicKey.Equal(ca.PublicKey) {
+		return nil, nil
+	}
+
+	if now.Add(certificateRenewBefore).After(ca.NotAfter) {
+		return nil, nil
+	}
+	return ca, caKey
+}
+
+// caBundle returns the CA followed by the CAs of the previous bundle which
+// have not expired, so that the certificates they signed are still trusted
+func caBundle(ca *x509.Certificate, previous []byte, now time.Time) []byte {
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})
+
+	for {
+		var block *pem.Block
+		block, previous = pem.Decode(previous)
+		if block == nil {
+			return bundle
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || bytes.Equal(cert.Raw, ca.Raw) || now.After(cert.NotAfter) {
+			continue
+		}
+		bundle = append(bundle, pem.EncodeToMemory(block)...)
+	}
+}
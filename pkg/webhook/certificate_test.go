@@ -0,0 +1,296 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testCertificateConfig = CertificateConfig{
+	Namespace:     "openfaas",
+	Service:       "ingress-operator-webhook",
+	SecretName:    "ingress-operator-webhook-cert",
+	ClusterDomain: "cluster.local",
+}
+
+func newTestWebhookConfiguration() *admissionregistrationv1.ValidatingWebhookConfiguration {
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-operator"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "functioningresses.openfaas.com"},
+		},
+	}
+}
+
+func TestCertificates_EnsureGeneratesCertificate(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(newTestWebhookConfiguration())
+	certificates := NewCertificates(kubeClient, nil, testCertificateConfig, "ingress-operator")
+
+	if err := certificates.Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets("openfaas").Get(context.Background(), "ingress-operator-webhook-cert", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("want a secret, got: %s", err)
+	}
+
+	cert, err := certificates.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(secret.Data[caCertKey])
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "ingress-operator-webhook.openfaas.svc", Roots: roots}); err != nil {
+		t.Errorf("want the serving certificate to be trusted by the CA, got: %s", err)
+	}
+
+	configuration, err := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(context.Background(), "ingress-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(configuration.Webhooks[0].ClientConfig.CABundle, secret.Data[caCertKey]) {
+		t.Errorf("want the caBundle to be the CA of the secret")
+	}
+}
+
+func TestCertificates_EnsureKeepsValidCertificate(t *testing.T) {
+	data, err := generateCertificate(nil, testCertificateConfig.DNSNames(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-operator-webhook-cert", Namespace: "openfaas", ResourceVersion: "1"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       data,
+	}
+
+	kubeClient := fake.NewSimpleClientset(newTestWebhookConfiguration(), secret)
+	certificates := NewCertificates(kubeClient, nil, testCertificateConfig, "ingress-operator")
+	if err := certificates.Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := kubeClient.CoreV1().Secrets("openfaas").Get(context.Background(), "ingress-operator-webhook-cert", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(got.Data[corev1.TLSCertKey], data[corev1.TLSCertKey]) {
+		t.Errorf("want the certificate to be kept")
+	}
+}
+
+func TestCertificates_EnsureRenewsExpiringCertificate(t *testing.T) {
+	// Issued long enough ago to be within certificateRenewBefore of expiry
+	data, err := generateCertificate(nil, testCertificateConfig.DNSNames(), time.Now().Add(-certificateValidity+24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-operator-webhook-cert", Namespace: "openfaas", ResourceVersion: "1"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       data,
+	}
+
+	kubeClient := fake.NewSimpleClientset(newTestWebhookConfiguration(), secret)
+	certificates := NewCertificates(kubeClient, nil, testCertificateConfig, "ingress-operator")
+	if err := certificates.Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := kubeClient.CoreV1().Secrets("openfaas").Get(context.Background(), "ingress-operator-webhook-cert", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	block, _ := pem.Decode(got.Data[corev1.TLSCertKey])
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if time.Until(cert.NotAfter) < certificateRenewBefore {
+		t.Errorf("want a renewed certificate, it expires at %s", cert.NotAfter)
+	}
+}
+
+func TestCertificates_EnsureRenewalKeepsCA(t *testing.T) {
+	data, err := generateCertificate(nil, testCertificateConfig.DNSNames(), time.Now().Add(-certificateValidity+24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got := ensureTestSecret(t, data)
+	if !bytes.Equal(got.Data[caCertKey], data[caCertKey]) {
+		t.Errorf("want the CA to be kept when the serving certificate is renewed")
+	}
+
+	// Another replica still serves the previous certificate
+	verifyTestCertificate(t, data[corev1.TLSCertKey], got.Data[caCertKey])
+	verifyTestCertificate(t, got.Data[corev1.TLSCertKey], got.Data[caCertKey])
+}
+
+func TestCertificates_EnsureRenewalOfCAKeepsPreviousCA(t *testing.T) {
+	// The CA expires in 20 days, within certificateRenewBefore, and so does
+	// the serving certificate it signed 15 days ago
+	now := time.Now()
+	data, err := generateCertificate(nil, testCertificateConfig.DNSNames(), now.Add(20*24*time.Hour-caValidity))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	data, err = generateCertificate(data, testCertificateConfig.DNSNames(), now.Add(-15*24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got := ensureTestSecret(t, data)
+	if bytes.Equal(got.Data[caKeyKey], data[caKeyKey]) {
+		t.Errorf("want a new CA")
+	}
+	if !bytes.HasSuffix(got.Data[caCertKey], data[caCertKey]) {
+		t.Errorf("want the previous CA to stay in the bundle until it expires")
+	}
+
+	verifyTestCertificate(t, data[corev1.TLSCertKey], got.Data[caCertKey])
+	verifyTestCertificate(t, got.Data[corev1.TLSCertKey], got.Data[caCertKey])
+}
+
+func TestCertificates_EnsureReplacesCAWithoutKey(t *testing.T) {
+	// Written before the key of the CA was kept in the secret
+	data, err := generateCertificate(nil, testCertificateConfig.DNSNames(), time.Now().Add(-certificateValidity+24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	delete(data, caKeyKey)
+
+	got := ensureTestSecret(t, data)
+	if len(got.Data[caKeyKey]) == 0 {
+		t.Errorf("want the key of the new CA to be kept in the secret")
+	}
+	verifyTestCertificate(t, data[corev1.TLSCertKey], got.Data[caCertKey])
+	verifyTestCertificate(t, got.Data[corev1.TLSCertKey], got.Data[caCertKey])
+}
+
+// ensureTestSecret runs Ensure against a secret with the data, and returns
+// the secret it leaves behind
+func ensureTestSecret(t *testing.T, data map[string][]byte) *corev1.Secret {
+	t.Helper()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-operator-webhook-cert", Namespace: "openfaas", ResourceVersion: "1"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       data,
+	}
+	kubeClient := fake.NewSimpleClientset(newTestWebhookConfiguration(), secret.DeepCopy())
+	certificates := NewCertificates(kubeClient, nil, testCertificateConfig, "ingress-operator")
+	if err := certificates.Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := kubeClient.CoreV1().Secrets("openfaas").Get(context.Background(), "ingress-operator-webhook-cert", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return got
+}
+
+func verifyTestCertificate(t *testing.T, certPEM, caBundle []byte) {
+	t.Helper()
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caBundle)
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "ingress-operator-webhook.openfaas.svc", Roots: roots}); err != nil {
+		t.Errorf("want the certificate to be trusted by the caBundle, got: %s", err)
+	}
+}
+
+func TestCertificates_EnsurePatchesMutatingConfiguration(t *testing.T) {
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-operator"},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "functioningresses.openfaas.com"},
+		},
+	}
+
+	// Only the mutating webhook is installed
+	kubeClient := fake.NewSimpleClientset(mutating)
+	certificates := NewCertificates(kubeClient, nil, testCertificateConfig, "ingress-operator")
+	if err := certificates.Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets("openfaas").Get(context.Background(), "ingress-operator-webhook-cert", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(), "ingress-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(got.Webhooks[0].ClientConfig.CABundle, secret.Data[caCertKey]) {
+		t.Errorf("want the caBundle to be the CA of the secret")
+	}
+}
+
+func TestCertificates_EnsurePatchesConversionWebhook(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "functioningresses.openfaas.com"},
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"strategy": "Webhook",
+				"webhook": map[string]interface{}{
+					"clientConfig": map[string]interface{}{
+						"service": map[string]interface{}{"name": "ingress-operator-webhook", "namespace": "openfaas", "path": ConvertPath},
+					},
+				},
+			},
+		},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"}, crd)
+
+	config := testCertificateConfig
+	config.CustomResourceDefinition = "functioningresses.openfaas.com"
+
+	kubeClient := fake.NewSimpleClientset(newTestWebhookConfiguration())
+	certificates := NewCertificates(kubeClient, dynamicClient, config, "ingress-operator")
+	if err := certificates.Ensure(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets("openfaas").Get(context.Background(), "ingress-operator-webhook-cert", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := dynamicClient.Resource(crdGVR).Get(context.Background(), "functioningresses.openfaas.com", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	caBundle, _, _ := unstructured.NestedString(got.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	if want := base64.StdEncoding.EncodeToString(secret.Data[caCertKey]); caBundle != want {
+		t.Errorf("want the caBundle of the conversion webhook to be the CA of the secret, got %q", caBundle)
+	}
+}
@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	faasv2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	klog "k8s.io/klog"
+)
+
+// ConvertPath is the path that the CustomResourceDefinition sends
+// ConversionReviews for FunctionIngresses to.
+const ConvertPath = "/convert"
+
+// conversionReview is the apiextensions.k8s.io/v1 ConversionReview, it is
+// declared here rather than importing k8s.io/apiextensions-apiserver for a
+// single type
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Request  *conversionRequest  `json:"request,omitempty"`
+	Response *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// Converter converts FunctionIngresses between openfaas.com/v1 and
+// openfaas.com/v2 for the API server, so that either version can be read and
+// written while v1 is stored.
+type Converter struct{}
+
+// NewConverter returns a Converter
+func NewConverter() *Converter {
+	return &Converter{}
+}
+
+// Convert returns the FunctionIngress in raw as desiredAPIVersion
+func (c *Converter) Convert(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("cannot decode object: %w", err)
+	}
+	if typeMeta.Kind != "FunctionIngress" {
+		return nil, fmt.Errorf("cannot convert kind %q", typeMeta.Kind)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	v1 := faasv1.SchemeGroupVersion.String()
+	v2 := faasv2.SchemeGroupVersion.String()
+
+	switch {
+	case typeMeta.APIVersion == v1 && desiredAPIVersion == v2:
+		fni := &faasv1.FunctionIngress{}
+		if err := json.Unmarshal(raw, fni); err != nil {
+			return nil, fmt.Errorf("cannot decode FunctionIngress: %w", err)
+		}
+		return json.Marshal(faasv2.ConvertFromV1(fni))
+
+	case typeMeta.APIVersion == v2 && desiredAPIVersion == v1:
+		fni := &faasv2.FunctionIngress{}
+		if err := json.Unmarshal(raw, fni); err != nil {
+			return nil, fmt.Errorf("cannot decode FunctionIngress: %w", err)
+		}
+		return json.Marshal(faasv2.ConvertToV1(fni))
+	}
+
+	return nil, fmt.Errorf("cannot convert FunctionIngress from %s to %s", typeMeta.APIVersion, desiredAPIVersion)
+}
+
+// ServeHTTP answers a ConversionReview for FunctionIngresses
+func (c *Converter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := conversionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "expected a ConversionReview with a request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = c.review(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		klog.Errorf("Error writing ConversionReview: %s", err)
+	}
+}
+
+func (c *Converter) review(req *conversionRequest) *conversionResponse {
+	res := &conversionResponse{
+		UID:              req.UID,
+		ConvertedObjects: []runtime.RawExtension{},
+		Result:           metav1.Status{Status: metav1.StatusSuccess},
+	}
+
+	for _, object := range req.Objects {
+		converted, err := c.Convert(object.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			klog.Errorf("Error converting FunctionIngress: %s", err)
+			return &conversionResponse{
+				UID:    req.UID,
+				Result: metav1.Status{Status: metav1.StatusFailure, Message: err.Error()},
+			}
+		}
+		res.ConvertedObjects = append(res.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	return res
+}
@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	faasv2 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func convert(t *testing.T, desiredAPIVersion string, objects ...interface{}) *conversionResponse {
+	t.Helper()
+
+	req := &conversionRequest{UID: "0f3d6d1a-3c1e-4a8e-9a0e-2f4b5f6c7d8e", DesiredAPIVersion: desiredAPIVersion}
+	for _, object := range objects {
+		raw, err := json.Marshal(object)
+		if err != nil {
+			t.Fatalf("unable to encode object: %s", err)
+		}
+		req.Objects = append(req.Objects, runtime.RawExtension{Raw: raw})
+	}
+
+	body, err := json.Marshal(conversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request:  req,
+	})
+	if err != nil {
+		t.Fatalf("unable to encode ConversionReview: %s", err)
+	}
+
+	rr := httptest.NewRecorder()
+	NewConverter().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, ConvertPath, bytes.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	res := conversionReview{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unable to decode ConversionReview: %s", err)
+	}
+	if res.Kind != "ConversionReview" || res.Response == nil {
+		t.Fatalf("want a ConversionReview with a response, got %s", rr.Body.String())
+	}
+	if res.Response.UID != req.UID {
+		t.Errorf("want uid %s, got %s", req.UID, res.Response.UID)
+	}
+	return res.Response
+}
+
+func TestConverter_ConvertsV1ToV2(t *testing.T) {
+	fni := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/v1/(.*)")
+	fni.TypeMeta = metav1.TypeMeta{APIVersion: "openfaas.com/v1", Kind: "FunctionIngress"}
+	fni.Spec.BypassGateway = true
+
+	res := convert(t, "openfaas.com/v2", fni)
+	if res.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("want success, got %v", res.Result)
+	}
+	if len(res.ConvertedObjects) != 1 {
+		t.Fatalf("want 1 converted object, got %d", len(res.ConvertedObjects))
+	}
+
+	got := &faasv2.FunctionIngress{}
+	if err := json.Unmarshal(res.ConvertedObjects[0].Raw, got); err != nil {
+		t.Fatalf("unable to decode FunctionIngress: %s", err)
+	}
+	if got.APIVersion != "openfaas.com/v2" {
+		t.Errorf("want apiVersion openfaas.com/v2, got %s", got.APIVersion)
+	}
+	if got.Spec.Backend.Type != faasv2.BackendTypeDirect {
+		t.Errorf("want backend type %s, got %s", faasv2.BackendTypeDirect, got.Spec.Backend.Type)
+	}
+	if len(got.Spec.Domains) != 1 || got.Spec.Domains[0] != "nodeinfo.example.com" {
+		t.Errorf("want domains [nodeinfo.example.com], got %v", got.Spec.Domains)
+	}
+}
+
+func TestConverter_ConvertsV2ToV1(t *testing.T) {
+	fni := &faasv2.FunctionIngress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "openfaas.com/v2", Kind: "FunctionIngress"},
+		ObjectMeta: metav1.ObjectMeta{Name: "nodeinfo", Namespace: "openfaas"},
+		Spec: faasv2.FunctionIngressSpec{
+			Domains: []faasv2.Domain{"nodeinfo.example.com"},
+			Class:   faasv2.ClassReference{Kind: faasv2.ClassKindHTTPRoute},
+			Backend: faasv2.Backend{Type: faasv2.BackendTypeGateway, Function: "nodeinfo"},
+		},
+	}
+
+	res := convert(t, "openfaas.com/v1", fni)
+	if res.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("want success, got %v", res.Result)
+	}
+
+	got := &faasv1.FunctionIngress{}
+	if err := json.Unmarshal(res.ConvertedObjects[0].Raw, got); err != nil {
+		t.Fatalf("unable to decode FunctionIngress: %s", err)
+	}
+	if got.APIVersion != "openfaas.com/v1" {
+		t.Errorf("want apiVersion openfaas.com/v1, got %s", got.APIVersion)
+	}
+	if got.Spec.IngressType != "gateway-api" || got.Spec.Domain != "nodeinfo.example.com" || got.Spec.Function != "nodeinfo" {
+		t.Errorf("unexpected spec: %+v", got.Spec)
+	}
+}
+
+func TestConverter_FailsForUnknownVersion(t *testing.T) {
+	fni := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "")
+	fni.TypeMeta = metav1.TypeMeta{APIVersion: "openfaas.com/v1", Kind: "FunctionIngress"}
+
+	res := convert(t, "openfaas.com/v3", fni)
+	if res.Result.Status != metav1.StatusFailure {
+		t.Errorf("want failure, got %v", res.Result)
+	}
+	if len(res.ConvertedObjects) != 0 {
+		t.Errorf("want no converted objects, got %d", len(res.ConvertedObjects))
+	}
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+)
+
+// DefaultPath is the path that the MutatingWebhookConfiguration sends
+// admission requests for FunctionIngresses to.
+const DefaultPath = "/mutate-openfaas-com-v1-functioningress"
+
+// Defaulter writes the defaults of a FunctionIngress into the stored object,
+// see controller.SetDefaults.
+type Defaulter struct {
+	defaults       controller.Defaults
+	ingressClasses networkinglisters.IngressClassLister
+}
+
+// NewDefaulter returns a Defaulter for the operator level defaults
+func NewDefaulter(defaults controller.Defaults, ingressClasses networkinglisters.IngressClassLister) *Defaulter {
+	return &Defaulter{
+		defaults:       defaults,
+		ingressClasses: ingressClasses,
+	}
+}
+
+// ServeHTTP answers an AdmissionReview for a FunctionIngress with a JSON
+// patch of the defaults
+func (d *Defaulter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveAdmission(w, r, d.review)
+}
+
+func (d *Defaulter) review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	fni := &faasv1.FunctionIngress{}
+	if err := json.Unmarshal(req.Object.Raw, fni); err != nil {
+		return deny(errors.NewBadRequest(fmt.Sprintf("cannot decode FunctionIngress: %s", err)))
+	}
+	if len(fni.Namespace) == 0 {
+		fni.Namespace = req.Namespace
+	}
+
+	defaulted := fni.DeepCopy()
+	if req.Operation == admissionv1.Update {
+		old := &faasv1.FunctionIngress{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return deny(errors.NewBadRequest(fmt.Sprintf("cannot decode the old FunctionIngress: %s", err)))
+		}
+		controller.ClearDerivedDefaults(defaulted, old)
+	}
+	controller.SetDefaults(defaulted, d.defaults, d.ingressClasses)
+
+	patch := defaultsPatch(fni, defaulted)
+	if len(patch) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return deny(errors.NewInternalError(err))
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     data,
+		PatchType: &patchType,
+	}
+}
+
+// patchOperation is an operation of a JSON patch, RFC 6902
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// defaultsPatch returns an "add" operation for each field that SetDefaults
+// changed, "add" replaces a field which is already present. A field which
+// ClearDerivedDefaults emptied, and which is not derived again, is removed.
+func defaultsPatch(fni, defaulted *faasv1.FunctionIngress) []patchOperation {
+	patch := []patchOperation{}
+
+	add := func(path string, before, after interface{}) {
+		if equality.Semantic.DeepEqual(before, after) {
+			return
+		}
+		if after == "" {
+			patch = append(patch, patchOperation{Op: "remove", Path: path})
+			return
+		}
+		patch = append(patch, patchOperation{Op: "add", Path: path, Value: after})
+	}
+
+	add("/spec/ingressClassName", fni.Spec.IngressClassName, defaulted.Spec.IngressClassName)
+	add("/spec/functionNamespace", fni.Spec.FunctionNamespace, defaulted.Spec.FunctionNamespace)
+	add("/spec/path", fni.Spec.Path, defaulted.Spec.Path)
+
+	if fni.Spec.TLS != nil {
+		add("/spec/tls/mode", fni.Spec.TLS.Mode, defaulted.Spec.TLS.Mode)
+		add("/spec/tls/issuerRef", fni.Spec.TLS.IssuerRef, defaulted.Spec.TLS.IssuerRef)
+	}
+
+	return patch
+}
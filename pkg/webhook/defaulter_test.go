@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestDefaulter_PatchesDefaults(t *testing.T) {
+	d := NewDefaulter(controller.Defaults{
+		FunctionNamespace: "openfaas-fn",
+		IssuerRef:         faasv1.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+	}, nil)
+
+	fni := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "")
+	fni.Spec.TLS = &faasv1.FunctionIngressTLS{Enabled: true}
+
+	res := review(t, d, admissionv1.Create, fni)
+	if !res.Allowed {
+		t.Fatalf("want the FunctionIngress to be allowed, got: %v", res.Result)
+	}
+	if res.PatchType == nil || *res.PatchType != admissionv1.PatchTypeJSONPatch {
+		t.Fatalf("want a JSON patch, got %v", res.PatchType)
+	}
+
+	patch := []map[string]interface{}{}
+	if err := json.Unmarshal(res.Patch, &patch); err != nil {
+		t.Fatalf("unable to decode patch: %s", err)
+	}
+
+	want := []map[string]interface{}{
+		{"op": "add", "path": "/spec/ingressClassName", "value": "nginx"},
+		{"op": "add", "path": "/spec/functionNamespace", "value": "openfaas-fn"},
+		{"op": "add", "path": "/spec/path", "value": "/(.*)"},
+		{"op": "add", "path": "/spec/tls/mode", "value": "certManager"},
+		{"op": "add", "path": "/spec/tls/issuerRef", "value": map[string]interface{}{"name": "letsencrypt", "kind": "ClusterIssuer"}},
+	}
+	if !reflect.DeepEqual(want, patch) {
+		t.Errorf("want patch %v, got %v", want, patch)
+	}
+}
+
+func TestDefaulter_NoPatchWhenDefaulted(t *testing.T) {
+	d := NewDefaulter(controller.Defaults{}, nil)
+
+	fni := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/v1/(.*)")
+	fni.Spec.IngressClassName = "nginx"
+
+	res := reviewUpdate(t, d, admissionv1.Update, fni, fni)
+	if !res.Allowed {
+		t.Fatalf("want the FunctionIngress to be allowed, got: %v", res.Result)
+	}
+	if len(res.Patch) > 0 {
+		t.Errorf("want no patch, got %s", res.Patch)
+	}
+}
+
+func TestDefaulter_DerivesAgainWhenSourceChanges(t *testing.T) {
+	d := NewDefaulter(controller.Defaults{}, nil)
+
+	// The stored object, as defaulted when it was created
+	old := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/(.*)")
+	old.Spec.IngressClassName = "nginx"
+
+	cases := []struct {
+		name   string
+		update func(fni *faasv1.FunctionIngress)
+		want   []map[string]interface{}
+	}{
+		{
+			name:   "ingressType is changed",
+			update: func(fni *faasv1.FunctionIngress) { fni.Spec.IngressType = "traefik" },
+			want: []map[string]interface{}{
+				{"op": "add", "path": "/spec/ingressClassName", "value": "traefik"},
+			},
+		},
+		{
+			name: "ingressType and ingressClassName are changed",
+			update: func(fni *faasv1.FunctionIngress) {
+				fni.Spec.IngressType = "traefik"
+				fni.Spec.IngressClassName = "traefik-internal"
+			},
+		},
+		{
+			name:   "the gateway is bypassed",
+			update: func(fni *faasv1.FunctionIngress) { fni.Spec.BypassGateway = true },
+			want: []map[string]interface{}{
+				{"op": "add", "path": "/spec/functionNamespace", "value": "openfaas"},
+				{"op": "add", "path": "/spec/path", "value": "/"},
+			},
+		},
+		{
+			name:   "the gateway is bypassed when its functions are ready",
+			update: func(fni *faasv1.FunctionIngress) { fni.Spec.BypassMode = faasv1.BypassModeAuto },
+			want: []map[string]interface{}{
+				{"op": "remove", "path": "/spec/path"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fni := old.DeepCopy()
+			tc.update(fni)
+
+			res := reviewUpdate(t, d, admissionv1.Update, old, fni)
+			if !res.Allowed {
+				t.Fatalf("want the FunctionIngress to be allowed, got: %v", res.Result)
+			}
+
+			patch := []map[string]interface{}{}
+			if len(res.Patch) > 0 {
+				if err := json.Unmarshal(res.Patch, &patch); err != nil {
+					t.Fatalf("unable to decode patch: %s", err)
+				}
+			}
+			if len(tc.want) == 0 && len(patch) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(tc.want, patch) {
+				t.Errorf("want patch %v, got %v", tc.want, patch)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	klog "k8s.io/klog"
+)
+
+// certificateCheckPeriod is how often the serving certificate is checked
+// for renewal, and the caBundle of the webhook configurations is repaired.
+// Each replica loads a certificate renewed by another one on its next
+// check, which is well within certificateRenewBefore.
+const certificateCheckPeriod = 12 * time.Hour
+
+// Serve serves the validating, mutating and conversion webhooks over TLS on
+// addr until stopCh is closed. The serving certificate is loaded, or generated, before the
+// listener is opened.
+func Serve(addr string, certificates *Certificates, validator *Validator, defaulter *Defaulter, converter *Converter, stopCh <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	if err := certificates.Ensure(ctx); err != nil {
+		return err
+	}
+
+	go wait.Until(func() {
+		if err := certificates.Ensure(ctx); err != nil {
+			klog.Errorf("Error renewing the webhook certificate: %s", err)
+		}
+	}, certificateCheckPeriod, stopCh)
+
+	mux := http.NewServeMux()
+	mux.Handle(ValidatePath, validator)
+	mux.Handle(DefaultPath, defaulter)
+	mux.Handle(ConvertPath, converter)
+
+	s := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certificates.GetCertificate,
+		},
+	}
+
+	go func() {
+		<-stopCh
+		s.Close()
+	}()
+
+	klog.Infof("Serving the webhooks on %s", addr)
+	if err := s.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	klog "k8s.io/klog"
+)
+
+// ValidatePath is the path that the ValidatingWebhookConfiguration sends
+// admission requests for FunctionIngresses to.
+const ValidatePath = "/validate-openfaas-com-v1-functioningress"
+
+// Validator admits a FunctionIngress when the controller can render it, and
+// its domains and paths are not already used by another FunctionIngress.
+type Validator struct {
+	// functionIngresses list the FunctionIngresses of each watched
+	// namespace, which are checked for domains and paths in use
+	functionIngresses []listers.FunctionIngressLister
+
+	ingressClasses networkinglisters.IngressClassLister
+}
+
+// NewValidator returns a Validator which checks for conflicts against the
+// FunctionIngresses of the listers.
+func NewValidator(ingressClasses networkinglisters.IngressClassLister, functionIngresses ...listers.FunctionIngressLister) *Validator {
+	return &Validator{
+		functionIngresses: functionIngresses,
+		ingressClasses:    ingressClasses,
+	}
+}
+
+// Validate returns the reasons to reject the FunctionIngress
+func (v *Validator) Validate(fni *faasv1.FunctionIngress) (field.ErrorList, error) {
+	ingressClass := controller.ResolveIngressClass(fni, v.ingressClasses)
+	if errs := controller.Validate(fni, ingressClass.Dialect); len(errs) > 0 {
+		return errs, nil
+	}
+
+	others := []*faasv1.FunctionIngress{}
+	for _, lister := range v.functionIngresses {
+		items, err := lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		others = append(others, items...)
+	}
+	return controller.Conflicts(fni, others), nil
+}
+
+// ServeHTTP answers an AdmissionReview for a FunctionIngress
+func (v *Validator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveAdmission(w, r, v.review)
+}
+
+func (v *Validator) review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation == admissionv1.Delete {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	fni := &faasv1.FunctionIngress{}
+	if err := json.Unmarshal(req.Object.Raw, fni); err != nil {
+		return deny(errors.NewBadRequest(fmt.Sprintf("cannot decode FunctionIngress: %s", err)))
+	}
+	// The namespace is not set in the object when it is created with
+	// generateName or without a namespace in the manifest
+	if len(fni.Namespace) == 0 {
+		fni.Namespace = req.Namespace
+	}
+
+	// Adding or removing a finalizer must not be blocked by a spec which
+	// has since become invalid, or the FunctionIngress could never be
+	// deleted, so only a change to the spec is validated
+	if fni.DeletionTimestamp != nil {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	if req.Operation == admissionv1.Update {
+		old := &faasv1.FunctionIngress{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return deny(errors.NewBadRequest(fmt.Sprintf("cannot decode the old FunctionIngress: %s", err)))
+		}
+		if equality.Semantic.DeepEqual(fni.Spec, old.Spec) {
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+	}
+
+	errs, err := v.Validate(fni)
+	if err != nil {
+		return deny(errors.NewInternalError(err))
+	}
+	if len(errs) > 0 {
+		klog.Infof("Rejected FunctionIngress %s/%s: %s", fni.Namespace, req.Name, errs.ToAggregate())
+		return deny(errors.NewInvalid(faasv1.SchemeGroupVersion.WithKind("FunctionIngress").GroupKind(), req.Name, errs))
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
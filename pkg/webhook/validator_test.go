@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	faasv1 "github.com/openfaas/ingress-operator/pkg/apis/openfaas/v1"
+	listers "github.com/openfaas/ingress-operator/pkg/client/listers/openfaas/v1"
+	"github.com/openfaas/ingress-operator/pkg/controller"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestValidator(t *testing.T, existing ...*faasv1.FunctionIngress) *Validator {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, fni := range existing {
+		if err := indexer.Add(fni); err != nil {
+			t.Fatalf("unable to add FunctionIngress: %s", err)
+		}
+	}
+	return NewValidator(nil, listers.NewFunctionIngressLister(indexer))
+}
+
+// review posts an AdmissionReview for the FunctionIngress to the handler
+// and returns the response.
+func review(t *testing.T, h http.Handler, operation admissionv1.Operation, fni *faasv1.FunctionIngress) *admissionv1.AdmissionResponse {
+	t.Helper()
+
+	return reviewUpdate(t, h, operation, nil, fni)
+}
+
+// reviewUpdate is review with the old FunctionIngress of an UPDATE
+func reviewUpdate(t *testing.T, h http.Handler, operation admissionv1.Operation, old, fni *faasv1.FunctionIngress) *admissionv1.AdmissionResponse {
+	t.Helper()
+
+	raw, err := json.Marshal(fni)
+	if err != nil {
+		t.Fatalf("unable to encode FunctionIngress: %s", err)
+	}
+
+	oldObject := runtime.RawExtension{}
+	if old != nil {
+		if oldObject.Raw, err = json.Marshal(old); err != nil {
+			t.Fatalf("unable to encode the old FunctionIngress: %s", err)
+		}
+	}
+
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "b6a2c0b4-5e0e-4b8a-9c52-6f3f3f0c9a61",
+			Name:      fni.Name,
+			Namespace: fni.Namespace,
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+			OldObject: oldObject,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to encode AdmissionReview: %s", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, ValidatePath, bytes.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	res := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unable to decode AdmissionReview: %s", err)
+	}
+	if res.Response == nil {
+		t.Fatalf("want a response")
+	}
+	if res.Response.UID != "b6a2c0b4-5e0e-4b8a-9c52-6f3f3f0c9a61" {
+		t.Errorf("want the UID of the request, got %q", res.Response.UID)
+	}
+	return res.Response
+}
+
+func newTestFunctionIngress(namespace, name, domain, path string) *faasv1.FunctionIngress {
+	return &faasv1.FunctionIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: faasv1.FunctionIngressSpec{
+			Domain:   domain,
+			Function: name,
+			Path:     path,
+		},
+	}
+}
+
+func TestValidator_AllowsValidFunctionIngress(t *testing.T) {
+	v := newTestValidator(t)
+
+	res := review(t, v, admissionv1.Create, newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/v1/(.*)"))
+	if !res.Allowed {
+		t.Errorf("want the FunctionIngress to be allowed, got: %v", res.Result)
+	}
+}
+
+func TestValidator_RejectsInvalidDomain(t *testing.T) {
+	v := newTestValidator(t)
+
+	res := review(t, v, admissionv1.Create, newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo_example.com", ""))
+	if res.Allowed {
+		t.Fatalf("want the FunctionIngress to be rejected")
+	}
+	if res.Result.Code != http.StatusUnprocessableEntity || res.Result.Reason != metav1.StatusReasonInvalid {
+		t.Errorf("want %d %s, got %d %s", http.StatusUnprocessableEntity, metav1.StatusReasonInvalid, res.Result.Code, res.Result.Reason)
+	}
+	if !strings.Contains(res.Result.Message, "spec.domain") {
+		t.Errorf("want the field in the message, got %q", res.Result.Message)
+	}
+}
+
+func TestValidator_RejectsPathForIngressType(t *testing.T) {
+	v := newTestValidator(t)
+
+	fni := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/v(1|2)/(.*)")
+	fni.Spec.IngressType = "traefik-crd"
+
+	if res := review(t, v, admissionv1.Create, fni); res.Allowed {
+		t.Errorf("want a regular expression to be rejected for traefik-crd")
+	}
+}
+
+func TestValidator_RejectsClaimedDomainAndPath(t *testing.T) {
+	v := newTestValidator(t, newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/(.*)"))
+
+	res := review(t, v, admissionv1.Create, newTestFunctionIngress("team-a", "figlet", "nodeinfo.example.com", "/(.*)"))
+	if res.Allowed {
+		t.Fatalf("want the FunctionIngress to be rejected")
+	}
+	if !strings.Contains(res.Result.Message, "openfaas/nodeinfo") {
+		t.Errorf("want the FunctionIngress which claims the path in the message, got %q", res.Result.Message)
+	}
+}
+
+func TestValidator_AllowsUpdateOfSameFunctionIngress(t *testing.T) {
+	existing := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/(.*)")
+	v := newTestValidator(t, existing)
+
+	updated := existing.DeepCopy()
+	updated.Spec.Function = "nodeinfo-v2"
+
+	if res := reviewUpdate(t, v, admissionv1.Update, existing, updated); !res.Allowed {
+		t.Errorf("want the update to be allowed, got: %v", res.Result)
+	}
+}
+
+func TestValidator_RejectsUpdateToClaimedPath(t *testing.T) {
+	existing := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/(.*)")
+	figlet := newTestFunctionIngress("openfaas", "figlet", "nodeinfo.example.com", "/figlet/(.*)")
+	v := newTestValidator(t, existing, figlet)
+
+	updated := figlet.DeepCopy()
+	updated.Spec.Path = "/(.*)"
+
+	if res := reviewUpdate(t, v, admissionv1.Update, figlet, updated); res.Allowed {
+		t.Fatalf("want a change to a claimed path to be rejected")
+	}
+}
+
+func TestValidator_AllowsMetadataUpdateOfInvalidFunctionIngress(t *testing.T) {
+	// figlet claims the same domain and path as nodeinfo, as it was created
+	// before the webhook was installed
+	existing := newTestFunctionIngress("openfaas", "nodeinfo", "nodeinfo.example.com", "/(.*)")
+	figlet := newTestFunctionIngress("openfaas", "figlet", "nodeinfo.example.com", "/(.*)")
+	v := newTestValidator(t, existing, figlet)
+
+	finalized := figlet.DeepCopy()
+	finalized.Finalizers = []string{controller.Finalizer}
+	if res := reviewUpdate(t, v, admissionv1.Update, figlet, finalized); !res.Allowed {
+		t.Errorf("want a finalizer to be added, got: %v", res.Result)
+	}
+
+	deleting := finalized.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	deleting.Finalizers = nil
+	deleting.Spec.Path = "/figlet/(.*"
+	if res := reviewUpdate(t, v, admissionv1.Update, finalized, deleting); !res.Allowed {
+		t.Errorf("want the finalizer of a deleted FunctionIngress to be removed, got: %v", res.Result)
+	}
+}
+
+func TestValidator_AllowsDelete(t *testing.T) {
+	v := newTestValidator(t)
+
+	if res := review(t, v, admissionv1.Delete, &faasv1.FunctionIngress{}); !res.Allowed {
+		t.Errorf("want a delete to be allowed, got: %v", res.Result)
+	}
+}
+
+func TestValidator_RejectsMalformedReview(t *testing.T) {
+	v := newTestValidator(t)
+
+	rr := httptest.NewRecorder()
+	v.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, ValidatePath, strings.NewReader("{}")))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("want status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}